@@ -9,15 +9,21 @@ import (
 	"path/filepath"
 	"strconv"
 	"syscall"
+	_ "time/tzdata" // таймзоны для /schedule есть и в alpine-образе без tzdata
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/maaaruch/tg-vote-bot/internal/app"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
 	"github.com/maaaruch/tg-vote-bot/internal/storage"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
@@ -30,11 +36,28 @@ func main() {
 	voteSalt := getenv("VOTE_SALT", "dev_salt_change_me")
 	debug := getbool("BOT_DEBUG", false)
 
-	if dir := filepath.Dir(dbPath); dir != "." && dir != "" {
-		_ = os.MkdirAll(dir, 0o755)
+	sessionBackend := getenv("SESSION_BACKEND", "sqlite")
+	if sessionBackend != "sqlite" && sessionBackend != "memory" {
+		log.Fatalf("неизвестный SESSION_BACKEND=%q (ожидается sqlite или memory)", sessionBackend)
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	mode := getenv("BOT_MODE", "polling")
+	webhook := app.WebhookConfig{
+		URL:    os.Getenv("WEBHOOK_URL"),
+		Listen: getenv("WEBHOOK_LISTEN", ":8080"),
+		Secret: os.Getenv("WEBHOOK_SECRET"),
+	}
+	switch mode {
+	case "polling":
+	case "webhook":
+		if webhook.URL == "" || webhook.Secret == "" {
+			log.Fatal("для BOT_MODE=webhook нужны WEBHOOK_URL и WEBHOOK_SECRET")
+		}
+	default:
+		log.Fatalf("неизвестный BOT_MODE=%q (ожидается polling или webhook)", mode)
+	}
+
+	db, err := openDB(dbPath)
 	if err != nil {
 		log.Fatalf("ошибка открытия БД: %v", err)
 	}
@@ -44,12 +67,13 @@ func main() {
 		}
 	}()
 
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
-
 	store := storage.New(db)
-	if err := store.InitSchema(); err != nil {
-		log.Fatalf("ошибка инициализации схемы БД: %v", err)
+	applied, err := store.Migrate()
+	if err != nil {
+		log.Fatalf("ошибка миграции БД: %v", err)
+	}
+	for _, m := range applied {
+		log.Printf("Применена миграция %04d_%s", m.Version, m.Name)
 	}
 
 	bot, err := tgbotapi.NewBotAPI(token)
@@ -59,12 +83,47 @@ func main() {
 	bot.Debug = debug
 	log.Printf("Бот запущен как @%s", bot.Self.UserName)
 
-	application := app.New(bot, store, voteSalt)
-	application.Run(ctx)
+	var sessions session.Store = store
+	if sessionBackend == "memory" {
+		sessions = session.NewMemoryStore()
+	}
+
+	application := app.New(bot, store, sessions, voteSalt)
+
+	if mode == "webhook" {
+		if err := application.RegisterWebhook(webhook); err != nil {
+			log.Fatalf("ошибка регистрации вебхука: %v", err)
+		}
+		log.Printf("Вебхук зарегистрирован: %s", webhook.URL)
+		if err := application.RunWebhook(ctx, webhook); err != nil {
+			log.Printf("webhook server: %v", err)
+		}
+	} else {
+		// если раньше стоял вебхук, getUpdates будет отвечать 409 — снимаем его
+		if err := application.RemoveWebhook(); err != nil {
+			log.Fatalf("ошибка удаления вебхука: %v", err)
+		}
+		application.Run(ctx)
+	}
 
 	log.Println("Выключаемся…")
 }
 
+func openDB(dbPath string) (*sql.DB, error) {
+	if dir := filepath.Dir(dbPath); dir != "." && dir != "" {
+		_ = os.MkdirAll(dir, 0o755)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	return db, nil
+}
+
 func getenv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
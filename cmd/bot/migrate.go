@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+const migrateUsage = `Использование:
+  bot migrate status – показать применённые и ожидающие миграции
+  bot migrate up     – применить все ожидающие миграции`
+
+// runMigrate обрабатывает подкоманду "migrate". Токен бота для неё не нужен.
+func runMigrate(args []string) int {
+	if len(args) != 1 || (args[0] != "status" && args[0] != "up") {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db, err := openDB(getenv("DB_PATH", "data/data.db"))
+	if err != nil {
+		log.Printf("ошибка открытия БД: %v", err)
+		return 1
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("db close: %v", err)
+		}
+	}()
+
+	store := storage.New(db)
+
+	if args[0] == "up" {
+		applied, err := store.Migrate()
+		if err != nil {
+			log.Printf("ошибка миграции БД: %v", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("Новых миграций нет.")
+		}
+		for _, m := range applied {
+			fmt.Printf("применена  %04d_%s\n", m.Version, m.Name)
+		}
+		return 0
+	}
+
+	statuses, err := store.MigrationStatus()
+	if err != nil {
+		log.Printf("ошибка чтения статуса миграций: %v", err)
+		return 1
+	}
+	for _, m := range statuses {
+		if m.AppliedAt.IsZero() {
+			fmt.Printf("ожидает    %04d_%s\n", m.Version, m.Name)
+		} else {
+			fmt.Printf("применена  %04d_%s  (%s)\n", m.Version, m.Name, m.AppliedAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+	return 0
+}
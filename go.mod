@@ -5,4 +5,5 @@ go 1.22.2
 require (
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
 	github.com/mattn/go-sqlite3 v1.14.32
+	golang.org/x/crypto v0.31.0
 )
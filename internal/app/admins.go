@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// adminInviteTTL — сколько живёт ссылка из /add_admin (она одноразовая).
+const adminInviteTTL = 48 * time.Hour
+
+func displayName(u *tgbotapi.User) string {
+	if u == nil {
+		return ""
+	}
+	if u.UserName != "" {
+		return "@" + u.UserName
+	}
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+// ---------- Команды ----------
+
+func (a *App) handleAddAdmin(msg *tgbotapi.Message) {
+	args := strings.Fields(strings.TrimSpace(msg.CommandArguments()))
+	if len(args) == 0 {
+		text := "Формат: /add_admin roomID [admin|observer]\n\n" +
+			"admin — может управлять номинациями, номинантами и приглашениями\n" +
+			"observer — может только смотреть результаты\n\n" +
+			"Бот пришлёт одноразовую ссылку: отправь её человеку, которого хочешь добавить."
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	roomID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "roomID должно быть числом."))
+		return
+	}
+
+	role := domain.RoleAdmin
+	if len(args) >= 2 {
+		switch domain.Role(strings.ToLower(args[1])) {
+		case domain.RoleAdmin:
+		case domain.RoleObserver:
+			role = domain.RoleObserver
+		default:
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Роль может быть admin или observer."))
+			return
+		}
+	}
+
+	current, err := a.store.RoomRole(roomID, msg.From.ID)
+	if err != nil {
+		log.Println("RoomRole(add_admin):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if current != domain.RoleOwner {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Добавлять админов и наблюдателей может только автор комнаты."))
+		return
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		log.Println("newInviteToken(add_admin):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось создать приглашение."))
+		return
+	}
+
+	now := time.Now()
+	inv := domain.Invite{
+		Token:     token,
+		RoomID:    roomID,
+		CreatedBy: msg.From.ID,
+		MaxUses:   1,
+		ExpiresAt: now.Add(adminInviteTTL),
+		CreatedAt: now,
+		Role:      role,
+	}
+	if err := a.store.CreateInvite(inv); err != nil {
+		log.Println("CreateInvite(add_admin):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось создать приглашение."))
+		return
+	}
+
+	text := fmt.Sprintf("Одноразовая ссылка для роли «%s» в комнате ID %d:\n%s\n\n(%s)\n\n"+
+		"Отправь её человеку лично — кто первым откроет, тот и получит роль.",
+		role.Title(), roomID, a.inviteLink(token), describeInvite(inv, now))
+	m := tgbotapi.NewMessage(msg.Chat.ID, text)
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚫 Отозвать", "inv_revoke:"+token),
+		),
+	)
+	a.send(m)
+}
+
+func (a *App) handleAdminsCommand(msg *tgbotapi.Message) {
+	roomID, err := strconv.ParseInt(strings.TrimSpace(msg.CommandArguments()), 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Формат: /admins roomID"))
+		return
+	}
+	a.sendAdminsList(msg.Chat.ID, msg.From.ID, roomID)
+}
+
+// ---------- Кнопки ----------
+
+func (a *App) handleAdminsCallback(cq *tgbotapi.CallbackQuery, data string) {
+	chatID := cq.Message.Chat.ID
+
+	switch {
+	case strings.HasPrefix(data, "adm_list:"):
+		roomID, err := strconv.ParseInt(strings.TrimPrefix(data, "adm_list:"), 10, 64)
+		if err != nil {
+			return
+		}
+		a.sendAdminsList(chatID, cq.From.ID, roomID)
+
+	// adm_rm:<roomID>:<userID>
+	case strings.HasPrefix(data, "adm_rm:"):
+		roomStr, userStr, ok := strings.Cut(strings.TrimPrefix(data, "adm_rm:"), ":")
+		if !ok {
+			return
+		}
+		roomID, err := strconv.ParseInt(roomStr, 10, 64)
+		if err != nil {
+			return
+		}
+		userID, err := strconv.ParseInt(userStr, 10, 64)
+		if err != nil {
+			return
+		}
+
+		role, err := a.store.RoomRole(roomID, cq.From.ID)
+		if err != nil {
+			log.Println("RoomRole(adm_rm):", err)
+			a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+			return
+		}
+		if role != domain.RoleOwner {
+			a.send(tgbotapi.NewMessage(chatID, "Снимать роли может только автор комнаты."))
+			return
+		}
+
+		removed, err := a.store.RemoveRoomAdmin(roomID, userID)
+		if err != nil {
+			log.Println("RemoveRoomAdmin:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Не удалось снять роль."))
+			return
+		}
+		if !removed {
+			a.send(tgbotapi.NewMessage(chatID, "У этого пользователя уже нет роли в комнате."))
+			return
+		}
+		a.send(tgbotapi.NewMessage(chatID, "Роль снята ✅"))
+	}
+}
+
+// ---------- Утилиты ----------
+
+func (a *App) sendAdminsList(chatID, userID, roomID int64) {
+	role, err := a.store.RoomRole(roomID, userID)
+	if err != nil {
+		log.Println("RoomRole(admins):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanViewResults() {
+		a.send(tgbotapi.NewMessage(chatID, "Команду комнаты видят только её организаторы и наблюдатели."))
+		return
+	}
+
+	admins, err := a.store.ListRoomAdmins(roomID)
+	if err != nil {
+		log.Println("ListRoomAdmins:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить список."))
+		return
+	}
+
+	var sb strings.Builder
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	fmt.Fprintf(&sb, "Команда комнаты ID %d:\n", roomID)
+	for _, adm := range admins {
+		name := adm.DisplayName
+		if name == "" {
+			name = fmt.Sprintf("user %d", adm.UserID)
+		}
+		fmt.Fprintf(&sb, "• %s — %s\n", name, adm.Role.Title())
+
+		if role == domain.RoleOwner && adm.Role != domain.RoleOwner {
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🗑 Снять роль: "+name, fmt.Sprintf("adm_rm:%d:%d", roomID, adm.UserID)),
+			))
+		}
+	}
+	if role == domain.RoleOwner {
+		fmt.Fprintf(&sb, "\nДобавить: /add_admin %d admin или /add_admin %d observer", roomID, roomID)
+	}
+
+	m := tgbotapi.NewMessage(chatID, sb.String())
+	if len(buttons) > 0 {
+		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons...)
+	}
+	a.send(m)
+}
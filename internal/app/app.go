@@ -13,6 +13,7 @@ import (
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
 	"github.com/maaaruch/tg-vote-bot/internal/session"
 	"github.com/maaaruch/tg-vote-bot/internal/storage"
 )
@@ -24,16 +25,55 @@ type App struct {
 	voteSalt string
 }
 
-func New(bot *tgbotapi.BotAPI, store *storage.Store, voteSalt string) *App {
+// startCaption — подпись к картинке /start.
+const startCaption = "Привет! Это бот для голосования по номинациям в комнатах. Команды — в следующем сообщении."
+
+// commandsText — список команд, отдельным сообщением после картинки /start.
+const commandsText = "Основные команды:\n" +
+	"/create_room Название | Пароль – создать свою комнату\n" +
+	"/my_rooms – список твоих комнат\n" +
+	"/room ID Пароль – войти в комнату как участник\n" +
+	"/invite roomID [часы] [макс_входов] – ссылка-приглашение в комнату\n" +
+	"/invites roomID – активные приглашения (можно отозвать)\n" +
+	"/jury_invite roomID [часы] [макс_входов] – ссылка для жюри\n" +
+	"/jury_weight roomID проценты – вес жюри в общем зачёте\n" +
+	"/auto_runoff roomID on|off – переголосование при ничьей после закрытия\n" +
+	"/withdraw_after_close roomID on|off – можно ли отзывать голоса после закрытия\n" +
+	"/allow_revote roomID on|off – можно ли менять отданный голос\n" +
+	"/add_admin roomID [admin|observer] – пригласить соорганизатора или наблюдателя\n" +
+	"/admins roomID – команда комнаты\n" +
+	"/open_voting roomID, /close_voting roomID – открыть/закрыть голосование\n" +
+	"/schedule roomID | открытие | закрытие | таймзона – голосование по расписанию\n" +
+	"/nominations – показать номинации в активной комнате (с ID)\n" +
+	"/my_votes – за кого ты проголосовал(а) в активной комнате\n" +
+	"/add_nomination roomID | Название | Описание – добавить номинацию (автор и админы комнаты)\n" +
+	"/add_nominee nominationID | Имя – добавить номинанта\n" +
+	"/set_nominee_media nomineeID – привязать/сменить фото/видео номинанта\n" +
+	"/set_max_choices nominationID K – разрешить отметить до K номинантов\n" +
+	"/nomination_mode nominationID режим – выбор, ранжирование, оценки 1–5, сетка, пары или референдум\n" +
+	"/set_tally nominationID irv|borda – как считать ранжирование\n" +
+	"/referendum nominationID majority|2/3 [кворум%] – референдум «за/против» с порогом\n" +
+	"/delete_nomination nominationID – удалить номинацию\n" +
+	"/delete_nominee nomineeID – удалить номинанта\n" +
+	"/results nominationID – результаты одной номинации (участникам — после публикации)\n" +
+	"/publish roomID [winners|full] [nominationID ...] – опубликовать итоги для участников\n" +
+	"/promote nominationID N [| Название] – топ-N в следующий тур\n" +
+	"/bracket nominationID [минут] – построить турнирную сетку\n" +
+	"/next_match nominationID – завершить матч сетки и открыть следующий"
+
+// New собирает приложение. sessions — бэкенд сессий (SQLite в проде, session.MemoryStore в тестах).
+func New(bot *tgbotapi.BotAPI, store *storage.Store, sessions session.Store, voteSalt string) *App {
 	return &App{
 		bot:      bot,
 		store:    store,
-		sessions: session.NewManager(),
+		sessions: session.NewManager(sessions),
 		voteSalt: voteSalt,
 	}
 }
 
 func (a *App) Run(ctx context.Context) {
+	go a.runScheduler(ctx)
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
@@ -49,19 +89,33 @@ func (a *App) Run(ctx context.Context) {
 			if !ok {
 				return
 			}
-			if update.Message != nil {
-				a.handleMessage(update.Message)
-			} else if update.CallbackQuery != nil {
-				a.handleCallback(update.CallbackQuery)
-			}
+			a.handleUpdate(update)
 		}
 	}
 }
 
-func (a *App) getSession(userID int64) *session.Session {
+func (a *App) handleUpdate(update tgbotapi.Update) {
+	if update.Message != nil {
+		a.handleMessage(update.Message)
+	} else if update.CallbackQuery != nil {
+		a.handleCallback(update.CallbackQuery)
+	}
+}
+
+func (a *App) getSession(userID int64) (*session.Session, error) {
 	return a.sessions.Get(userID)
 }
 
+// saveSession сохраняет сессию, если за время обработки апдейта она поменялась.
+func (a *App) saveSession(userID int64, sess *session.Session, before session.Session) {
+	if *sess == before {
+		return
+	}
+	if err := a.sessions.Save(userID, sess); err != nil {
+		log.Println("save session:", err)
+	}
+}
+
 func (a *App) hashUserID(userID int64) string {
 	data := fmt.Sprintf("%s:%d", a.voteSalt, userID)
 	sum := sha256.Sum256([]byte(data))
@@ -81,7 +135,13 @@ func (a *App) handleMessage(msg *tgbotapi.Message) {
 		return
 	}
 	userID := msg.From.ID
-	sess := a.getSession(userID)
+	sess, err := a.getSession(userID)
+	if err != nil {
+		log.Println("get session:", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Что-то пошло не так, попробуй ещё раз."))
+		return
+	}
+	defer a.saveSession(userID, sess, *sess)
 
 	// 1) ждём медиа для номинанта
 	if sess.WaitingMediaForNomineeID != 0 && (len(msg.Photo) > 0 || msg.Video != nil) {
@@ -99,21 +159,17 @@ func (a *App) handleMessage(msg *tgbotapi.Message) {
 	if msg.IsCommand() {
 		switch msg.Command() {
 		case "start":
-			text := "Привет! Это бот для голосования по номинациям в комнатах.\n\n" +
-				"Основные команды:\n" +
-				"/create_room Название | Пароль – создать свою комнату\n" +
-				"/my_rooms – список твоих комнат\n" +
-				"/room ID Пароль – войти в комнату как участник\n" +
-				"/nominations – показать номинации в активной комнате (с ID)\n" +
-				"/add_nomination roomID | Название | Описание – добавить номинацию (только автор комнаты)\n" +
-				"/add_nominee nominationID | Имя – добавить номинанта\n" +
-				"/set_nominee_media nomineeID – привязать/сменить фото/видео номинанта\n" +
-				"/delete_nomination nominationID – удалить номинацию\n" +
-				"/delete_nominee nomineeID – удалить номинанта\n" +
-				"/results nominationID – результаты одной номинации (только автор комнаты)"
+			// t.me/<bot>?start=<token> — вход по приглашению
+			if token := strings.TrimSpace(msg.CommandArguments()); token != "" {
+				a.handleStartInvite(msg, sess, token)
+				return
+			}
+
+			// подпись к фото Telegram ограничивает 1024 символами — список команд идёт отдельным сообщением
 			photo := tgbotapi.NewPhoto(msg.Chat.ID, tgbotapi.FilePath("assets/start.jpg"))
-			photo.Caption = text
+			photo.Caption = startCaption
 			a.send(photo)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, commandsText))
 
 		case "help":
 			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Смотри /start – там всё расписано 🙂"))
@@ -125,11 +181,44 @@ func (a *App) handleMessage(msg *tgbotapi.Message) {
 			a.handleMyRooms(msg)
 
 		case "room":
-			a.handleJoinRoom(msg)
+			a.handleJoinRoom(msg, sess)
+
+		case "invite":
+			a.handleInviteCommand(msg, domain.GroupAudience)
+
+		case "jury_invite":
+			a.handleInviteCommand(msg, domain.GroupJury)
+
+		case "jury_weight":
+			a.handleJuryWeight(msg)
+
+		case "auto_runoff":
+			a.handleAutoRunoff(msg)
+
+		case "invites":
+			a.handleInvitesCommand(msg)
+
+		case "add_admin":
+			a.handleAddAdmin(msg)
+
+		case "open_voting":
+			a.handleRoomStatusCommand(msg, domain.RoomOpen)
+
+		case "close_voting":
+			a.handleRoomStatusCommand(msg, domain.RoomClosed)
+
+		case "schedule":
+			a.handleSchedule(msg)
+
+		case "admins":
+			a.handleAdminsCommand(msg)
 
 		case "nominations":
 			a.handleNominationsCommand(msg, sess)
 
+		case "my_votes":
+			a.handleMyVotes(msg, sess)
+
 		case "add_nomination":
 			a.handleAddNomination(msg)
 
@@ -139,6 +228,15 @@ func (a *App) handleMessage(msg *tgbotapi.Message) {
 		case "set_nominee_media":
 			a.handleSetNomineeMedia(msg, sess)
 
+		case "set_max_choices":
+			a.handleSetMaxChoices(msg)
+
+		case "nomination_mode":
+			a.handleNominationMode(msg)
+
+		case "set_tally":
+			a.handleSetTally(msg)
+
 		case "delete_nomination":
 			a.handleDeleteNomination(msg)
 
@@ -146,7 +244,28 @@ func (a *App) handleMessage(msg *tgbotapi.Message) {
 			a.handleDeleteNominee(msg)
 
 		case "results":
-			a.handleResults(msg)
+			a.handleResults(msg, sess)
+
+		case "publish":
+			a.handlePublish(msg)
+
+		case "promote":
+			a.handlePromote(msg)
+
+		case "bracket":
+			a.handleBracket(msg)
+
+		case "next_match":
+			a.handleNextMatch(msg)
+
+		case "referendum":
+			a.handleReferendum(msg)
+
+		case "withdraw_after_close":
+			a.handleWithdrawAfterClose(msg)
+
+		case "allow_revote":
+			a.handleAllowRevote(msg)
 
 		default:
 			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не знаю такой команды. Попробуй /start"))
@@ -166,11 +285,18 @@ func (a *App) handleCallback(cq *tgbotapi.CallbackQuery) {
 		return
 	}
 	userID := cq.From.ID
-	sess := a.getSession(userID)
 
 	// убрать "часики" у кнопки
 	_, _ = a.bot.Request(tgbotapi.NewCallback(cq.ID, ""))
 
+	sess, err := a.getSession(userID)
+	if err != nil {
+		log.Println("get session:", err)
+		a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Что-то пошло не так, попробуй ещё раз."))
+		return
+	}
+	defer a.saveSession(userID, sess, *sess)
+
 	// универсальная кнопка "назад" — возвращаемся к списку номинаций
 	if data == "back:nominations" {
 		// сбрасываем возможные "ожидания" (имя/медиа), чтобы пользователь не застревал в режиме ввода
@@ -188,6 +314,43 @@ func (a *App) handleCallback(cq *tgbotapi.CallbackQuery) {
 	}
 
 	switch {
+	// приглашения: создать / список / отозвать
+	case strings.HasPrefix(data, "inv_"):
+		a.handleInviteCallback(cq, data)
+
+	// команда комнаты: список / снять роль
+	case strings.HasPrefix(data, "adm_"):
+		a.handleAdminsCallback(cq, data)
+
+	case strings.HasPrefix(data, "score:"):
+		a.handleScoreCallback(cq, sess, data)
+
+	case strings.HasPrefix(data, "match_"):
+		a.handleMatchCallback(cq, sess, data)
+
+	case strings.HasPrefix(data, "pair:"):
+		a.handlePairCallback(cq, sess, data)
+
+	case strings.HasPrefix(data, "ref:"):
+		a.handleReferendumCallback(cq, sess, data)
+
+	case strings.HasPrefix(data, "unvote:"):
+		a.handleUnvoteCallback(cq, sess, data)
+
+	// мастер «Голосовать во всём»
+	case strings.HasPrefix(data, "wiz:"):
+		a.handleWizardCallback(cq, sess, data)
+
+	case strings.HasPrefix(data, "rank_"):
+		a.handleRankedCallback(cq, sess, data)
+
+	case strings.HasPrefix(data, "pub_"):
+		a.handlePublishCallback(cq, data)
+
+	// смена статуса комнаты (открыть/закрыть голосование)
+	case strings.HasPrefix(data, "room_status:"):
+		a.handleRoomStatusCallback(cq, data)
+
 	// открыть номинацию, показать номинантов
 	case strings.HasPrefix(data, "nomination:"):
 		idStr := strings.TrimPrefix(data, "nomination:")
@@ -238,8 +401,44 @@ func (a *App) handleCallback(cq *tgbotapi.CallbackQuery) {
 			return
 		}
 
+		nom, err := a.store.GetNomination(nominationID)
+		if err != nil {
+			log.Println("get nomination(vote):", err)
+			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Что-то пошло не так, попробуй ещё раз."))
+			return
+		}
+		switch nom.Kind {
+		case domain.KindRanked:
+			a.sendRankedBallot(cq.Message.Chat.ID, userID, nom, nil)
+			return
+		case domain.KindScore:
+			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "В этой номинации ставят оценки — открой её заново."))
+			return
+		case domain.KindBracket:
+			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "В этой номинации голосуют в матчах сетки — открой её заново."))
+			return
+		case domain.KindPairwise:
+			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "В этой номинации сравнивают пары — открой её заново."))
+			return
+		case domain.KindReferendum:
+			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Это референдум — открой номинацию заново и ответь «за» или «против»."))
+			return
+		}
+		if nom.MaxChoices > 1 {
+			a.handleToggleVote(cq.Message.Chat.ID, userID, nom, nomineeID)
+			return
+		}
+
 		userHash := a.hashUserID(userID)
 		if err := a.store.RecordVote(userHash, nominationID, nomineeID, time.Now()); err != nil {
+			if reason := a.voteRejectedReason(roomID, err); reason != "" {
+				a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Голос не принят: "+reason+"."))
+				return
+			}
+			if errors.Is(err, storage.ErrVoteLocked) {
+				a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, a.lockedVoteText(userHash, nominationID)))
+				return
+			}
 			log.Println("record vote:", err)
 			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Что-то пошло не так, попробуй ещё раз."))
 			return
@@ -262,7 +461,7 @@ func (a *App) handleCallback(cq *tgbotapi.CallbackQuery) {
 			}
 		}
 
-	// результаты по номинации (кнопка 📊 Результаты)
+	// результаты по номинации (кнопки 📊 Результаты / 🏆 Итоги)
 	case strings.HasPrefix(data, "res_nom:"):
 		idStr := strings.TrimPrefix(data, "res_nom:")
 		nominationID, err := strconv.ParseInt(idStr, 10, 64)
@@ -281,65 +480,7 @@ func (a *App) handleCallback(cq *tgbotapi.CallbackQuery) {
 			return
 		}
 
-		isOwner, err := a.store.IsRoomOwner(roomID, cq.From.ID)
-		if err != nil {
-			log.Println("IsRoomOwner(res_nom):", err)
-			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Ошибка проверки прав."))
-			return
-		}
-		if !isOwner {
-			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Результаты может смотреть только автор комнаты."))
-			return
-		}
-
-		roomTitle, err := a.store.GetRoomTitle(roomID)
-		if err != nil && !errors.Is(err, storage.ErrNotFound) {
-			log.Println("res_nom get room title:", err)
-		}
-		if roomTitle == "" {
-			roomTitle = fmt.Sprintf("ID %d", roomID)
-		}
-
-		nominationName, err := a.store.GetNominationName(nominationID)
-		if err != nil && !errors.Is(err, storage.ErrNotFound) {
-			log.Println("res_nom get nomination name:", err)
-		}
-		if nominationName == "" {
-			nominationName = fmt.Sprintf("ID %d", nominationID)
-		}
-
-		results, err := a.store.ResultsByNomination(nominationID)
-		if err != nil {
-			log.Println("res_nom results:", err)
-			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Не удалось получить результаты."))
-			return
-		}
-
-		var sb strings.Builder
-		fmt.Fprintf(&sb,
-			"Результаты голосования\nКомната: %s (ID %d)\nНоминация: %s (ID %d)\n\n",
-			roomTitle, roomID, nominationName, nominationID,
-		)
-
-		if len(results) == 0 {
-			sb.WriteString("В этой номинации пока нет номинантов.\n")
-		} else {
-			for _, r := range results {
-				fmt.Fprintf(&sb, "• %s (ID %d) — %d голос(ов)\n", r.Name, r.ID, r.Votes)
-			}
-		}
-
-		text := sb.String()
-		if len(text) > 4000 {
-			text = text[:4000] + "\n\n(обрезано, слишком много текста)"
-		}
-		m := tgbotapi.NewMessage(cq.Message.Chat.ID, text)
-		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
-			),
-		)
-		a.send(m)
+		a.showResults(cq.Message.Chat.ID, userID, sess, roomID, nominationID)
 
 	// кнопка "➕ Добавить номинанта"
 	case strings.HasPrefix(data, "addnom:"):
@@ -349,14 +490,14 @@ func (a *App) handleCallback(cq *tgbotapi.CallbackQuery) {
 			return
 		}
 
-		ok, err := a.store.IsNominationOwner(nominationID, cq.From.ID)
+		role, err := a.store.NominationRole(nominationID, cq.From.ID)
 		if err != nil {
-			log.Println("IsNominationOwner(addnom):", err)
+			log.Println("NominationRole(addnom):", err)
 			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Ошибка проверки прав."))
 			return
 		}
-		if !ok {
-			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Только автор комнаты может добавлять номинантов."))
+		if !role.CanManage() {
+			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Только автор или админы комнаты могут добавлять номинантов."))
 			return
 		}
 
@@ -379,14 +520,14 @@ func (a *App) handleCallback(cq *tgbotapi.CallbackQuery) {
 			return
 		}
 
-		ok, err := a.store.IsNomineeOwner(nomineeID, cq.From.ID)
+		role, err := a.store.NomineeRole(nomineeID, cq.From.ID)
 		if err != nil {
-			log.Println("IsNomineeOwner(setmedia):", err)
+			log.Println("NomineeRole(setmedia):", err)
 			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Ошибка проверки прав."))
 			return
 		}
-		if !ok {
-			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Только автор комнаты может менять медиа у номинантов."))
+		if !role.CanManage() {
+			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Только автор или админы комнаты могут менять медиа у номинантов."))
 			return
 		}
 
@@ -409,14 +550,14 @@ func (a *App) handleCallback(cq *tgbotapi.CallbackQuery) {
 			return
 		}
 
-		ok, err := a.store.IsNomineeOwner(nomineeID, cq.From.ID)
+		role, err := a.store.NomineeRole(nomineeID, cq.From.ID)
 		if err != nil {
-			log.Println("IsNomineeOwner(delnom):", err)
+			log.Println("NomineeRole(delnom):", err)
 			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Ошибка проверки прав."))
 			return
 		}
-		if !ok {
-			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Только автор комнаты может удалять номинантов."))
+		if !role.CanManage() {
+			a.send(tgbotapi.NewMessage(cq.Message.Chat.ID, "Только автор или админы комнаты могут удалять номинантов."))
 			return
 		}
 
@@ -462,6 +603,10 @@ func (a *App) handleCreateRoom(msg *tgbotapi.Message) {
 	password := parts[1]
 
 	roomID, err := a.store.CreateRoom(msg.From.ID, title, password)
+	if errors.Is(err, storage.ErrPasswordTooLong) {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Пароль слишком длинный — максимум 72 байта."))
+		return
+	}
 	if err != nil {
 		log.Println("create_room:", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось создать комнату 😔"))
@@ -471,13 +616,14 @@ func (a *App) handleCreateRoom(msg *tgbotapi.Message) {
 	text := fmt.Sprintf(
 		"Комната создана! 🎉\nID: %d\nНазвание: %s\nПароль: %s\n\n"+
 			"Поделись ID и паролем с участниками.\n"+
-			"Чтобы зайти как участник: /room %d %s",
-		roomID, title, password, roomID, password)
+			"Чтобы зайти как участник: /room %d %s\n\n"+
+			"Комната создана как черновик: добавь номинации и открой голосование командой /open_voting %d",
+		roomID, title, password, roomID, password, roomID)
 	a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
 }
 
 func (a *App) handleMyRooms(msg *tgbotapi.Message) {
-	rooms, err := a.store.ListRoomsByOwner(msg.From.ID)
+	rooms, err := a.store.ListRoomsByAdmin(msg.From.ID)
 	if err != nil {
 		log.Println("my_rooms:", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не получилось получить список комнат."))
@@ -490,16 +636,34 @@ func (a *App) handleMyRooms(msg *tgbotapi.Message) {
 	}
 
 	var sb strings.Builder
+	var buttons [][]tgbotapi.InlineKeyboardButton
 	sb.WriteString("Твои комнаты:\n")
-	for _, r := range rooms {
-		fmt.Fprintf(&sb, "• ID: %d — %s\n", r.ID, r.Title)
+	for _, ar := range rooms {
+		r := ar.Room
+		fmt.Fprintf(&sb, "• ID: %d — %s (%s)\n", r.ID, r.Title, ar.Role.Title())
+		if !ar.Role.CanManage() {
+			continue
+		}
+		row := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔗 Пригласить в ID %d", r.ID), fmt.Sprintf("inv_new:%d", r.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("📋 Приглашения", fmt.Sprintf("inv_list:%d", r.ID)),
+		)
+		if ar.Role == domain.RoleOwner {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("👥 Команда", fmt.Sprintf("adm_list:%d", r.ID)))
+		}
+		buttons = append(buttons, row)
 	}
-	sb.WriteString("\nЧтобы зайти в комнату как участник:\n/room ID Пароль")
+	sb.WriteString("\nЧтобы зайти в комнату как участник:\n/room ID Пароль\n")
+	sb.WriteString("Или создай ссылку-приглашение кнопкой ниже — по ней входят без пароля.")
 
-	a.send(tgbotapi.NewMessage(msg.Chat.ID, sb.String()))
+	m := tgbotapi.NewMessage(msg.Chat.ID, sb.String())
+	if len(buttons) > 0 {
+		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons...)
+	}
+	a.send(m)
 }
 
-func (a *App) handleJoinRoom(msg *tgbotapi.Message) {
+func (a *App) handleJoinRoom(msg *tgbotapi.Message, sess *session.Session) {
 	args := strings.TrimSpace(msg.CommandArguments())
 	if args == "" {
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Формат: /room ID Пароль\nПример: /room 1 secret123"))
@@ -520,22 +684,34 @@ func (a *App) handleJoinRoom(msg *tgbotapi.Message) {
 
 	password := fields[1]
 
-	room, err := a.store.GetRoomByIDAndPassword(roomID, password)
+	now := time.Now()
+	room, until, err := a.joinByPassword(msg.From.ID, roomID, password, now)
 	if err != nil {
-		if errors.Is(err, storage.ErrNotFound) {
+		switch {
+		case errors.Is(err, errJoinLocked):
+			text := fmt.Sprintf("Слишком много неудачных попыток входа. Попробуй снова через %s.", formatWait(until.Sub(now)))
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		case errors.Is(err, storage.ErrNotFound):
 			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Комната не найдена или неверный пароль."))
-		} else {
+		default:
 			log.Println("join room:", err)
 			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка при входе в комнату."))
 		}
 		return
 	}
 
-	sess := a.getSession(msg.From.ID)
+	a.enterRoom(msg.Chat.ID, msg.From.ID, sess, room)
+}
+
+func (a *App) enterRoom(chatID, userID int64, sess *session.Session, room *domain.Room) {
 	sess.ActiveRoomID = room.ID
+	if err := a.store.AddRoomMember(room.ID, userID, time.Now()); err != nil {
+		log.Println("AddRoomMember:", err)
+	}
 
-	text := fmt.Sprintf("Ты вошёл в комнату: %s (ID %d)\nТеперь можешь смотреть номинации командой /nominations", room.Title, room.ID)
-	a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+	text := fmt.Sprintf("Ты вошёл в комнату: %s (ID %d)\nСтатус: %s\nТеперь можешь смотреть номинации командой /nominations",
+		room.Title, room.ID, room.Status.Title())
+	a.send(tgbotapi.NewMessage(chatID, text))
 }
 
 func (a *App) handleNominationsCommand(msg *tgbotapi.Message, sess *session.Session) {
@@ -575,14 +751,14 @@ func (a *App) handleAddNomination(msg *tgbotapi.Message) {
 		description = parts[2]
 	}
 
-	ok, err := a.store.IsRoomOwner(roomID, msg.From.ID)
+	role, err := a.store.RoomRole(roomID, msg.From.ID)
 	if err != nil {
-		log.Println("isRoomOwner:", err)
+		log.Println("RoomRole:", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
 		return
 	}
-	if !ok {
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор комнаты может добавлять номинации."))
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор или админы комнаты могут добавлять номинации."))
 		return
 	}
 
@@ -617,14 +793,14 @@ func (a *App) handleAddNominee(msg *tgbotapi.Message) {
 
 	name := parts[1]
 
-	ok, err := a.store.IsNominationOwner(nominationID, msg.From.ID)
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
 	if err != nil {
-		log.Println("isNominationOwner:", err)
+		log.Println("NominationRole:", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
 		return
 	}
-	if !ok {
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор комнаты может добавлять номинантов."))
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор или админы комнаты могут добавлять номинантов."))
 		return
 	}
 
@@ -654,14 +830,14 @@ func (a *App) handleSetNomineeMedia(msg *tgbotapi.Message, sess *session.Session
 		return
 	}
 
-	ok, err := a.store.IsNomineeOwner(nomineeID, msg.From.ID)
+	role, err := a.store.NomineeRole(nomineeID, msg.From.ID)
 	if err != nil {
-		log.Println("isNomineeOwner:", err)
+		log.Println("NomineeRole:", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
 		return
 	}
-	if !ok {
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор комнаты может менять медиа у номинантов."))
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор или админы комнаты могут менять медиа у номинантов."))
 		return
 	}
 
@@ -686,14 +862,14 @@ func (a *App) handleDeleteNomination(msg *tgbotapi.Message) {
 		return
 	}
 
-	ok, err := a.store.IsNominationOwner(nominationID, msg.From.ID)
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
 	if err != nil {
-		log.Println("isNominationOwner(delete_nomination):", err)
+		log.Println("NominationRole(delete_nomination):", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
 		return
 	}
-	if !ok {
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор комнаты может удалять номинации."))
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор или админы комнаты могут удалять номинации."))
 		return
 	}
 
@@ -726,14 +902,14 @@ func (a *App) handleDeleteNominee(msg *tgbotapi.Message) {
 		return
 	}
 
-	ok, err := a.store.IsNomineeOwner(nomineeID, msg.From.ID)
+	role, err := a.store.NomineeRole(nomineeID, msg.From.ID)
 	if err != nil {
-		log.Println("isNomineeOwner(delete_nominee):", err)
+		log.Println("NomineeRole(delete_nominee):", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
 		return
 	}
-	if !ok {
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор комнаты может удалять номинантов."))
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор или админы комнаты могут удалять номинантов."))
 		return
 	}
 
@@ -751,7 +927,7 @@ func (a *App) handleDeleteNominee(msg *tgbotapi.Message) {
 	a.send(tgbotapi.NewMessage(msg.Chat.ID, "Номинант удалён вместе с его голосами ✅"))
 }
 
-func (a *App) handleResults(msg *tgbotapi.Message) {
+func (a *App) handleResults(msg *tgbotapi.Message, sess *session.Session) {
 	args := strings.Fields(strings.TrimSpace(msg.CommandArguments()))
 	if len(args) == 0 {
 		text := "Форматы:\n" +
@@ -804,59 +980,7 @@ func (a *App) handleResults(msg *tgbotapi.Message) {
 		}
 	}
 
-	ok, err := a.store.IsRoomOwner(roomID, msg.From.ID)
-	if err != nil {
-		log.Println("isRoomOwner(results):", err)
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
-		return
-	}
-	if !ok {
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор комнаты может смотреть результаты."))
-		return
-	}
-
-	roomTitle, err := a.store.GetRoomTitle(roomID)
-	if err != nil {
-		if !errors.Is(err, storage.ErrNotFound) {
-			log.Println("results get room title:", err)
-		}
-		roomTitle = fmt.Sprintf("ID %d", roomID)
-	}
-
-	nominationName, err := a.store.GetNominationName(nominationID)
-	if err != nil {
-		if !errors.Is(err, storage.ErrNotFound) {
-			log.Println("results get nomination name:", err)
-		}
-		nominationName = fmt.Sprintf("ID %d", nominationID)
-	}
-
-	results, err := a.store.ResultsByNomination(nominationID)
-	if err != nil {
-		log.Println("results nominees:", err)
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось получить результаты."))
-		return
-	}
-
-	var sb strings.Builder
-	fmt.Fprintf(&sb,
-		"Результаты голосования\nКомната: %s (ID %d)\nНоминация: %s (ID %d)\n\n",
-		roomTitle, roomID, nominationName, nominationID,
-	)
-
-	if len(results) == 0 {
-		sb.WriteString("В этой номинации пока нет номинантов.\n")
-	} else {
-		for _, r := range results {
-			fmt.Fprintf(&sb, "• %s (ID %d) — %d голос(ов)\n", r.Name, r.ID, r.Votes)
-		}
-	}
-
-	text := sb.String()
-	if len(text) > 4000 {
-		text = text[:4000] + "\n\n(обрезано, слишком много текста)"
-	}
-	a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+	a.showResults(msg.Chat.ID, msg.From.ID, sess, roomID, nominationID)
 }
 
 // ---------- Медиа / создание номинантов / утилиты ----------
@@ -868,14 +992,14 @@ func (a *App) handleMediaUpload(msg *tgbotapi.Message, sess *session.Session) {
 		return
 	}
 
-	ok, err := a.store.IsNomineeOwner(nomineeID, msg.From.ID)
+	role, err := a.store.NomineeRole(nomineeID, msg.From.ID)
 	if err != nil {
-		log.Println("isNomineeOwner(media):", err)
+		log.Println("NomineeRole(media):", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
 		return
 	}
-	if !ok {
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор комнаты может менять медиа у номинантов."))
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор или админы комнаты могут менять медиа у номинантов."))
 		return
 	}
 
@@ -916,14 +1040,14 @@ func (a *App) handleCreateNomineeTextStep(msg *tgbotapi.Message, sess *session.S
 	// сбрасываем флаг создания (чтобы не зациклиться)
 	sess.CreatingNomineeForNominationID = 0
 
-	ok, err := a.store.IsNominationOwner(nominationID, msg.From.ID)
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
 	if err != nil {
-		log.Println("IsNominationOwner(create nominee text):", err)
+		log.Println("NominationRole(create nominee text):", err)
 		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
 		return
 	}
-	if !ok {
-		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор комнаты может добавлять номинантов."))
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Только автор или админы комнаты могут добавлять номинантов."))
 		return
 	}
 
@@ -963,36 +1087,87 @@ func (a *App) sendNominationsList(chatID, userID, roomID int64) error {
 		return sendErr
 	}
 
-	isOwner, err := a.store.IsRoomOwner(roomID, userID)
+	role, err := a.store.RoomRole(roomID, userID)
+	if err != nil {
+		log.Println("RoomRole in sendNominationsList:", err)
+		role = domain.RoleNone
+	}
+
+	status, err := a.store.GetRoomStatus(roomID)
+	if err != nil {
+		log.Println("GetRoomStatus in sendNominationsList:", err)
+	}
+
+	// участникам — кнопки только у опубликованных номинаций
+	var published map[int64]domain.ResultsMode
+	if !role.CanViewResults() && status == domain.RoomPublished {
+		published, err = a.store.ListResultsModes(roomID)
+		if err != nil {
+			log.Println("ListResultsModes in sendNominationsList:", err)
+		}
+	}
+
+	voted, err := a.store.VotedNominations(a.hashUserID(userID), roomID)
 	if err != nil {
-		log.Println("IsRoomOwner in sendNominationsList:", err)
-		isOwner = false
+		log.Println("VotedNominations in sendNominationsList:", err)
 	}
 
 	var buttons [][]tgbotapi.InlineKeyboardButton
 	var sb strings.Builder
 
+	fmt.Fprintf(&sb, "Статус: %s\n\n", status.Title())
 	sb.WriteString("Список номинаций в комнате:\n")
 	for _, n := range nominations {
-		fmt.Fprintf(&sb, "ID %d — %s\n", n.ID, n.Name)
+		mark := "▫️"
+		if voted[n.ID] {
+			mark = "✅"
+		}
+		if mode := nominationModeTitle(n); mode != "" {
+			fmt.Fprintf(&sb, "%s ID %d — %s (%s)\n", mark, n.ID, n.Name, mode)
+		} else {
+			fmt.Fprintf(&sb, "%s ID %d — %s\n", mark, n.ID, n.Name)
+		}
+		if n.ParentID != 0 {
+			fmt.Fprintf(&sb, "   ↳ следующий тур номинации ID %d\n", n.ParentID)
+		}
 
 		openData := fmt.Sprintf("nomination:%d", n.ID)
-		openBtn := tgbotapi.NewInlineKeyboardButtonData("🗳 Открыть", openData)
+		openLabel := "🗳 Открыть"
+		if voted[n.ID] {
+			openLabel = "✅ Открыть"
+		}
+		openBtn := tgbotapi.NewInlineKeyboardButtonData(openLabel, openData)
 
-		if isOwner {
-			resData := fmt.Sprintf("res_nom:%d", n.ID)
+		resData := fmt.Sprintf("res_nom:%d", n.ID)
+		if role.CanViewResults() {
 			resBtn := tgbotapi.NewInlineKeyboardButtonData("📊 Результаты", resData)
 			buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(openBtn, resBtn))
+		} else if published[n.ID] != domain.ResultsHidden {
+			resBtn := tgbotapi.NewInlineKeyboardButtonData("🏆 Итоги", resData)
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(openBtn, resBtn))
 		} else {
 			buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(openBtn))
 		}
 	}
 
+	sb.WriteString("\n✅ — ты уже проголосовал(а), ▫️ — ещё нет.\n")
+	if status == domain.RoomOpen {
+		sb.WriteString("Кнопка «🧭 Голосовать во всём» проведёт по номинациям по очереди.\n")
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🧭 Голосовать во всём", "wiz:start"),
+		))
+	}
 	sb.WriteString("\nЭти ID можно использовать в командах:\n")
 	sb.WriteString("/add_nominee nominationID | Имя\n")
 	sb.WriteString("/delete_nomination nominationID\n")
 	sb.WriteString("/results nominationID\n")
 
+	if role.CanManage() {
+		if row := roomStatusButtons(roomID, status); row != nil {
+			buttons = append(buttons, row)
+		}
+	}
+
 	kb := tgbotapi.NewInlineKeyboardMarkup(buttons...)
 	msg := tgbotapi.NewMessage(chatID, sb.String())
 	msg.ReplyMarkup = kb
@@ -1001,9 +1176,45 @@ func (a *App) sendNominationsList(chatID, userID, roomID int64) error {
 }
 
 func (a *App) sendNominees(chatID, userID, nominationID int64) error {
-	nominationName, err := a.store.GetNominationName(nominationID)
+	nom, err := a.store.GetNomination(nominationID)
 	if err != nil && !errors.Is(err, storage.ErrNotFound) {
-		log.Println("get nomination name:", err)
+		log.Println("get nomination:", err)
+	}
+	var nominationName string
+	maxChoices := 1
+	kind := domain.KindPlurality
+	if nom != nil {
+		nominationName = nom.Name
+		maxChoices = nom.MaxChoices
+		kind = nom.Kind
+	}
+	ranked := kind == domain.KindRanked
+
+	// показываем, за кого пользователь уже голосовал: в approval-номинации иначе непонятно,
+	// что снимет повторное нажатие, в обычной — у выбранного номинанта отметка и кнопка «отозвать»
+	var voted map[int64]bool
+	if kind == domain.KindPlurality {
+		voted, err = a.store.UserNomineeVotes(a.hashUserID(userID), nominationID)
+		if err != nil {
+			log.Println("UserNomineeVotes:", err)
+		}
+	}
+	// в комнате без переголосования отданный голос окончательный: ни «отозвать», ни «голосовать» за другого
+	finalVotes := false
+	if kind == domain.KindPlurality && maxChoices == 1 && nom != nil {
+		room, err := a.store.GetRoom(nom.RoomID)
+		if err != nil {
+			log.Println("GetRoom(sendNominees):", err)
+		} else {
+			finalVotes = !room.AllowRevote
+		}
+	}
+	var myScores map[int64]int
+	if kind == domain.KindScore {
+		myScores, err = a.store.UserScores(a.hashUserID(userID), nominationID)
+		if err != nil {
+			log.Println("UserScores:", err)
+		}
 	}
 
 	nominees, err := a.store.ListNominees(nominationID)
@@ -1013,21 +1224,35 @@ func (a *App) sendNominees(chatID, userID, nominationID int64) error {
 		return err
 	}
 
-	isOwner, err := a.store.IsNominationOwner(nominationID, userID)
+	role, err := a.store.NominationRole(nominationID, userID)
 	if err != nil {
-		log.Println("IsNominationOwner(sendNominees):", err)
-		isOwner = false
+		log.Println("NominationRole(sendNominees):", err)
+		role = domain.RoleNone
 	}
+	canManage := role.CanManage()
 
 	// заголовок
+	header := fmt.Sprintf("🏆 Номинация ID %d", nominationID)
 	if nominationName != "" {
-		a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🏆 Номинация: %s (ID %d)", nominationName, nominationID)))
-	} else {
-		a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🏆 Номинация ID %d", nominationID)))
-	}
-
-	// отдельная кнопка "➕ Добавить номинанта" для владельца
-	if isOwner {
+		header = fmt.Sprintf("🏆 Номинация: %s (ID %d)", nominationName, nominationID)
+	}
+	if ranked {
+		header += "\nРанжирование: расставь номинантов по порядку в бюллетене ниже."
+	} else if kind == domain.KindScore {
+		header += "\nОцени каждого номинанта от 1 до 5, оценку можно менять."
+	} else if kind == domain.KindPairwise {
+		header += "\nСравнивай пары: нажимай на того, кто лучше. Сравнивать можно сколько угодно."
+	} else if kind == domain.KindReferendum {
+		header += "\nРеферендум: ответь «за», «против» или воздержись, ответ можно поменять."
+	} else if maxChoices > 1 {
+		header += fmt.Sprintf("\nМожно отметить до %d номинантов, повторное нажатие снимает отметку.", maxChoices)
+	} else if finalVotes {
+		header += "\nВ этой комнате голос окончательный: поменять или отозвать его нельзя."
+	}
+	a.send(tgbotapi.NewMessage(chatID, header))
+
+	// отдельная кнопка "➕ Добавить номинанта" для организаторов (в референдуме номинантов нет)
+	if canManage && kind != domain.KindReferendum {
 		kb := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData("➕ Добавить номинанта", fmt.Sprintf("addnom:%d", nominationID)),
@@ -1043,6 +1268,19 @@ func (a *App) sendNominees(chatID, userID, nominationID int64) error {
 		}
 	}
 
+	if kind == domain.KindBracket {
+		a.sendBracketMatch(chatID, userID, nom, canManage)
+		return nil
+	}
+	if kind == domain.KindPairwise {
+		a.sendPair(chatID, userID, nom)
+		return nil
+	}
+	if kind == domain.KindReferendum {
+		a.sendReferendum(chatID, userID, nom)
+		return nil
+	}
+
 	if len(nominees) == 0 {
 		m := tgbotapi.NewMessage(chatID, "В этой номинации пока нет номинантов.")
 		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
@@ -1056,14 +1294,38 @@ func (a *App) sendNominees(chatID, userID, nominationID int64) error {
 
 	for _, n := range nominees {
 		voteData := fmt.Sprintf("vote:%d", n.ID)
-		voteRow := tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ Голосовать", voteData),
-		)
 
-		rows := [][]tgbotapi.InlineKeyboardButton{voteRow}
+		var rows [][]tgbotapi.InlineKeyboardButton
+		var hint string
+		switch {
+		case ranked:
+			// голосуют в общем бюллетене после карточек
+			hint = "Выбери место для номинанта в бюллетене ниже."
+		case kind == domain.KindScore:
+			hint = "Поставь оценку от 1 до 5."
+			if v := myScores[n.ID]; v > 0 {
+				hint = fmt.Sprintf("Твоя оценка: %s", strings.Repeat("⭐", v))
+			}
+			rows = append(rows, scoreRow(n.ID, myScores[n.ID]))
+		case maxChoices > 1:
+			label := "☐ Отметить"
+			hint = "Нажми кнопку, чтобы отметить номинанта."
+			if voted[n.ID] {
+				label, hint = "☑️ Отмечено — снять", "☑️ Ты отметил(а) этого номинанта."
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(label, voteData),
+			))
+		default:
+			var row []tgbotapi.InlineKeyboardButton
+			row, hint = pluralityRow(n.ID, voted[n.ID], len(voted) > 0, finalVotes)
+			if row != nil {
+				rows = append(rows, row)
+			}
+		}
 
-		// если владелец комнаты — добавляем кнопки "Медиа" и "Удалить"
-		if isOwner {
+		// если организатор комнаты — добавляем кнопки "Медиа" и "Удалить"
+		if canManage {
 			adminRow := tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData("🖼 Медиа", fmt.Sprintf("setmedia:%d", n.ID)),
 				tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить", fmt.Sprintf("delnom:%d", n.ID)),
@@ -1078,30 +1340,64 @@ func (a *App) sendNominees(chatID, userID, nominationID int64) error {
 		rows = append(rows, backRow)
 
 		kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
-		caption := fmt.Sprintf("ID %d — %s\n\nНажми кнопку, чтобы отдать голос.", n.ID, n.Name)
-
-		if n.MediaFileID != "" && n.MediaType == "photo" {
-			photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(n.MediaFileID))
-			photo.Caption = caption
-			photo.ReplyMarkup = kb
-			if _, err := a.bot.Send(photo); err != nil {
-				log.Println("send nominee photo:", err)
-			}
-		} else if n.MediaFileID != "" && n.MediaType == "video" {
-			video := tgbotapi.NewVideo(chatID, tgbotapi.FileID(n.MediaFileID))
-			video.Caption = caption
-			video.ReplyMarkup = kb
-			if _, err := a.bot.Send(video); err != nil {
-				log.Println("send nominee video:", err)
-			}
-		} else {
-			msg := tgbotapi.NewMessage(chatID, caption)
-			msg.ReplyMarkup = kb
-			if _, err := a.bot.Send(msg); err != nil {
-				log.Println("send nominee text:", err)
-			}
-		}
+		a.sendNomineeCard(chatID, n, nomineeCaption(n, hint, voted[n.ID]), kb)
+	}
+
+	if ranked {
+		a.sendRankedBallot(chatID, userID, nom, nil)
 	}
 
 	return nil
 }
+
+// findNominee — номинант номинации по ID; ErrNotFound, если его уже удалили.
+func (a *App) findNominee(nominationID, nomineeID int64) (*domain.Nominee, error) {
+	nominees, err := a.store.ListNominees(nominationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nominees {
+		if nominees[i].ID == nomineeID {
+			return &nominees[i], nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// editNomineeCard меняет подпись и кнопки уже отправленной карточки (см. sendNomineeCard):
+// у фото и видео правится подпись, у текстовой карточки — текст.
+func (a *App) editNomineeCard(card *tgbotapi.Message, caption string, kb tgbotapi.InlineKeyboardMarkup) {
+	if len(card.Photo) > 0 || card.Video != nil {
+		edit := tgbotapi.NewEditMessageCaption(card.Chat.ID, card.MessageID, caption)
+		edit.ReplyMarkup = &kb
+		a.send(edit)
+		return
+	}
+	a.send(tgbotapi.NewEditMessageTextAndMarkup(card.Chat.ID, card.MessageID, caption, kb))
+}
+
+// sendNomineeCard — карточка номинанта: фото, видео или просто текст, с подписью и кнопками;
+// kb == nil — без кнопок.
+func (a *App) sendNomineeCard(chatID int64, n domain.Nominee, caption string, kb any) {
+	if n.MediaFileID != "" && n.MediaType == "photo" {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(n.MediaFileID))
+		photo.Caption = caption
+		photo.ReplyMarkup = kb
+		if _, err := a.bot.Send(photo); err != nil {
+			log.Println("send nominee photo:", err)
+		}
+	} else if n.MediaFileID != "" && n.MediaType == "video" {
+		video := tgbotapi.NewVideo(chatID, tgbotapi.FileID(n.MediaFileID))
+		video.Caption = caption
+		video.ReplyMarkup = kb
+		if _, err := a.bot.Send(video); err != nil {
+			log.Println("send nominee video:", err)
+		}
+	} else {
+		msg := tgbotapi.NewMessage(chatID, caption)
+		msg.ReplyMarkup = kb
+		if _, err := a.bot.Send(msg); err != nil {
+			log.Println("send nominee text:", err)
+		}
+	}
+}
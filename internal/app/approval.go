@@ -0,0 +1,136 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+// maxChoicesLimit — верхняя граница для /set_max_choices, чтобы не было опечаток вида 100.
+const maxChoicesLimit = 20
+
+// ---------- Команды ----------
+
+// handleSetMaxChoices — /set_max_choices nominationID K
+func (a *App) handleSetMaxChoices(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		text := "Формат: /set_max_choices nominationID K\n\n" +
+			"K — сколько номинантов участник может отметить в номинации (1 — обычный выбор одного).\n" +
+			"Менять можно, пока в номинации нет голосов."
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	nominationID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "nominationID должно быть числом."))
+		return
+	}
+	k, err := strconv.Atoi(args[1])
+	if err != nil || k < 1 || k > maxChoicesLimit {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("K должно быть числом от 1 до %d.", maxChoicesLimit)))
+		return
+	}
+
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
+	if err != nil {
+		log.Println("NominationRole(set_max_choices):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Настраивать номинацию могут только автор или админы комнаты."))
+		return
+	}
+
+	nom, err := a.store.GetNomination(nominationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Номинация не найдена."))
+		} else {
+			log.Println("GetNomination(set_max_choices):", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка при получении номинации."))
+		}
+		return
+	}
+	if nom.Kind != domain.KindPlurality {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Лимит выбора настраивается только в номинациях с выбором, а эта — %s.", nom.Kind.Title())))
+		return
+	}
+
+	if err := a.store.SetNominationMaxChoices(nominationID, k); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Номинация не найдена."))
+		case errors.Is(err, storage.ErrHasVotes):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "В номинации уже есть голоса — лимит менять нельзя."))
+		default:
+			log.Println("SetNominationMaxChoices:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить настройку."))
+		}
+		return
+	}
+
+	if k == 1 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Готово ✅ В номинации снова выбирают одного номинанта."))
+		return
+	}
+	a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Готово ✅ Теперь в номинации можно отметить до %d номинантов.", k)))
+}
+
+// ---------- Кнопки ----------
+
+// handleToggleVote — кнопка vote: в номинации с max_choices > 1 ставит/снимает отметку.
+func (a *App) handleToggleVote(chatID, userID int64, nom *domain.Nomination, nomineeID int64) {
+	added, selected, err := a.store.ToggleVote(a.hashUserID(userID), nom.ID, nomineeID, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrVotingClosed), errors.Is(err, storage.ErrNextRound):
+			a.send(tgbotapi.NewMessage(chatID, "Голос не принят: "+a.voteRejectedReason(nom.RoomID, err)+"."))
+		case errors.Is(err, storage.ErrTooManyChoices):
+			a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+				"Уже отмечено %d из %d. Сними отметку с кого-нибудь, чтобы выбрать другого.", selected, nom.MaxChoices)))
+		case errors.Is(err, storage.ErrVoteLocked):
+			a.send(tgbotapi.NewMessage(chatID, "В этой комнате голос окончательный — поставленную отметку снять нельзя."))
+		default:
+			log.Println("ToggleVote:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		}
+		return
+	}
+
+	name, err := a.store.GetNomineeName(nomineeID)
+	if err != nil {
+		log.Println("get nominee name:", err)
+	}
+	if name == "" {
+		name = fmt.Sprintf("ID %d", nomineeID)
+	}
+
+	var text string
+	if added {
+		text = fmt.Sprintf("☑️ Отмечено: %s\nВыбрано %d из %d.", name, selected, nom.MaxChoices)
+	} else {
+		text = fmt.Sprintf("Отметка снята: %s\nВыбрано %d из %d.", name, selected, nom.MaxChoices)
+	}
+
+	m := tgbotapi.NewMessage(chatID, text)
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить номинантов", fmt.Sprintf("nomination:%d", nom.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+		),
+	)
+	a.send(m)
+}
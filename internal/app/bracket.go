@@ -0,0 +1,508 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+// maxMatchMinutes — верхняя граница длительности матча (сутки).
+const maxMatchMinutes = 24 * 60
+
+// ---------- Команды ----------
+
+// handleBracket — /bracket nominationID [минут_на_матч]
+func (a *App) handleBracket(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		text := "Формат: /bracket nominationID [минут_на_матч]\n\n" +
+			"Раскладывает номинантов по турнирной сетке на выбывание (посев — в порядке добавления) " +
+			"и открывает первый матч. Номинация должна быть в режиме bracket (/nomination_mode).\n" +
+			"минут_на_матч — матч закрывается сам через это время; без него (или 0) следующий матч " +
+			"запускает организатор кнопкой или /next_match.\n\n" +
+			"Пример:\n/bracket 5 30"
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	nominationID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "nominationID должно быть числом."))
+		return
+	}
+	minutes := 0
+	if len(args) == 2 {
+		minutes, err = strconv.Atoi(args[1])
+		if err != nil || minutes < 0 || minutes > maxMatchMinutes {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Минуты на матч — число от 0 до %d.", maxMatchMinutes)))
+			return
+		}
+	}
+
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
+	if err != nil {
+		log.Println("NominationRole(bracket):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Строить сетку могут только автор или админы комнаты."))
+		return
+	}
+
+	nominees, err := a.store.ListNominees(nominationID)
+	if err != nil {
+		log.Println("ListNominees(bracket):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось получить список номинантов."))
+		return
+	}
+	if len(nominees) < 2 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Для сетки нужно хотя бы два номинанта."))
+		return
+	}
+	seeded := make([]int64, 0, len(nominees))
+	for _, n := range nominees {
+		seeded = append(seeded, n.ID)
+	}
+
+	opened, err := a.store.CreateBracket(nominationID, tally.BracketPairs(seeded), minutes, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Номинация не найдена."))
+		case errors.Is(err, storage.ErrNotBracket):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Сначала переведи номинацию в режим сетки: /nomination_mode %d bracket", nominationID)))
+		case errors.Is(err, storage.ErrBracketExists):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Сетка этой номинации уже построена."))
+		default:
+			log.Println("CreateBracket:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось построить сетку."))
+		}
+		return
+	}
+
+	a.reportMatches(msg.Chat.ID, nominationID, nil, opened)
+}
+
+// handleNextMatch — /next_match nominationID
+func (a *App) handleNextMatch(msg *tgbotapi.Message) {
+	nominationID, err := strconv.ParseInt(strings.TrimSpace(msg.CommandArguments()), 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Формат: /next_match nominationID"))
+		return
+	}
+	m, err := a.store.CurrentMatch(nominationID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Println("CurrentMatch(next_match):", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось переключить матч."))
+			return
+		}
+		// открытого матча нет: сетку не построили или турнир окончен — nextMatch скажет, что именно
+		m = &domain.BracketMatch{NominationID: nominationID}
+	}
+	a.nextMatch(msg.Chat.ID, msg.From.ID, m.NominationID, m.ID)
+}
+
+// ---------- Кнопки ----------
+
+// handleMatchCallback — match_vote:<matchID>:<nomineeID> и match_next:<matchID>
+func (a *App) handleMatchCallback(cq *tgbotapi.CallbackQuery, sess *session.Session, data string) {
+	chatID := cq.Message.Chat.ID
+
+	if rest, ok := strings.CutPrefix(data, "match_next:"); ok {
+		matchID, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return
+		}
+		m, err := a.store.GetMatch(matchID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				a.send(tgbotapi.NewMessage(chatID, "Этот матч больше не существует."))
+			} else {
+				log.Println("GetMatch(next):", err)
+			}
+			return
+		}
+		a.nextMatch(chatID, cq.From.ID, m.NominationID, m.ID)
+		return
+	}
+
+	matchStr, nomineeStr, ok := strings.Cut(strings.TrimPrefix(data, "match_vote:"), ":")
+	if !ok {
+		return
+	}
+	matchID, err := strconv.ParseInt(matchStr, 10, 64)
+	if err != nil {
+		return
+	}
+	nomineeID, err := strconv.ParseInt(nomineeStr, 10, 64)
+	if err != nil {
+		return
+	}
+
+	m, err := a.store.GetMatch(matchID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(chatID, "Этот матч больше не существует."))
+		} else {
+			log.Println("GetMatch:", err)
+		}
+		return
+	}
+	nom, err := a.store.GetNomination(m.NominationID)
+	if err != nil {
+		log.Println("get nomination(match):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		return
+	}
+	if sess.ActiveRoomID != nom.RoomID {
+		a.send(tgbotapi.NewMessage(chatID, "У тебя нет доступа к этой комнате. Сначала зайди в неё командой /room."))
+		return
+	}
+
+	if err := a.store.RecordMatchVote(a.hashUserID(cq.From.ID), matchID, nomineeID, time.Now()); err != nil {
+		if reason := a.voteRejectedReason(nom.RoomID, err); reason != "" {
+			a.send(tgbotapi.NewMessage(chatID, "Голос не принят: "+reason+"."))
+			return
+		}
+		switch {
+		case errors.Is(err, storage.ErrMatchClosed):
+			a.send(tgbotapi.NewMessage(chatID, "Этот матч уже закончился — открой номинацию заново, чтобы увидеть текущий."))
+		case errors.Is(err, storage.ErrNomineeMismatch):
+			a.send(tgbotapi.NewMessage(chatID, "Этот номинант не участвует в матче."))
+		case errors.Is(err, storage.ErrVoteLocked):
+			a.send(tgbotapi.NewMessage(chatID, "В этой комнате голос окончательный — в этом матче он уже отдан."))
+		default:
+			log.Println("RecordMatchVote:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		}
+		return
+	}
+
+	names, err := a.nomineeNames(nom.ID)
+	if err != nil {
+		log.Println("nomineeNames(match):", err)
+	}
+	if cq.Message.ReplyMarkup != nil {
+		role, err := a.store.NominationRole(nom.ID, cq.From.ID)
+		if err != nil {
+			log.Println("NominationRole(match):", err)
+		}
+		a.send(tgbotapi.NewEditMessageReplyMarkup(chatID, cq.Message.MessageID, matchKeyboard(*m, names, nomineeID, role.CanManage())))
+	}
+	a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Голос принят: %s ✅ До конца матча его можно поменять.", nomineeName(names, nomineeID))))
+}
+
+// ---------- Утилиты ----------
+
+// nextMatch закрывает матч matchID и открывает следующий. matchID == 0 — открытого матча нет.
+func (a *App) nextMatch(chatID, userID, nominationID, matchID int64) {
+	role, err := a.store.NominationRole(nominationID, userID)
+	if err != nil {
+		log.Println("NominationRole(next_match):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(chatID, "Переключать матчи могут только автор или админы комнаты."))
+		return
+	}
+
+	closed, opened, err := a.store.AdvanceBracket(nominationID, matchID, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Сетка ещё не построена: /bracket %d", nominationID)))
+		case errors.Is(err, storage.ErrMatchClosed) && matchID == 0:
+			a.send(tgbotapi.NewMessage(chatID, "Турнир уже окончен — итоги в /results."))
+		case errors.Is(err, storage.ErrMatchClosed):
+			a.send(tgbotapi.NewMessage(chatID, "Этот матч уже завершён — текущий матч в номинации или /next_match."))
+		default:
+			log.Println("AdvanceBracket:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Не удалось переключить матч."))
+		}
+		return
+	}
+	a.reportMatches(chatID, nominationID, closed, opened)
+}
+
+// runDueMatches закрывает матчи, у которых истекло время, и открывает следующие; итог — автору комнаты.
+func (a *App) runDueMatches(now time.Time) {
+	ids, err := a.store.DueMatches(now)
+	if err != nil {
+		log.Println("DueMatches:", err)
+		return
+	}
+	for _, matchID := range ids {
+		closed, opened, err := a.store.AdvanceDueMatch(matchID, now)
+		if err != nil {
+			log.Println("AdvanceDueMatch:", err)
+			continue
+		}
+		if closed == nil {
+			continue // матч успели переключить вручную
+		}
+		nominationID := closed.NominationID
+		roomID, err := a.store.GetNominationRoomID(nominationID)
+		if err != nil {
+			log.Println("GetNominationRoomID(scheduler):", err)
+			continue
+		}
+		ownerID, err := a.store.GetRoomOwnerID(roomID)
+		if err != nil {
+			log.Println("GetRoomOwnerID(scheduler):", err)
+			continue
+		}
+		a.reportMatches(ownerID, nominationID, closed, opened)
+	}
+}
+
+// reportMatches — итог закрытого матча и следующий матч (или победитель турнира).
+func (a *App) reportMatches(chatID, nominationID int64, closed, opened *domain.BracketMatch) {
+	names, err := a.nomineeNames(nominationID)
+	if err != nil {
+		log.Println("nomineeNames(report):", err)
+	}
+
+	var sb strings.Builder
+	if closed != nil {
+		fmt.Fprintf(&sb, "⏹ Матч завершён (%s): %s\n\n", roundTitle(closed.Round, closed.Rounds), formatMatch(*closed, names, true))
+	}
+	switch {
+	case opened != nil:
+		fmt.Fprintf(&sb, "⚔️ Следующий матч (%s): %s против %s", roundTitle(opened.Round, opened.Rounds),
+			nomineeName(names, opened.NomineeA), nomineeName(names, opened.NomineeB))
+		if !opened.ClosesAt.IsZero() {
+			fmt.Fprintf(&sb, "\nГолосование до %s UTC.", opened.ClosesAt.UTC().Format(scheduleLayout))
+		}
+	case closed != nil:
+		fmt.Fprintf(&sb, "🏆 Турнир окончен, победитель: %s", nomineeName(names, closed.WinnerID))
+	default:
+		sb.WriteString("Сетка построена, но играть некому.")
+	}
+
+	m := tgbotapi.NewMessage(chatID, sb.String())
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗂 Открыть номинацию", fmt.Sprintf("nomination:%d", nominationID)),
+		),
+	)
+	a.send(m)
+}
+
+// sendBracketMatch — экран номинации-сетки: карточки соперников текущего матча, сетка и кнопки голоса.
+func (a *App) sendBracketMatch(chatID, userID int64, nom *domain.Nomination, canManage bool) {
+	back := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+	)
+
+	matches, err := a.store.BracketMatches(nom.ID)
+	if err != nil {
+		log.Println("BracketMatches:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить сетку 😔"))
+		return
+	}
+	if len(matches) == 0 {
+		text := "Турнирная сетка ещё не построена."
+		if canManage {
+			text += fmt.Sprintf("\nДобавь номинантов и построй её: /bracket %d [минут_на_матч]", nom.ID)
+		}
+		m := tgbotapi.NewMessage(chatID, text)
+		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(back)
+		a.send(m)
+		return
+	}
+
+	nominees, err := a.store.ListNominees(nom.ID)
+	if err != nil {
+		log.Println("ListNominees(bracket):", err)
+	}
+	byID := make(map[int64]domain.Nominee, len(nominees))
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		byID[n.ID] = n
+		names[n.ID] = n.Name
+	}
+
+	var current *domain.BracketMatch
+	for i := range matches {
+		if matches[i].Status == domain.MatchOpen {
+			current = &matches[i]
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(formatBracket(matches, names, false))
+	if current == nil {
+		final := matches[len(matches)-1]
+		fmt.Fprintf(&sb, "\n🏆 Турнир окончен, победитель: %s", nomineeName(names, final.WinnerID))
+		m := tgbotapi.NewMessage(chatID, sb.String())
+		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(back)
+		a.send(m)
+		return
+	}
+
+	for i, id := range []int64{current.NomineeA, current.NomineeB} {
+		if n, ok := byID[id]; ok {
+			a.sendNomineeCard(chatID, n, fmt.Sprintf("%s %s", []string{"🔴", "🔵"}[i], n.Name), nil)
+		}
+	}
+
+	chosen, err := a.store.UserMatchVote(a.hashUserID(userID), current.ID)
+	if err != nil {
+		log.Println("UserMatchVote:", err)
+	}
+	fmt.Fprintf(&sb, "\n⚔️ Сейчас (%s): %s против %s\n", roundTitle(current.Round, current.Rounds),
+		nomineeName(names, current.NomineeA), nomineeName(names, current.NomineeB))
+	if current.ClosesAt.IsZero() {
+		sb.WriteString("Матч закончится, когда организатор запустит следующий.")
+	} else {
+		fmt.Fprintf(&sb, "Голосование до %s UTC.", current.ClosesAt.UTC().Format(scheduleLayout))
+	}
+
+	m := tgbotapi.NewMessage(chatID, sb.String())
+	m.ReplyMarkup = matchKeyboard(*current, names, chosen, canManage)
+	a.send(m)
+}
+
+// matchKeyboard — кнопки голоса за соперников (выбранный отмечен), «следующий матч» для организаторов и «назад».
+func matchKeyboard(m domain.BracketMatch, names map[int64]string, chosen int64, canManage bool) tgbotapi.InlineKeyboardMarkup {
+	var vote []tgbotapi.InlineKeyboardButton
+	for i, id := range []int64{m.NomineeA, m.NomineeB} {
+		label := []string{"🔴 ", "🔵 "}[i] + nomineeName(names, id)
+		if id == chosen {
+			label = "✅ " + nomineeName(names, id)
+		}
+		vote = append(vote, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("match_vote:%d:%d", m.ID, id)))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{vote}
+	if canManage {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏭ Завершить матч", fmt.Sprintf("match_next:%d", m.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// ---------- Итоги ----------
+
+func (a *App) bracketResults(nominationID int64, mode domain.ResultsMode) (string, error) {
+	matches, err := a.store.BracketMatches(nominationID)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "Турнирная сетка ещё не построена.\n", nil
+	}
+	names, err := a.nomineeNames(nominationID)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if mode != domain.ResultsWinners {
+		sb.WriteString(formatBracket(matches, names, true))
+		sb.WriteString("\n")
+	}
+	final := matches[len(matches)-1]
+	if final.Status == domain.MatchDone {
+		fmt.Fprintf(&sb, "🏆 Победитель турнира: %s\n", nomineeName(names, final.WinnerID))
+	} else {
+		sb.WriteString("Турнир ещё идёт.\n")
+	}
+	return sb.String(), nil
+}
+
+// formatBracket — сетка по кругам. withVotes — показывать счёт матчей (организаторам и в итогах).
+func formatBracket(matches []domain.BracketMatch, names map[int64]string, withVotes bool) string {
+	var sb strings.Builder
+	round := 0
+	for _, m := range matches {
+		if m.Round != round {
+			if round != 0 {
+				sb.WriteString("\n")
+			}
+			round = m.Round
+			fmt.Fprintf(&sb, "%s:\n", roundTitle(m.Round, m.Rounds))
+		}
+		fmt.Fprintf(&sb, "• %s\n", formatMatch(m, names, withVotes))
+	}
+	return sb.String()
+}
+
+func formatMatch(m domain.BracketMatch, names map[int64]string, withVotes bool) string {
+	a, b := nomineeName(names, m.NomineeA), nomineeName(names, m.NomineeB)
+	switch m.Status {
+	case domain.MatchDone:
+		if m.NomineeA == 0 || m.NomineeB == 0 {
+			return nomineeName(names, m.WinnerID) + " — проходит без соперника"
+		}
+		if withVotes {
+			return fmt.Sprintf("%s %d:%d %s → %s", a, m.VotesA, m.VotesB, b, nomineeName(names, m.WinnerID))
+		}
+		return fmt.Sprintf("%s — %s → %s", a, b, nomineeName(names, m.WinnerID))
+	case domain.MatchOpen:
+		if withVotes {
+			return fmt.Sprintf("%s %d:%d %s ⏳ идёт голосование", a, m.VotesA, m.VotesB, b)
+		}
+		return fmt.Sprintf("%s — %s ⏳ идёт голосование", a, b)
+	default:
+		return fmt.Sprintf("%s — %s", a, b)
+	}
+}
+
+// roundTitle — «Финал», «Полуфинал», «Четвертьфинал» или «Круг N».
+func roundTitle(round, rounds int) string {
+	switch rounds - round {
+	case 0:
+		return "Финал"
+	case 1:
+		return "Полуфинал"
+	case 2:
+		return "Четвертьфинал"
+	default:
+		return fmt.Sprintf("Круг %d", round)
+	}
+}
+
+// nomineeName — имя из справочника; пустое место — «?», удалённый номинант — его ID.
+func nomineeName(names map[int64]string, id int64) string {
+	if id == 0 {
+		return "?"
+	}
+	if name, ok := names[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("ID %d", id)
+}
+
+// bracketScores — показатель для standings: насколько далеко номинант прошёл по сетке.
+func bracketScores(matches []domain.BracketMatch) map[int64]float64 {
+	scores := make(map[int64]float64)
+	for _, m := range matches {
+		for _, id := range []int64{m.NomineeA, m.NomineeB} {
+			if id != 0 {
+				scores[id] = max(scores[id], float64(m.Round))
+			}
+		}
+		if m.Status == domain.MatchDone && m.WinnerID != 0 {
+			scores[m.WinnerID] = max(scores[m.WinnerID], float64(m.Round+1))
+		}
+	}
+	return scores
+}
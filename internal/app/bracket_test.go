@@ -0,0 +1,100 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestRoundTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		round, rounds int
+		want          string
+	}{
+		{"final", 3, 3, "Финал"},
+		{"semifinal", 2, 3, "Полуфинал"},
+		{"quarterfinal", 2, 4, "Четвертьфинал"},
+		{"early_round", 1, 4, "Круг 1"},
+		{"two_nominees", 1, 1, "Финал"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := roundTitle(tt.round, tt.rounds); got != tt.want {
+				t.Fatalf("got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBracket(t *testing.T) {
+	t.Parallel()
+
+	names := map[int64]string{1: "A", 2: "B", 3: "C"}
+	matches := []domain.BracketMatch{
+		{Round: 1, Rounds: 2, Position: 0, NomineeA: 1, WinnerID: 1, Status: domain.MatchDone},
+		{Round: 1, Rounds: 2, Position: 1, NomineeA: 2, NomineeB: 3, VotesA: 2, VotesB: 1, WinnerID: 2, Status: domain.MatchDone},
+		{Round: 2, Rounds: 2, NomineeA: 1, NomineeB: 2, VotesA: 4, VotesB: 5, Status: domain.MatchOpen},
+	}
+
+	withVotes := formatBracket(matches, names, true)
+	for _, want := range []string{"Полуфинал:", "A — проходит без соперника", "B 2:1 C → B", "Финал:", "A 4:5 B ⏳"} {
+		if !strings.Contains(withVotes, want) {
+			t.Fatalf("missing %q in:\n%s", want, withVotes)
+		}
+	}
+
+	// участникам счёт открытого матча не показываем
+	hidden := formatBracket(matches, names, false)
+	if strings.Contains(hidden, "4:5") || strings.Contains(hidden, "2:1") {
+		t.Fatalf("votes must be hidden:\n%s", hidden)
+	}
+	if !strings.Contains(hidden, "B — C → B") {
+		t.Fatalf("winner must stay visible:\n%s", hidden)
+	}
+}
+
+func TestMatchKeyboard(t *testing.T) {
+	t.Parallel()
+
+	m := domain.BracketMatch{ID: 7, NominationID: 5, NomineeA: 1, NomineeB: 2}
+	names := map[int64]string{1: "A", 2: "B"}
+
+	kb := matchKeyboard(m, names, 2, false)
+	if len(kb.InlineKeyboard) != 2 {
+		t.Fatalf("voter keyboard: want vote and back rows, got %d", len(kb.InlineKeyboard))
+	}
+	vote := kb.InlineKeyboard[0]
+	if vote[0].Text != "🔴 A" || vote[1].Text != "✅ B" {
+		t.Fatalf("unexpected labels: %q, %q", vote[0].Text, vote[1].Text)
+	}
+	if *vote[1].CallbackData != "match_vote:7:2" {
+		t.Fatalf("unexpected callback: %q", *vote[1].CallbackData)
+	}
+
+	kb = matchKeyboard(m, names, 0, true)
+	if len(kb.InlineKeyboard) != 3 || *kb.InlineKeyboard[1][0].CallbackData != "match_next:7" {
+		t.Fatalf("staff keyboard must have the next-match button: %+v", kb.InlineKeyboard)
+	}
+}
+
+func TestBracketScores(t *testing.T) {
+	t.Parallel()
+
+	matches := []domain.BracketMatch{
+		{Round: 1, NomineeA: 1, NomineeB: 2, WinnerID: 1, Status: domain.MatchDone},
+		{Round: 1, NomineeA: 3, NomineeB: 4, WinnerID: 4, Status: domain.MatchDone},
+		{Round: 2, NomineeA: 1, NomineeB: 4, WinnerID: 4, Status: domain.MatchDone},
+	}
+	got := bracketScores(matches)
+	if !(got[4] > got[1] && got[1] > got[2] && got[2] == got[3]) {
+		t.Fatalf("unexpected scores: %v", got)
+	}
+}
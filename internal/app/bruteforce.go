@@ -0,0 +1,147 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+// joinLockout — экспоненциальная блокировка /room после серии неудачных паролей:
+// на Threshold-й неудаче блокируем на Base, дальше каждый раз вдвое дольше, но не больше Max.
+type joinLockout struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+}
+
+func (p joinLockout) duration(failures int) time.Duration {
+	if failures < p.Threshold {
+		return 0
+	}
+	d := p.Base
+	for i := p.Threshold; i < failures; i++ {
+		d *= 2
+		if d >= p.Max {
+			return p.Max
+		}
+	}
+	return d
+}
+
+var (
+	// пользователь перебирает пароли (или ID комнат)
+	userJoinLockout = joinLockout{Threshold: 5, Base: time.Minute, Max: 24 * time.Hour}
+	// комнату перебирают с разных аккаунтов. Блокировка по комнате действует только на тех,
+	// у кого есть свои неудачи: иначе несколько аккаунтов с неверными паролями закрыли бы вход
+	// и участникам, которые пароль знают
+	roomJoinLockout = joinLockout{Threshold: 20, Base: time.Minute, Max: time.Hour}
+)
+
+const (
+	// если неудач не было дольше окна, счётчик начинается заново
+	joinFailureWindow = 24 * time.Hour
+	// каждые N неудач по комнате предупреждаем автора
+	roomFailureNotifyEvery = 10
+)
+
+// errJoinLocked — вход по паролю временно закрыт после серии неудачных попыток.
+var errJoinLocked = errors.New("join is locked")
+
+// joinByPassword — вход в комнату по паролю с учётом блокировок. При блокировке возвращает errJoinLocked
+// и момент, до которого она действует; при неверном пароле — storage.ErrNotFound, неудача засчитана.
+// Успешный вход счётчик пользователя не сбрасывает: иначе между попытками перебора можно заходить
+// в свою комнату, и блокировка никогда не наступит. Счётчик обнуляется сам, если неудач не было joinFailureWindow.
+func (a *App) joinByPassword(userID, roomID int64, password string, now time.Time) (*domain.Room, time.Time, error) {
+	if until, locked := a.joinLockedUntil(userID, roomID, now); locked {
+		return nil, until, errJoinLocked
+	}
+
+	room, err := a.store.GetRoomByIDAndPassword(roomID, password)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.registerJoinFailure(userID, roomID, now)
+		}
+		return nil, time.Time{}, err
+	}
+
+	if err := a.store.AddRoomVoter(room.ID, a.hashUserID(userID), domain.GroupAudience, now); err != nil {
+		log.Println("AddRoomVoter:", err)
+	}
+	return room, time.Time{}, nil
+}
+
+// joinLockedUntil возвращает момент, до которого вход по паролю запрещён: по пользователю или,
+// если пользователь сам уже ошибался, по комнате.
+func (a *App) joinLockedUntil(userID, roomID int64, now time.Time) (time.Time, bool) {
+	user, err := a.store.GetJoinFailure(storage.JoinScopeUser, userID)
+	if err != nil {
+		log.Println("GetJoinFailure(user):", err)
+	}
+	until := user.LockedUntil
+	if user.Failures > 0 {
+		room, err := a.store.GetJoinFailure(storage.JoinScopeRoom, roomID)
+		if err != nil {
+			log.Println("GetJoinFailure(room):", err)
+		}
+		if room.LockedUntil.After(until) {
+			until = room.LockedUntil
+		}
+	}
+	return until, until.After(now)
+}
+
+// registerJoinFailure учитывает неверный пароль и при необходимости блокирует вход и пишет автору комнаты.
+func (a *App) registerJoinFailure(userID, roomID int64, now time.Time) {
+	userFailures, err := a.store.RecordJoinFailure(storage.JoinScopeUser, userID, now, joinFailureWindow)
+	if err != nil {
+		log.Println("RecordJoinFailure(user):", err)
+	} else if d := userJoinLockout.duration(userFailures); d > 0 {
+		if err := a.store.SetJoinLock(storage.JoinScopeUser, userID, now.Add(d)); err != nil {
+			log.Println("SetJoinLock(user):", err)
+		}
+	}
+
+	ownerID, err := a.store.GetRoomOwnerID(roomID)
+	if err != nil {
+		// комнаты нет — считаем только по пользователю
+		return
+	}
+
+	roomFailures, err := a.store.RecordJoinFailure(storage.JoinScopeRoom, roomID, now, joinFailureWindow)
+	if err != nil {
+		log.Println("RecordJoinFailure(room):", err)
+		return
+	}
+	d := roomJoinLockout.duration(roomFailures)
+	if d > 0 {
+		if err := a.store.SetJoinLock(storage.JoinScopeRoom, roomID, now.Add(d)); err != nil {
+			log.Println("SetJoinLock(room):", err)
+		}
+	}
+
+	if roomFailures%roomFailureNotifyEvery == 0 {
+		text := fmt.Sprintf("⚠️ В комнату ID %d уже %d раз(а) пытались войти с неверным паролем за последние сутки.", roomID, roomFailures)
+		if d > 0 {
+			text += fmt.Sprintf("\nВход по паролю в комнату закрыт на %s.", formatWait(d))
+		}
+		text += "\nЕсли это не твои участники — смени пароль или создай новую комнату."
+		a.send(tgbotapi.NewMessage(ownerID, text))
+	}
+}
+
+func formatWait(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%d ч %d мин", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%d мин", int((d+time.Minute-1)/time.Minute))
+	default:
+		return fmt.Sprintf("%d сек", int((d+time.Second-1)/time.Second))
+	}
+}
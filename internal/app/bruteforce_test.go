@@ -0,0 +1,144 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+func TestJoinLockout_Duration(t *testing.T) {
+	t.Parallel()
+
+	p := joinLockout{Threshold: 3, Base: time.Minute, Max: 10 * time.Minute}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Minute},
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{6, 8 * time.Minute},
+		{7, 10 * time.Minute}, // упёрлись в Max
+		{100, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := p.duration(tt.failures); got != tt.want {
+			t.Fatalf("failures=%d: got=%s want=%s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWait(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "30 сек"},
+		{time.Minute, "1 мин"},
+		{90 * time.Second, "2 мин"},
+		{2*time.Hour + 5*time.Minute, "2 ч 5 мин"},
+	}
+
+	for _, tt := range tests {
+		if got := formatWait(tt.d); got != tt.want {
+			t.Fatalf("d=%s: got=%q want=%q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func newJoinTestApp(t *testing.T) (*App, *storage.Store) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	db.SetMaxOpenConns(1)
+	store := storage.New(db)
+	if _, err := store.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return &App{store: store, voteSalt: "salt"}, store
+}
+
+// Заход в свою комнату между попытками перебора не должен обнулять счётчик неудач пользователя.
+func TestJoinByPassword_OwnRoomDoesNotResetUserLockout(t *testing.T) {
+	t.Parallel()
+
+	a, store := newJoinTestApp(t)
+
+	const attacker = 100
+	ownRoom, err := store.CreateRoom(attacker, "своя", "mine")
+	if err != nil {
+		t.Fatalf("CreateRoom(own): %v", err)
+	}
+	// перебор ID несуществующих комнат тоже засчитывается пользователю; по комнате счёта нет,
+	// так что автору ничего не отправляется и бот в тесте не нужен
+	victimRoom := ownRoom + 1000
+
+	now := time.Unix(1_000_000, 0)
+	guess := 0
+	for round := 0; round < 3; round++ {
+		for i := 0; i < userJoinLockout.Threshold-1; i++ {
+			guess++
+			_, _, err := a.joinByPassword(attacker, victimRoom, fmt.Sprintf("guess%d", guess), now)
+			if errors.Is(err, errJoinLocked) {
+				if round == 0 {
+					t.Fatalf("locked too early, after %d guesses", guess)
+				}
+				return // блокировка наступила, несмотря на входы в свою комнату
+			}
+			if !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("wrong password: expected ErrNotFound, got %v", err)
+			}
+		}
+		if _, _, err := a.joinByPassword(attacker, ownRoom, "mine", now); err != nil {
+			t.Fatalf("join own room: %v", err)
+		}
+	}
+	t.Fatalf("%d wrong guesses interleaved with joins to the own room never locked the user", guess)
+}
+
+// Блокировка комнаты, набранная чужими ошибками, не мешает войти тому, кто пароль знает.
+func TestJoinByPassword_RoomLockSparesUsersWithoutFailures(t *testing.T) {
+	t.Parallel()
+
+	a, store := newJoinTestApp(t)
+	roomID, err := store.CreateRoom(1, "room", "secret")
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	now := time.Unix(1_000_000, 0)
+	if _, err := store.RecordJoinFailure(storage.JoinScopeRoom, roomID, now, joinFailureWindow); err != nil {
+		t.Fatalf("RecordJoinFailure(room): %v", err)
+	}
+	if err := store.SetJoinLock(storage.JoinScopeRoom, roomID, now.Add(time.Hour)); err != nil {
+		t.Fatalf("SetJoinLock(room): %v", err)
+	}
+
+	if _, _, err := a.joinByPassword(2, roomID, "secret", now); err != nil {
+		t.Fatalf("user without failures: %v", err)
+	}
+
+	// кто уже ошибался, ждёт окончания блокировки комнаты
+	if _, err := store.RecordJoinFailure(storage.JoinScopeUser, 3, now, joinFailureWindow); err != nil {
+		t.Fatalf("RecordJoinFailure(user): %v", err)
+	}
+	if _, until, err := a.joinByPassword(3, roomID, "secret", now); !errors.Is(err, errJoinLocked) || !until.Equal(now.Add(time.Hour)) {
+		t.Fatalf("user with failures: until=%v err=%v", until, err)
+	}
+}
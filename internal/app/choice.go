@@ -0,0 +1,47 @@
+package app
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// choiceBadge — первая строка подписи у номинанта, за которого пользователь уже проголосовал.
+const choiceBadge = "✅ ТВОЙ ВЫБОР"
+
+// pluralityRow — кнопки под карточкой номинанта в номинации с выбором одного и подсказка к ним.
+// chosen — голос пользователя сейчас за этого номинанта, hasVote — голос отдан кому-то в номинации,
+// final — в комнате голос окончательный (allow_revote выключен). Пустой ряд — кнопок нет.
+func pluralityRow(nomineeID int64, chosen, hasVote, final bool) ([]tgbotapi.InlineKeyboardButton, string) {
+	voteData := fmt.Sprintf("vote:%d", nomineeID)
+	switch {
+	case chosen && final:
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔒 Твой голос", voteData),
+		), "Твой голос за этого номинанта — окончательный."
+	case chosen:
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Твой голос", voteData),
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Отозвать", fmt.Sprintf("unvote:%d", nomineeID)),
+		), "Твой голос сейчас за этого номинанта. Его можно отозвать."
+	case hasVote && final:
+		return nil, "Ты уже проголосовал(а) в этой номинации."
+	case hasVote:
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 Переголосовать", voteData),
+		), "Нажми, чтобы отдать голос этому номинанту вместо нынешнего."
+	default:
+		return voteRow(nomineeID), "Нажми кнопку, чтобы отдать голос."
+	}
+}
+
+// nomineeCaption — подпись карточки номинанта; выбранный пользователем номинант помечен сверху.
+func nomineeCaption(n domain.Nominee, hint string, chosen bool) string {
+	caption := fmt.Sprintf("ID %d — %s\n\n%s", n.ID, n.Name, hint)
+	if chosen {
+		caption = choiceBadge + "\n" + caption
+	}
+	return caption
+}
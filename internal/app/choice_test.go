@@ -0,0 +1,64 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestPluralityRow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                   string
+		chosen, hasVote, final bool
+		wantLabels             []string
+		wantData               []string
+	}{
+		{name: "no vote yet", wantLabels: []string{"✅ Голосовать"}, wantData: []string{"vote:7"}},
+		{name: "chosen", chosen: true, hasVote: true,
+			wantLabels: []string{"✅ Твой голос", "↩️ Отозвать"}, wantData: []string{"vote:7", "unvote:7"}},
+		{name: "chosen and final", chosen: true, hasVote: true, final: true,
+			wantLabels: []string{"🔒 Твой голос"}, wantData: []string{"vote:7"}},
+		{name: "voted for another", hasVote: true,
+			wantLabels: []string{"🔁 Переголосовать"}, wantData: []string{"vote:7"}},
+		{name: "voted for another, final", hasVote: true, final: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			row, hint := pluralityRow(7, tt.chosen, tt.hasVote, tt.final)
+			if hint == "" {
+				t.Fatalf("hint must not be empty")
+			}
+			if len(row) != len(tt.wantLabels) {
+				t.Fatalf("got %d buttons, want %d", len(row), len(tt.wantLabels))
+			}
+			for i, b := range row {
+				if b.Text != tt.wantLabels[i] || *b.CallbackData != tt.wantData[i] {
+					t.Fatalf("button %d = %q (%s), want %q (%s)", i, b.Text, *b.CallbackData, tt.wantLabels[i], tt.wantData[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNomineeCaption(t *testing.T) {
+	t.Parallel()
+
+	n := domain.Nominee{ID: 3, Name: "Вася"}
+	plain := nomineeCaption(n, "подсказка", false)
+	if plain != "ID 3 — Вася\n\nподсказка" {
+		t.Fatalf("unexpected caption: %q", plain)
+	}
+	if strings.Contains(plain, choiceBadge) {
+		t.Fatalf("badge on a nominee that was not chosen")
+	}
+	if got := nomineeCaption(n, "подсказка", true); !strings.HasPrefix(got, choiceBadge+"\n") {
+		t.Fatalf("chosen nominee must start with the badge: %q", got)
+	}
+}
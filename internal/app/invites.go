@@ -0,0 +1,312 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+const (
+	// defaultInviteTTL — срок жизни ссылки, созданной кнопкой из /my_rooms.
+	defaultInviteTTL = 7 * 24 * time.Hour
+	// maxInvitesShown — сколько приглашений влезает в одно сообщение со списком.
+	maxInvitesShown = 20
+)
+
+// newInviteToken — 128 бит случайности в base64url: влезает в payload /start (A-Za-z0-9_-, до 64 символов).
+func newInviteToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (a *App) inviteLink(token string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", a.bot.Self.UserName, token)
+}
+
+func describeInvite(inv domain.Invite, now time.Time) string {
+	var parts []string
+	if inv.MaxUses > 0 {
+		parts = append(parts, fmt.Sprintf("входов: %d/%d", inv.Uses, inv.MaxUses))
+	} else {
+		parts = append(parts, fmt.Sprintf("входов: %d", inv.Uses))
+	}
+	if inv.ExpiresAt.IsZero() {
+		parts = append(parts, "бессрочно")
+	} else {
+		parts = append(parts, "до "+inv.ExpiresAt.UTC().Format("2006-01-02 15:04")+" UTC")
+	}
+	if inv.Role != domain.RoleNone {
+		parts = append(parts, "роль: "+inv.Role.Title())
+	}
+	if inv.Group == domain.GroupJury {
+		parts = append(parts, "⚖️ жюри")
+	}
+	if !inv.Usable(now) {
+		parts = append(parts, "неактивно")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ---------- Команды ----------
+
+// handleInviteCommand обслуживает /invite (зрители) и /jury_invite (жюри) — отличается только группа.
+func (a *App) handleInviteCommand(msg *tgbotapi.Message, group domain.VoterGroup) {
+	args := strings.Fields(strings.TrimSpace(msg.CommandArguments()))
+	if len(args) == 0 {
+		cmd := "/" + msg.Command()
+		text := "Формат: " + cmd + " roomID [часы] [макс_входов]\n\n" +
+			"часы — сколько действует ссылка (0 — бессрочно, по умолчанию 168 = неделя)\n" +
+			"макс_входов — сколько раз по ней можно войти (0 — без ограничения)\n\n" +
+			"Пример:\n" + cmd + " 1 48 20"
+		if group == domain.GroupJury {
+			text += "\n\nГолоса вошедших по этой ссылке считаются голосами жюри."
+		}
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	roomID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "roomID должно быть числом."))
+		return
+	}
+
+	ttl := defaultInviteTTL
+	if len(args) >= 2 {
+		hours, err := strconv.Atoi(args[1])
+		if err != nil || hours < 0 {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Часы должны быть неотрицательным числом."))
+			return
+		}
+		ttl = time.Duration(hours) * time.Hour
+	}
+
+	maxUses := 0
+	if len(args) >= 3 {
+		maxUses, err = strconv.Atoi(args[2])
+		if err != nil || maxUses < 0 {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "макс_входов должно быть неотрицательным числом."))
+			return
+		}
+	}
+
+	a.createInvite(msg.Chat.ID, msg.From.ID, roomID, ttl, maxUses, group)
+}
+
+func (a *App) handleInvitesCommand(msg *tgbotapi.Message) {
+	args := strings.TrimSpace(msg.CommandArguments())
+	roomID, err := strconv.ParseInt(args, 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Формат: /invites roomID"))
+		return
+	}
+	a.sendInvitesList(msg.Chat.ID, msg.From.ID, roomID)
+}
+
+// handleStartInvite — вход в комнату по ссылке t.me/<bot>?start=<token>.
+func (a *App) handleStartInvite(msg *tgbotapi.Message, sess *session.Session, token string) {
+	room, granted, group, err := a.store.RedeemInvite(token, msg.From.ID, a.hashUserID(msg.From.ID), displayName(msg.From), time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Приглашение недействительно: оно отозвано, истекло или закончились входы.\n"+
+				"Попроси у автора комнаты новую ссылку или зайди через /room ID Пароль."))
+		} else {
+			log.Println("RedeemInvite:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка при входе в комнату."))
+		}
+		return
+	}
+
+	a.enterRoom(msg.Chat.ID, msg.From.ID, sess, room)
+
+	if group == domain.GroupJury {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "⚖️ Ты в жюри этой комнаты: твои голоса считаются отдельно от зрительских."))
+	}
+
+	if granted != domain.RoleNone {
+		// автор, открывший свою же ссылку, автором и остаётся
+		role, err := a.store.RoomRole(room.ID, msg.From.ID)
+		if err != nil {
+			log.Println("RoomRole(start invite):", err)
+			return
+		}
+		text := fmt.Sprintf("Твоя роль в комнате «%s»: %s.\nСвои комнаты и кнопки управления — в /my_rooms.", room.Title, role.Title())
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+	}
+}
+
+// ---------- Кнопки ----------
+
+func (a *App) handleInviteCallback(cq *tgbotapi.CallbackQuery, data string) {
+	chatID := cq.Message.Chat.ID
+
+	switch {
+	case strings.HasPrefix(data, "inv_new:"):
+		roomID, err := strconv.ParseInt(strings.TrimPrefix(data, "inv_new:"), 10, 64)
+		if err != nil {
+			return
+		}
+		a.createInvite(chatID, cq.From.ID, roomID, defaultInviteTTL, 0, domain.GroupAudience)
+
+	case strings.HasPrefix(data, "inv_list:"):
+		roomID, err := strconv.ParseInt(strings.TrimPrefix(data, "inv_list:"), 10, 64)
+		if err != nil {
+			return
+		}
+		a.sendInvitesList(chatID, cq.From.ID, roomID)
+
+	case strings.HasPrefix(data, "inv_revoke:"):
+		token := strings.TrimPrefix(data, "inv_revoke:")
+		inv, err := a.store.GetInvite(token)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				a.send(tgbotapi.NewMessage(chatID, "Приглашение не найдено."))
+			} else {
+				log.Println("GetInvite(inv_revoke):", err)
+			}
+			return
+		}
+
+		role, err := a.store.RoomRole(inv.RoomID, cq.From.ID)
+		if err != nil {
+			log.Println("RoomRole(inv_revoke):", err)
+			a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+			return
+		}
+		if !role.CanManage() {
+			a.send(tgbotapi.NewMessage(chatID, "Отзывать приглашения могут только автор или админы комнаты."))
+			return
+		}
+
+		revoked, err := a.store.RevokeInvite(token, time.Now())
+		if err != nil {
+			log.Println("RevokeInvite:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Не удалось отозвать приглашение."))
+			return
+		}
+		if !revoked {
+			a.send(tgbotapi.NewMessage(chatID, "Это приглашение уже отозвано."))
+			return
+		}
+		a.send(tgbotapi.NewMessage(chatID, "Приглашение отозвано ✅ Войти по этой ссылке больше нельзя."))
+	}
+}
+
+// ---------- Утилиты ----------
+
+func (a *App) createInvite(chatID, userID, roomID int64, ttl time.Duration, maxUses int, group domain.VoterGroup) {
+	role, err := a.store.RoomRole(roomID, userID)
+	if err != nil {
+		log.Println("RoomRole(invite):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(chatID, "Создавать приглашения могут только автор или админы комнаты."))
+		return
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		log.Println("newInviteToken:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось создать приглашение."))
+		return
+	}
+
+	now := time.Now()
+	inv := domain.Invite{
+		Token:     token,
+		RoomID:    roomID,
+		CreatedBy: userID,
+		MaxUses:   maxUses,
+		CreatedAt: now,
+		Group:     group,
+	}
+	if ttl > 0 {
+		inv.ExpiresAt = now.Add(ttl)
+	}
+
+	if err := a.store.CreateInvite(inv); err != nil {
+		log.Println("CreateInvite:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось создать приглашение."))
+		return
+	}
+
+	text := fmt.Sprintf("Приглашение в комнату ID %d готово 🔗\n%s\n\n(%s)\n\n"+
+		"Кто откроет ссылку, сразу попадёт в комнату без пароля.",
+		roomID, a.inviteLink(token), describeInvite(inv, now))
+	if group == domain.GroupJury {
+		text += "\nГолоса вошедших по ней считаются голосами жюри."
+	}
+	m := tgbotapi.NewMessage(chatID, text)
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚫 Отозвать", "inv_revoke:"+token),
+		),
+	)
+	a.send(m)
+}
+
+func (a *App) sendInvitesList(chatID, userID, roomID int64) {
+	role, err := a.store.RoomRole(roomID, userID)
+	if err != nil {
+		log.Println("RoomRole(invites):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(chatID, "Смотреть приглашения могут только автор или админы комнаты."))
+		return
+	}
+
+	invites, err := a.store.ListInvites(roomID)
+	if err != nil {
+		log.Println("ListInvites:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить список приглашений."))
+		return
+	}
+
+	if len(invites) == 0 {
+		m := tgbotapi.NewMessage(chatID, fmt.Sprintf("У комнаты ID %d нет активных приглашений.", roomID))
+		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🔗 Создать приглашение", fmt.Sprintf("inv_new:%d", roomID)),
+			),
+		)
+		a.send(m)
+		return
+	}
+
+	now := time.Now()
+	var sb strings.Builder
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	fmt.Fprintf(&sb, "Приглашения в комнату ID %d:\n\n", roomID)
+	if len(invites) > maxInvitesShown {
+		invites = invites[:maxInvitesShown]
+		fmt.Fprintf(&sb, "(показаны последние %d)\n\n", maxInvitesShown)
+	}
+	for i, inv := range invites {
+		fmt.Fprintf(&sb, "%d) %s\n(%s)\n\n", i+1, a.inviteLink(inv.Token), describeInvite(inv, now))
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🚫 Отозвать %d", i+1), "inv_revoke:"+inv.Token),
+		))
+	}
+
+	m := tgbotapi.NewMessage(chatID, sb.String())
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons...)
+	a.send(m)
+}
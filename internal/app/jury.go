@@ -0,0 +1,186 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+// ---------- Команды ----------
+
+// handleJuryWeight — /jury_weight roomID проценты
+func (a *App) handleJuryWeight(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		text := "Формат: /jury_weight roomID проценты\n\n" +
+			"Сколько процентов общего зачёта приходится на жюри (0–100), остальное — на зрителей.\n" +
+			"Жюри приглашается ссылкой /jury_invite.\n\n" +
+			"Пример:\n/jury_weight 1 60"
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	roomID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "roomID должно быть числом."))
+		return
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(args[1], "%"))
+	if err != nil || percent < 0 || percent > 100 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Вес жюри — число от 0 до 100."))
+		return
+	}
+
+	role, err := a.store.RoomRole(roomID, msg.From.ID)
+	if err != nil {
+		log.Println("RoomRole(jury_weight):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Настраивать вес жюри могут только автор или админы комнаты."))
+		return
+	}
+
+	if err := a.store.SetJuryWeight(roomID, percent); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Комната не найдена."))
+		} else {
+			log.Println("SetJuryWeight:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить настройку."))
+		}
+		return
+	}
+
+	text := fmt.Sprintf("Готово ✅ В комнате ID %d общий зачёт: жюри %d%%, зрители %d%%.", roomID, percent, 100-percent)
+	a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+}
+
+// ---------- Итоги ----------
+
+// juryResults — итоги номинации с выбором, если в ней голосовало жюри: отдельно по группам
+// и общий зачёт с весами комнаты. ok=false — голосов жюри нет, итоги обычные.
+func (a *App) juryResults(nom *domain.Nomination, mode domain.ResultsMode) (body string, ok bool, err error) {
+	byGroup, err := a.store.ResultsByGroup(nom.ID)
+	if err != nil {
+		return "", false, err
+	}
+	if len(byGroup[domain.GroupJury]) == 0 {
+		return "", false, nil
+	}
+
+	room, err := a.store.GetRoom(nom.RoomID)
+	if err != nil {
+		return "", false, err
+	}
+	nominees, err := a.store.ListNominees(nom.ID)
+	if err != nil {
+		return "", false, err
+	}
+
+	candidates := make([]int64, 0, len(nominees))
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		candidates = append(candidates, n.ID)
+		names[n.ID] = n.Name
+	}
+	return formatJuryResults(candidates, names, byGroup, room.JuryWeight, mode), true, nil
+}
+
+// juryGroups — порядок групп в итогах.
+var juryGroups = []domain.VoterGroup{domain.GroupJury, domain.GroupAudience}
+
+func formatJuryResults(candidates []int64, names map[int64]string, byGroup map[domain.VoterGroup]map[int64]int, juryWeight int, mode domain.ResultsMode) string {
+	if len(candidates) == 0 {
+		return "В этой номинации пока нет номинантов.\n"
+	}
+
+	counts := make(map[string]map[int64]int, len(byGroup))
+	for g, c := range byGroup {
+		counts[string(g)] = c
+	}
+	weights := map[string]float64{
+		string(domain.GroupJury):     float64(juryWeight) / 100,
+		string(domain.GroupAudience): float64(100-juryWeight) / 100,
+	}
+	rows := tally.Weighted(candidates, counts, weights)
+
+	var sb strings.Builder
+	if mode == domain.ResultsWinners {
+		n := 1
+		for n < len(rows) && rows[n].Score == rows[0].Score {
+			n++
+		}
+		if n == 1 {
+			fmt.Fprintf(&sb, "🏆 Победитель общего зачёта: %s — %s\n", names[rows[0].ID], formatPercent(rows[0].Score))
+		} else {
+			fmt.Fprintf(&sb, "🏆 Победители общего зачёта (поровну, по %s):\n", formatPercent(rows[0].Score))
+			for _, r := range rows[:n] {
+				fmt.Fprintf(&sb, "• %s\n", names[r.ID])
+			}
+		}
+		fmt.Fprintf(&sb, "\nВеса: жюри %d%%, зрители %d%%\n", juryWeight, 100-juryWeight)
+		return sb.String()
+	}
+
+	for _, g := range juryGroups {
+		weight := juryWeight
+		if g == domain.GroupAudience {
+			weight = 100 - juryWeight
+		}
+		fmt.Fprintf(&sb, "%s %s (вес %d%%):\n", voterGroupIcon(g), capitalize(g.Title()), weight)
+		total := 0
+		for _, c := range candidates {
+			total += byGroup[g][c]
+		}
+		if total == 0 {
+			sb.WriteString("голосов не было\n\n")
+			continue
+		}
+		for _, c := range sortedByVotes(candidates, byGroup[g]) {
+			fmt.Fprintf(&sb, "• %s — %d голос(ов)\n", names[c], byGroup[g][c])
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("Общий зачёт:\n")
+	for i, r := range rows {
+		fmt.Fprintf(&sb, "%d. %s — %s\n", i+1, names[r.ID], formatPercent(r.Score))
+	}
+	return sb.String()
+}
+
+func voterGroupIcon(g domain.VoterGroup) string {
+	if g == domain.GroupJury {
+		return "⚖️"
+	}
+	return "👥"
+}
+
+func capitalize(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	return strings.ToUpper(string(r[:1])) + string(r[1:])
+}
+
+func formatPercent(share float64) string {
+	return strconv.FormatFloat(share*100, 'f', 1, 64) + "%"
+}
+
+// sortedByVotes — кандидаты по убыванию голосов, при равенстве — в исходном порядке.
+func sortedByVotes(candidates []int64, votes map[int64]int) []int64 {
+	out := append([]int64(nil), candidates...)
+	sort.SliceStable(out, func(i, j int) bool { return votes[out[i]] > votes[out[j]] })
+	return out
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestFormatJuryResults(t *testing.T) {
+	t.Parallel()
+
+	names := map[int64]string{1: "Alice", 2: "Bob"}
+	byGroup := map[domain.VoterGroup]map[int64]int{
+		domain.GroupJury:     {1: 3},
+		domain.GroupAudience: {1: 4, 2: 6},
+	}
+
+	full := formatJuryResults([]int64{1, 2}, names, byGroup, 50, domain.ResultsFull)
+	for _, want := range []string{
+		"⚖️ Жюри (вес 50%):\n• Alice — 3 голос(ов)",
+		"👥 Зрители (вес 50%):\n• Bob — 6 голос(ов)\n• Alice — 4 голос(ов)",
+		"Общий зачёт:\n1. Alice — 70.0%\n2. Bob — 30.0%",
+	} {
+		if !strings.Contains(full, want) {
+			t.Fatalf("full output missing %q:\n%s", want, full)
+		}
+	}
+
+	// весь вес у зрителей — побеждает Bob
+	win := formatJuryResults([]int64{1, 2}, names, byGroup, 0, domain.ResultsWinners)
+	if !strings.Contains(win, "Победитель общего зачёта: Bob — 60.0%") || strings.Contains(win, "Alice") {
+		t.Fatalf("winners output should show only Bob:\n%s", win)
+	}
+}
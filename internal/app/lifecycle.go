@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+// ---------- Команды ----------
+
+func (a *App) handleRoomStatusCommand(msg *tgbotapi.Message, to domain.RoomStatus) {
+	roomID, err := strconv.ParseInt(strings.TrimSpace(msg.CommandArguments()), 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Формат: /%s roomID", msg.Command())))
+		return
+	}
+	a.changeRoomStatus(msg.Chat.ID, msg.From.ID, roomID, to)
+}
+
+// ---------- Кнопки ----------
+
+// handleRoomStatusCallback — room_status:<roomID>:<status>
+func (a *App) handleRoomStatusCallback(cq *tgbotapi.CallbackQuery, data string) {
+	roomStr, status, ok := strings.Cut(strings.TrimPrefix(data, "room_status:"), ":")
+	if !ok {
+		return
+	}
+	roomID, err := strconv.ParseInt(roomStr, 10, 64)
+	if err != nil {
+		return
+	}
+	a.changeRoomStatus(cq.Message.Chat.ID, cq.From.ID, roomID, domain.RoomStatus(status))
+}
+
+// ---------- Утилиты ----------
+
+func (a *App) changeRoomStatus(chatID, userID, roomID int64, to domain.RoomStatus) {
+	role, err := a.store.RoomRole(roomID, userID)
+	if err != nil {
+		log.Println("RoomRole(room_status):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(chatID, "Менять статус комнаты могут только автор или админы комнаты."))
+		return
+	}
+
+	from, err := a.setRoomStatus(roomID, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			a.send(tgbotapi.NewMessage(chatID, "Комната не найдена."))
+		case errors.Is(err, storage.ErrInvalidTransition):
+			a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Сейчас так нельзя: статус комнаты — %s.", from.Title())))
+		default:
+			log.Println("setRoomStatus:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Не удалось сменить статус комнаты."))
+		}
+		return
+	}
+
+	m := tgbotapi.NewMessage(chatID, fmt.Sprintf("Статус комнаты ID %d: %s", roomID, to.Title()))
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+		),
+	)
+	a.send(m)
+}
+
+// setRoomStatus — переход без проверки прав; общий для кнопок, команд и планировщика.
+// Закрытие голосования запускает автоматическое переголосование при ничьей.
+func (a *App) setRoomStatus(roomID int64, to domain.RoomStatus) (domain.RoomStatus, error) {
+	from, err := a.store.TransitionRoomStatus(roomID, to)
+	if err == nil && from == domain.RoomOpen && to == domain.RoomClosed {
+		a.createRunoffs(roomID)
+	}
+	return from, err
+}
+
+// roomStatusButtons — кнопки смены статуса для организаторов в списке номинаций.
+func roomStatusButtons(roomID int64, status domain.RoomStatus) []tgbotapi.InlineKeyboardButton {
+	data := func(to domain.RoomStatus) string { return fmt.Sprintf("room_status:%d:%s", roomID, to) }
+
+	switch status {
+	case domain.RoomDraft:
+		return tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("▶️ Открыть голосование", data(domain.RoomOpen)))
+	case domain.RoomOpen:
+		return tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("⏹ Закрыть голосование", data(domain.RoomClosed)))
+	case domain.RoomClosed:
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("▶️ Открыть заново", data(domain.RoomOpen)),
+			tgbotapi.NewInlineKeyboardButtonData("📢 Итоги для участников", fmt.Sprintf("pub_screen:%d", roomID)),
+		)
+	case domain.RoomPublished:
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📢 Итоги для участников", fmt.Sprintf("pub_screen:%d", roomID)),
+			tgbotapi.NewInlineKeyboardButtonData("🙈 Скрыть итоги", data(domain.RoomClosed)),
+		)
+	default:
+		return nil
+	}
+}
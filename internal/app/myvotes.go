@@ -0,0 +1,207 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+)
+
+// myVote — номинация и выбор пользователя в ней; пустой Choice — ещё не голосовал(а).
+type myVote struct {
+	Nomination domain.Nomination
+	Choice     string
+}
+
+// ---------- Команды ----------
+
+// handleMyVotes — /my_votes: за кого пользователь голосовал в каждой номинации активной комнаты.
+func (a *App) handleMyVotes(msg *tgbotapi.Message, sess *session.Session) {
+	if sess.ActiveRoomID == 0 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Сначала зайди в комнату: /room ID Пароль"))
+		return
+	}
+
+	votes, err := a.myVotes(msg.From.ID, sess.ActiveRoomID)
+	if err != nil {
+		log.Println("myVotes:", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось получить твои голоса 😔"))
+		return
+	}
+	if len(votes) == 0 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "В этой комнате пока нет номинаций."))
+		return
+	}
+
+	title := ""
+	if room, err := a.store.GetRoom(sess.ActiveRoomID); err != nil {
+		log.Println("GetRoom(my_votes):", err)
+	} else {
+		title = room.Title
+	}
+
+	m := tgbotapi.NewMessage(msg.Chat.ID, formatMyVotes(title, votes))
+	m.ReplyMarkup = myVotesKeyboard(votes)
+	a.send(m)
+}
+
+// ---------- Утилиты ----------
+
+// myVotes — выбор пользователя во всех номинациях комнаты, в порядке списка номинаций.
+func (a *App) myVotes(userID, roomID int64) ([]myVote, error) {
+	nominations, err := a.store.ListNominations(roomID)
+	if err != nil {
+		return nil, err
+	}
+	userHash := a.hashUserID(userID)
+	votes := make([]myVote, 0, len(nominations))
+	for _, nom := range nominations {
+		choice, err := a.myChoice(userHash, nom)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, myVote{Nomination: nom, Choice: choice})
+	}
+	return votes, nil
+}
+
+// myChoice — голос пользователя в номинации одной строкой, как его видно в режиме номинации.
+func (a *App) myChoice(userHash string, nom domain.Nomination) (string, error) {
+	nominees, err := a.store.ListNominees(nom.ID)
+	if err != nil {
+		return "", err
+	}
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		names[n.ID] = n.Name
+	}
+
+	switch nom.Kind {
+	case domain.KindRanked:
+		order, err := a.store.UserRanking(userHash, nom.ID)
+		if err != nil || len(order) == 0 {
+			return "", err
+		}
+		places := make([]string, 0, len(order))
+		for i, id := range order {
+			places = append(places, fmt.Sprintf("%d. %s", i+1, nomineeName(names, id)))
+		}
+		return strings.Join(places, ", "), nil
+
+	case domain.KindScore:
+		scores, err := a.store.UserScores(userHash, nom.ID)
+		if err != nil {
+			return "", err
+		}
+		var parts []string
+		for _, n := range nominees {
+			if score := scores[n.ID]; score > 0 {
+				parts = append(parts, fmt.Sprintf("%s — %s", n.Name, strings.Repeat("⭐", score)))
+			}
+		}
+		return strings.Join(parts, ", "), nil
+
+	case domain.KindBracket:
+		picks, err := a.store.UserMatchVotes(userHash, nom.ID)
+		if err != nil || len(picks) == 0 {
+			return "", err
+		}
+		parts := make([]string, 0, len(picks))
+		for _, id := range picks {
+			parts = append(parts, nomineeName(names, id))
+		}
+		return "в матчах: " + strings.Join(parts, ", "), nil
+
+	case domain.KindPairwise:
+		done, err := a.store.UserComparisonCount(userHash, nom.ID)
+		if err != nil || done == 0 {
+			return "", err
+		}
+		return fmt.Sprintf("сравнений сделано: %d", done), nil
+
+	case domain.KindReferendum:
+		choice, err := a.store.UserReferendumVote(userHash, nom.ID)
+		if err != nil || choice == "" {
+			return "", err
+		}
+		return capitalize(choice.Title()), nil
+	}
+
+	voted, err := a.store.UserNomineeVotes(userHash, nom.ID)
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, n := range nominees {
+		if voted[n.ID] {
+			parts = append(parts, n.Name)
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// frozenNominations — номинации, у которых уже есть следующий тур: голосовать в них нельзя.
+func frozenNominations(votes []myVote) map[int64]bool {
+	frozen := make(map[int64]bool)
+	for _, v := range votes {
+		if v.Nomination.ParentID != 0 {
+			frozen[v.Nomination.ParentID] = true
+		}
+	}
+	return frozen
+}
+
+func formatMyVotes(roomTitle string, votes []myVote) string {
+	frozen := frozenNominations(votes)
+	done := 0
+	for _, v := range votes {
+		if v.Choice != "" {
+			done++
+		}
+	}
+
+	var sb strings.Builder
+	if roomTitle != "" {
+		fmt.Fprintf(&sb, "Твои голоса в комнате «%s»\n", roomTitle)
+	} else {
+		sb.WriteString("Твои голоса в комнате\n")
+	}
+	fmt.Fprintf(&sb, "Проголосовано: %d из %d\n\n", done, len(votes))
+
+	for _, v := range votes {
+		switch {
+		case v.Choice != "":
+			fmt.Fprintf(&sb, "✅ %s — %s\n", v.Nomination.Name, v.Choice)
+		case frozen[v.Nomination.ID]:
+			fmt.Fprintf(&sb, "▫️ %s — не голосовал(а), тур завершён\n", v.Nomination.Name)
+		default:
+			fmt.Fprintf(&sb, "▫️ %s — не голосовал(а)\n", v.Nomination.Name)
+		}
+	}
+	if done == len(votes) {
+		sb.WriteString("\nТы проголосовал(а) во всех номинациях 🎉")
+	}
+	return sb.String()
+}
+
+// myVotesKeyboard — переход к номинациям, где голоса ещё нет, и «назад».
+func myVotesKeyboard(votes []myVote) tgbotapi.InlineKeyboardMarkup {
+	frozen := frozenNominations(votes)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, v := range votes {
+		if v.Choice != "" || frozen[v.Nomination.ID] {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗳 "+v.Nomination.Name, fmt.Sprintf("nomination:%d", v.Nomination.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestFormatMyVotes(t *testing.T) {
+	t.Parallel()
+
+	votes := []myVote{
+		{Nomination: domain.Nomination{ID: 1, Name: "Лучший код"}, Choice: "Вася"},
+		{Nomination: domain.Nomination{ID: 2, Name: "Лучший дизайн"}},
+		{Nomination: domain.Nomination{ID: 3, Name: "Финал", ParentID: 2}},
+	}
+
+	got := formatMyVotes("Офис", votes)
+	for _, want := range []string{
+		"«Офис»",
+		"Проголосовано: 1 из 3",
+		"✅ Лучший код — Вася",
+		"▫️ Лучший дизайн — не голосовал(а), тур завершён",
+		"▫️ Финал — не голосовал(а)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "🎉") {
+		t.Fatalf("not everything is voted:\n%s", got)
+	}
+
+	all := formatMyVotes("", votes[:1])
+	if !strings.Contains(all, "во всех номинациях") {
+		t.Fatalf("expected the all-done line:\n%s", all)
+	}
+}
+
+func TestMyVotesKeyboard(t *testing.T) {
+	t.Parallel()
+
+	votes := []myVote{
+		{Nomination: domain.Nomination{ID: 1, Name: "A"}, Choice: "Вася"},
+		{Nomination: domain.Nomination{ID: 2, Name: "B"}},
+		{Nomination: domain.Nomination{ID: 3, Name: "C", ParentID: 2}},
+		{Nomination: domain.Nomination{ID: 4, Name: "D"}},
+	}
+
+	kb := myVotesKeyboard(votes)
+	var got []string
+	for _, row := range kb.InlineKeyboard {
+		got = append(got, *row[0].CallbackData)
+	}
+	// проголосованная и замороженная номинации без кнопок, «назад» последней
+	want := []string{"nomination:3", "nomination:4", "back:nominations"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("callbacks = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,233 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+// ---------- Кнопки ----------
+
+// handlePairCallback — pair:<nominationID>:<winnerID>:<loserID>
+func (a *App) handlePairCallback(cq *tgbotapi.CallbackQuery, sess *session.Session, data string) {
+	chatID := cq.Message.Chat.ID
+
+	parts := strings.Split(strings.TrimPrefix(data, "pair:"), ":")
+	if len(parts) != 3 {
+		return
+	}
+	var ids [3]int64
+	for i, p := range parts {
+		id, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return
+		}
+		ids[i] = id
+	}
+	nominationID, winnerID, loserID := ids[0], ids[1], ids[2]
+
+	nom, err := a.store.GetNomination(nominationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(chatID, "Эта номинация больше не существует."))
+		} else {
+			log.Println("get nomination(pair):", err)
+		}
+		return
+	}
+	if sess.ActiveRoomID != nom.RoomID {
+		a.send(tgbotapi.NewMessage(chatID, "У тебя нет доступа к этой комнате. Сначала зайди в неё командой /room."))
+		return
+	}
+	if nom.Kind != domain.KindPairwise {
+		a.send(tgbotapi.NewMessage(chatID, "В этой номинации больше не сравнивают пары — открой её заново."))
+		return
+	}
+
+	if err := a.store.RecordComparison(a.hashUserID(cq.From.ID), nominationID, winnerID, loserID, time.Now()); err != nil {
+		if reason := a.voteRejectedReason(nom.RoomID, err); reason != "" {
+			a.send(tgbotapi.NewMessage(chatID, "Выбор не принят: "+reason+"."))
+			return
+		}
+		if errors.Is(err, storage.ErrNomineeMismatch) {
+			a.send(tgbotapi.NewMessage(chatID, "Кого-то из этой пары уже удалили — открой номинацию заново."))
+			return
+		}
+		if errors.Is(err, storage.ErrVoteLocked) {
+			a.send(tgbotapi.NewMessage(chatID, "В этой комнате голос окончательный — в этой паре ты уже выбрал(а) другого."))
+			a.sendPair(chatID, cq.From.ID, nom)
+			return
+		}
+		log.Println("RecordComparison:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		return
+	}
+
+	names, err := a.nomineeNames(nominationID)
+	if err != nil {
+		log.Println("nomineeNames(pair):", err)
+	}
+	// убираем кнопки у отвеченной пары, чтобы её нельзя было засчитать дважды
+	a.send(tgbotapi.NewEditMessageText(chatID, cq.Message.MessageID,
+		fmt.Sprintf("%s 🆚 %s — выбран %s ✅", nomineeName(names, winnerID), nomineeName(names, loserID), nomineeName(names, winnerID))))
+
+	a.sendPair(chatID, cq.From.ID, nom)
+}
+
+// ---------- Утилиты ----------
+
+// sendPair — следующая случайная пара номинантов: две карточки и сообщение с кнопками выбора.
+func (a *App) sendPair(chatID, userID int64, nom *domain.Nomination) {
+	nominees, err := a.store.ListNominees(nom.ID)
+	if err != nil {
+		log.Println("ListNominees(pair):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить список номинантов 😔"))
+		return
+	}
+	if len(nominees) < 2 {
+		m := tgbotapi.NewMessage(chatID, "Для сравнения нужно хотя бы два номинанта.")
+		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+			),
+		)
+		a.send(m)
+		return
+	}
+
+	compared, err := a.store.UserComparedPairs(a.hashUserID(userID), nom.ID)
+	if err != nil {
+		log.Println("UserComparedPairs:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		return
+	}
+	left, right, ok := pickPair(nominees, compared, rand.Intn)
+	if !ok {
+		m := tgbotapi.NewMessage(chatID, fmt.Sprintf("Ты сравнил(а) все пары (%d) 🎉 Спасибо! Итог появится в результатах.", len(compared)))
+		m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+			),
+		)
+		a.send(m)
+		return
+	}
+
+	pair := [2]domain.Nominee{left, right}
+	for i, n := range pair {
+		a.sendNomineeCard(chatID, n, fmt.Sprintf("%s %s", []string{"🔴", "🔵"}[i], n.Name), nil)
+	}
+
+	total := len(nominees) * (len(nominees) - 1) / 2
+	m := tgbotapi.NewMessage(chatID, fmt.Sprintf("Кто лучше: %s или %s?\nСравнено пар: %d из %d. Остановиться можно в любой момент.",
+		pair[0].Name, pair[1].Name, len(compared), total))
+	m.ReplyMarkup = pairKeyboard(nom.ID, pair[0], pair[1])
+	a.send(m)
+}
+
+// pickPair выбирает случайную пару номинантов, которую пользователь ещё не сравнивал;
+// compared — сравнённые пары в виде {меньший ID, больший ID}. ok == false — сравнены все пары.
+// intn — источник случайности (rand.Intn): и для выбора пары, и для того, кто будет слева.
+func pickPair(nominees []domain.Nominee, compared map[[2]int64]bool, intn func(int) int) (left, right domain.Nominee, ok bool) {
+	var open [][2]domain.Nominee
+	for i := range nominees {
+		for j := i + 1; j < len(nominees); j++ {
+			a, b := nominees[i], nominees[j]
+			if !compared[[2]int64{min(a.ID, b.ID), max(a.ID, b.ID)}] {
+				open = append(open, [2]domain.Nominee{a, b})
+			}
+		}
+	}
+	if len(open) == 0 {
+		return domain.Nominee{}, domain.Nominee{}, false
+	}
+	pair := open[intn(len(open))]
+	if intn(2) == 1 {
+		pair[0], pair[1] = pair[1], pair[0]
+	}
+	return pair[0], pair[1], true
+}
+
+// pairKeyboard — выбор одного из пары и кнопка «закончить».
+func pairKeyboard(nominationID int64, left, right domain.Nominee) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔴 "+left.Name, fmt.Sprintf("pair:%d:%d:%d", nominationID, left.ID, right.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🔵 "+right.Name, fmt.Sprintf("pair:%d:%d:%d", nominationID, right.ID, left.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏹ Закончить", "back:nominations"),
+		),
+	)
+}
+
+// ---------- Итоги ----------
+
+// pairwiseResults — рейтинг номинантов по всем попарным сравнениям.
+func (a *App) pairwiseResults(nominationID int64, mode domain.ResultsMode) (string, error) {
+	nominees, err := a.store.ListNominees(nominationID)
+	if err != nil {
+		return "", err
+	}
+	comparisons, err := a.store.NominationComparisons(nominationID)
+	if err != nil {
+		return "", err
+	}
+	if len(nominees) == 0 {
+		return "В этой номинации пока нет номинантов.\n", nil
+	}
+
+	candidates := make([]int64, 0, len(nominees))
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		candidates = append(candidates, n.ID)
+		names[n.ID] = n.Name
+	}
+	return formatPairwise(tally.BradleyTerry(candidates, comparisons), names, len(comparisons), mode), nil
+}
+
+func formatPairwise(rows []tally.PairwiseRow, names map[int64]string, total int, mode domain.ResultsMode) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Попарные сравнения (всего %d): рейтинг Брэдли–Терри по шкале Эло, 1500 — средний\n\n", total)
+
+	if mode == domain.ResultsWinners {
+		if total == 0 {
+			sb.WriteString("Сравнений не было.\n")
+			return sb.String()
+		}
+		var top []string
+		for _, r := range rows {
+			if math.Round(r.Rating) != math.Round(rows[0].Rating) {
+				break
+			}
+			top = append(top, names[r.ID])
+		}
+		if len(top) == 1 {
+			fmt.Fprintf(&sb, "🏆 Победитель: %s — рейтинг %.0f\n", top[0], rows[0].Rating)
+		} else {
+			fmt.Fprintf(&sb, "🏆 Победители (поровну, рейтинг %.0f): %s\n", rows[0].Rating, strings.Join(top, ", "))
+		}
+		return sb.String()
+	}
+
+	for _, r := range rows {
+		if r.Wins+r.Losses == 0 {
+			fmt.Fprintf(&sb, "• %s (ID %d) — не сравнивали\n", names[r.ID], r.ID)
+			continue
+		}
+		fmt.Fprintf(&sb, "• %s (ID %d) — %.0f (побед: %d, поражений: %d)\n", names[r.ID], r.ID, r.Rating, r.Wins, r.Losses)
+	}
+	return sb.String()
+}
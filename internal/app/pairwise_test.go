@@ -0,0 +1,84 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestPickPair(t *testing.T) {
+	t.Parallel()
+
+	nominees := []domain.Nominee{{ID: 1, Name: "A"}, {ID: 2, Name: "B"}, {ID: 3, Name: "C"}}
+
+	// все упорядоченные пары достижимы
+	seen := make(map[[2]int64]bool)
+	for pick := 0; pick < 3; pick++ {
+		for side := 0; side < 2; side++ {
+			draws := []int{pick, side}
+			left, right, ok := pickPair(nominees, nil, func(int) int {
+				v := draws[0]
+				draws = draws[1:]
+				return v
+			})
+			if !ok || left.ID == right.ID {
+				t.Fatalf("bad pair %d, %d ok=%v", left.ID, right.ID, ok)
+			}
+			seen[[2]int64{left.ID, right.ID}] = true
+		}
+	}
+	if len(seen) != 6 {
+		t.Fatalf("only %d ordered pairs reachable: %v", len(seen), seen)
+	}
+
+	// сравнённые пары больше не предлагаются
+	compared := map[[2]int64]bool{{1, 2}: true, {1, 3}: true}
+	for draw := 0; draw < 2; draw++ {
+		left, right, ok := pickPair(nominees, compared, func(n int) int { return min(draw, n-1) })
+		if !ok || min(left.ID, right.ID) != 2 || max(left.ID, right.ID) != 3 {
+			t.Fatalf("expected the only uncompared pair 2–3, got %d, %d ok=%v", left.ID, right.ID, ok)
+		}
+	}
+
+	compared[[2]int64{2, 3}] = true
+	if _, _, ok := pickPair(nominees, compared, func(int) int { return 0 }); ok {
+		t.Fatalf("all pairs compared: expected ok=false")
+	}
+}
+
+func TestPairKeyboard(t *testing.T) {
+	t.Parallel()
+
+	kb := pairKeyboard(9, domain.Nominee{ID: 1, Name: "A"}, domain.Nominee{ID: 2, Name: "B"})
+	choice := kb.InlineKeyboard[0]
+	if *choice[0].CallbackData != "pair:9:1:2" || *choice[1].CallbackData != "pair:9:2:1" {
+		t.Fatalf("unexpected callbacks: %q, %q", *choice[0].CallbackData, *choice[1].CallbackData)
+	}
+	if *kb.InlineKeyboard[1][0].CallbackData != "back:nominations" {
+		t.Fatalf("stop button must return to the nominations list")
+	}
+}
+
+func TestFormatPairwise(t *testing.T) {
+	t.Parallel()
+
+	names := map[int64]string{1: "A", 2: "B", 3: "C"}
+	rows := tally.BradleyTerry([]int64{1, 2, 3}, []tally.Comparison{{Winner: 1, Loser: 2}, {Winner: 1, Loser: 2}})
+
+	full := formatPairwise(rows, names, 2, domain.ResultsFull)
+	for _, want := range []string{"всего 2", "A (ID 1) — ", "побед: 2, поражений: 0", "C (ID 3) — не сравнивали"} {
+		if !strings.Contains(full, want) {
+			t.Fatalf("missing %q in:\n%s", want, full)
+		}
+	}
+
+	if got := formatPairwise(rows, names, 2, domain.ResultsWinners); !strings.Contains(got, "🏆 Победитель: A") {
+		t.Fatalf("unexpected winners:\n%s", got)
+	}
+	empty := tally.BradleyTerry([]int64{1, 2}, nil)
+	if got := formatPairwise(empty, names, 0, domain.ResultsWinners); !strings.Contains(got, "Сравнений не было") {
+		t.Fatalf("unexpected winners without comparisons:\n%s", got)
+	}
+}
@@ -0,0 +1,312 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+// ---------- Команды ----------
+
+// handlePromote — /promote nominationID N [| Название финала]
+func (a *App) handlePromote(msg *tgbotapi.Message) {
+	head, title, _ := strings.Cut(msg.CommandArguments(), "|")
+	args := strings.Fields(head)
+	if len(args) != 2 {
+		text := "Формат: /promote nominationID N [| Название финала]\n\n" +
+			"Создаёт номинацию следующего тура из N лучших номинантов (с фото/видео). " +
+			"При ничьей на границе проходят все, кто её поделил; номинанты без голосов не проходят.\n" +
+			"Голоса в исходной номинации после этого замораживаются.\n\n" +
+			"Пример:\n/promote 3 5 | Финал"
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	nominationID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "nominationID должно быть числом."))
+		return
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 1 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "N должно быть положительным числом."))
+		return
+	}
+
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
+	if err != nil {
+		log.Println("NominationRole(promote):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Переводить номинантов в следующий тур могут только автор или админы комнаты."))
+		return
+	}
+
+	nom, err := a.store.GetNomination(nominationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Номинация не найдена."))
+		} else {
+			log.Println("GetNomination(promote):", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка при получении номинации."))
+		}
+		return
+	}
+
+	status, err := a.store.GetRoomStatus(nom.RoomID)
+	if err != nil {
+		log.Println("GetRoomStatus(promote):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка при получении статуса комнаты."))
+		return
+	}
+	if status != domain.RoomClosed && status != domain.RoomPublished {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Переводить в следующий тур можно после закрытия голосования, а сейчас статус комнаты — %s.", status.Title())))
+		return
+	}
+
+	next, err := a.store.HasNextRound(nominationID)
+	if err != nil {
+		log.Println("HasNextRound(promote):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка при получении номинации."))
+		return
+	}
+	if next {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "У этой номинации уже есть следующий тур — история туров видна в /results."))
+		return
+	}
+
+	rows, err := a.standings(nom)
+	if err != nil {
+		log.Println("standings(promote):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось получить результаты."))
+		return
+	}
+	top := topN(rows, n)
+	if len(top) == 0 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "В номинации нет голосов — переводить в следующий тур некого."))
+		return
+	}
+
+	name := strings.TrimSpace(title)
+	if name == "" {
+		name = nom.Name + " — финал"
+	}
+	ids := make([]int64, 0, len(top))
+	names := make([]string, 0, len(top))
+	for _, r := range top {
+		ids = append(ids, r.ID)
+		names = append(names, r.Name)
+	}
+	description := fmt.Sprintf("Следующий тур номинации «%s»: топ-%d.", nom.Name, n)
+
+	finalID, err := a.store.CreateNextRound(nom.ID, name, description, ids)
+	if err != nil {
+		log.Println("CreateNextRound(promote):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось создать следующий тур."))
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Готово ✅ Номинация «%s» (ID %d) создана, в неё прошли:\n", name, finalID)
+	for _, nm := range names {
+		fmt.Fprintf(&sb, "• %s\n", nm)
+	}
+	if len(top) > n {
+		fmt.Fprintf(&sb, "\n(прошло %d вместо %d — ничья на границе)\n", len(top), n)
+	}
+	sb.WriteString("\nГолоса в исходной номинации заморожены. Способ голосования в новом туре можно сменить через /nomination_mode. ")
+	if status == domain.RoomPublished {
+		sb.WriteString("Чтобы участники проголосовали, сними публикацию (/close_voting) и открой голосование заново.")
+	} else {
+		sb.WriteString("Чтобы участники проголосовали, открой голосование заново.")
+	}
+
+	m := tgbotapi.NewMessage(msg.Chat.ID, sb.String())
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+		),
+	)
+	a.send(m)
+}
+
+// ---------- Места в итогах ----------
+
+// standing — номинант и его показатель в итогах номинации; больше Score — выше место.
+// Score сравним только внутри одной номинации: голоса, доля, средняя оценка или очки.
+type standing struct {
+	ID    int64
+	Name  string
+	Score float64
+}
+
+// standings — номинанты номинации по местам, как в /results.
+func (a *App) standings(nom *domain.Nomination) ([]standing, error) {
+	nominees, err := a.store.ListNominees(nom.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]int64, 0, len(nominees))
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		candidates = append(candidates, n.ID)
+		names[n.ID] = n.Name
+	}
+
+	scores := make(map[int64]float64, len(candidates))
+	switch nom.Kind {
+	case domain.KindRanked:
+		ballots, err := a.store.RankedBallots(nom.ID)
+		if err != nil {
+			return nil, err
+		}
+		if nom.TallyMethod == domain.TallyBorda {
+			for _, r := range tally.Borda(candidates, ballots) {
+				scores[r.ID] = float64(r.Points)
+			}
+		} else {
+			scores = irvScores(tally.IRV(candidates, ballots), len(ballots))
+		}
+
+	case domain.KindBracket:
+		matches, err := a.store.BracketMatches(nom.ID)
+		if err != nil {
+			return nil, err
+		}
+		scores = bracketScores(matches)
+
+	case domain.KindPairwise:
+		comparisons, err := a.store.NominationComparisons(nom.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range tally.BradleyTerry(candidates, comparisons) {
+			if r.Wins+r.Losses > 0 {
+				scores[r.ID] = r.Rating
+			}
+		}
+
+	case domain.KindScore:
+		byNominee, err := a.store.NominationScores(nom.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range tally.RankByScore(candidates, byNominee) {
+			scores[r.ID] = r.Mean
+		}
+
+	default:
+		byGroup, err := a.store.ResultsByGroup(nom.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(byGroup[domain.GroupJury]) > 0 {
+			room, err := a.store.GetRoom(nom.RoomID)
+			if err != nil {
+				return nil, err
+			}
+			counts := make(map[string]map[int64]int, len(byGroup))
+			for g, c := range byGroup {
+				counts[string(g)] = c
+			}
+			weights := map[string]float64{
+				string(domain.GroupJury):     float64(room.JuryWeight) / 100,
+				string(domain.GroupAudience): float64(100-room.JuryWeight) / 100,
+			}
+			for _, r := range tally.Weighted(candidates, counts, weights) {
+				scores[r.ID] = r.Score
+			}
+		} else {
+			for _, c := range byGroup {
+				for id, v := range c {
+					scores[id] += float64(v)
+				}
+			}
+		}
+	}
+
+	rows := make([]standing, 0, len(candidates))
+	for _, c := range candidates {
+		rows = append(rows, standing{ID: c, Name: names[c], Score: scores[c]})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Score > rows[j].Score })
+	return rows, nil
+}
+
+// irvScores превращает раунды instant-runoff в показатель: выбывший в раунде i получает i+1,
+// дошедшие до последнего раунда — больше, чем все выбывшие, и упорядочены по голосам в нём.
+func irvScores(res tally.IRVResult, ballots int) map[int64]float64 {
+	scores := make(map[int64]float64)
+	if ballots == 0 || len(res.Rounds) == 0 {
+		return scores
+	}
+	for i, r := range res.Rounds {
+		for _, id := range r.Eliminated {
+			scores[id] = float64(i + 1)
+		}
+	}
+	last := res.Rounds[len(res.Rounds)-1]
+	for id, votes := range last.Counts {
+		scores[id] = float64(len(res.Rounds)+1) + float64(votes)/float64(ballots+1)
+	}
+	return scores
+}
+
+// topN — первые n мест; ничья на границе расширяет список, номинанты без голосов не проходят.
+func topN(rows []standing, n int) []standing {
+	end := 0
+	for end < len(rows) && rows[end].Score > 0 {
+		if end >= n && rows[end].Score != rows[n-1].Score {
+			break
+		}
+		end++
+	}
+	return rows[:end]
+}
+
+// ---------- История туров ----------
+
+// roundHistory — строка «История туров» для итогов; пусто, если тур у номинации единственный.
+func (a *App) roundHistory(nominationID int64) (string, error) {
+	rounds, err := a.store.RoundHistory(nominationID)
+	if err != nil {
+		return "", err
+	}
+	if len(rounds) < 2 {
+		return "", nil
+	}
+
+	sizes := make(map[int64]int, len(rounds))
+	for _, r := range rounds {
+		nominees, err := a.store.ListNominees(r.ID)
+		if err != nil {
+			return "", err
+		}
+		sizes[r.ID] = len(nominees)
+	}
+	return formatRoundHistory(rounds, nominationID, sizes), nil
+}
+
+func formatRoundHistory(rounds []domain.Nomination, current int64, sizes map[int64]int) string {
+	var sb strings.Builder
+	sb.WriteString("История туров:\n")
+	for i, r := range rounds {
+		fmt.Fprintf(&sb, "%d. %s (ID %d) — номинантов: %d", i+1, r.Name, r.ID, sizes[r.ID])
+		if r.ID == current {
+			sb.WriteString(" 👈")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestTopN(t *testing.T) {
+	t.Parallel()
+
+	rows := []standing{{ID: 1, Score: 5}, {ID: 2, Score: 3}, {ID: 3, Score: 3}, {ID: 4, Score: 1}, {ID: 5, Score: 0}}
+
+	tests := []struct {
+		name string
+		n    int
+		want []int64
+	}{
+		{"top1", 1, []int64{1}},
+		{"tie_on_border_extends", 2, []int64{1, 2, 3}},
+		{"exact", 3, []int64{1, 2, 3}},
+		{"no_votes_excluded", 5, []int64{1, 2, 3, 4}},
+		{"more_than_rows", 10, []int64{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []int64
+			for _, r := range topN(rows, tt.n) {
+				got = append(got, r.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIRVScores_OrderFollowsElimination(t *testing.T) {
+	t.Parallel()
+
+	// 1 побеждает во втором раунде, 3 выбывает первым, 2 доходит до конца вторым
+	ballots := []tally.Ballot{{1}, {1}, {2}, {2}, {3, 1}}
+	scores := irvScores(tally.IRV([]int64{1, 2, 3}, ballots), len(ballots))
+	if !(scores[1] > scores[2] && scores[2] > scores[3] && scores[3] > 0) {
+		t.Fatalf("unexpected scores: %v", scores)
+	}
+
+	if got := irvScores(tally.IRV([]int64{1, 2}, nil), 0); len(got) != 0 {
+		t.Fatalf("no ballots must give no scores, got %v", got)
+	}
+}
+
+func TestFormatRoundHistory(t *testing.T) {
+	t.Parallel()
+
+	rounds := []domain.Nomination{{ID: 3, Name: "Long"}, {ID: 7, Name: "Final", ParentID: 3}}
+	got := formatRoundHistory(rounds, 7, map[int64]int{3: 8, 7: 3})
+	want := "История туров:\n1. Long (ID 3) — номинантов: 8\n2. Final (ID 7) — номинантов: 3 👈\n"
+	if got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	if strings.Count(got, "👈") != 1 {
+		t.Fatalf("exactly one current round expected:\n%s", got)
+	}
+}
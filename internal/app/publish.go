@@ -0,0 +1,465 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+// ---------- Команды ----------
+
+// handlePublish — /publish roomID [winners|full] [nominationID ...]; без ID — все номинации комнаты.
+func (a *App) handlePublish(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 {
+		text := "Формат: /publish roomID [winners|full] [nominationID ...]\n\n" +
+			"winners — участники увидят только победителей, full — полные итоги с голосами (по умолчанию).\n" +
+			"Без nominationID публикуются все номинации комнаты.\n" +
+			"Выбрать номинации по одной можно кнопкой «📢 Итоги для участников» в /nominations."
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	roomID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "roomID должно быть числом."))
+		return
+	}
+	args = args[1:]
+
+	mode := domain.ResultsFull
+	if len(args) > 0 && (args[0] == string(domain.ResultsWinners) || args[0] == string(domain.ResultsFull)) {
+		mode = domain.ResultsMode(args[0])
+		args = args[1:]
+	}
+
+	if !a.checkCanPublish(msg.Chat.ID, msg.From.ID, roomID) {
+		return
+	}
+
+	nominations, err := a.store.ListNominations(roomID)
+	if err != nil {
+		log.Println("ListNominations(publish):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось получить номинации."))
+		return
+	}
+	inRoom := make(map[int64]bool, len(nominations))
+	for _, n := range nominations {
+		inRoom[n.ID] = true
+	}
+
+	var ids []int64
+	if len(args) == 0 {
+		for _, n := range nominations {
+			ids = append(ids, n.ID)
+		}
+	}
+	for _, s := range args {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Не понял %q: ожидаю winners, full или ID номинаций.", s)))
+			return
+		}
+		if !inRoom[id] {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Номинация ID %d не принадлежит этой комнате.", id)))
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "В этой комнате пока нет номинаций."))
+		return
+	}
+
+	for _, id := range ids {
+		if err := a.store.SetResultsMode(id, mode, msg.From.ID, time.Now()); err != nil {
+			log.Println("SetResultsMode(publish):", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось опубликовать итоги."))
+			return
+		}
+	}
+
+	if _, err := a.setRoomStatus(roomID, domain.RoomPublished); err != nil && !errors.Is(err, storage.ErrInvalidTransition) {
+		log.Println("setRoomStatus(publish):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Итоги выбраны, но сменить статус комнаты не удалось."))
+		return
+	}
+
+	m := tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf(
+		"Итоги опубликованы ✅\nНоминаций: %d, режим: %s.\nУчастники комнаты увидят их в /nominations и через /results.",
+		len(ids), mode.Title(),
+	))
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+		),
+	)
+	a.send(m)
+}
+
+// ---------- Кнопки ----------
+
+// handlePublishCallback — pub_screen:<roomID>, pub_set:<nominationID>:<mode|off>, pub_all:<roomID>:<mode|off>
+func (a *App) handlePublishCallback(cq *tgbotapi.CallbackQuery, data string) {
+	chatID := cq.Message.Chat.ID
+	userID := cq.From.ID
+
+	switch {
+	case strings.HasPrefix(data, "pub_screen:"):
+		roomID, err := strconv.ParseInt(strings.TrimPrefix(data, "pub_screen:"), 10, 64)
+		if err != nil {
+			return
+		}
+		if !a.checkCanPublish(chatID, userID, roomID) {
+			return
+		}
+		a.sendPublishScreen(chatID, roomID)
+
+	case strings.HasPrefix(data, "pub_set:"):
+		idStr, modeStr, ok := strings.Cut(strings.TrimPrefix(data, "pub_set:"), ":")
+		if !ok {
+			return
+		}
+		nominationID, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return
+		}
+		roomID, err := a.store.GetNominationRoomID(nominationID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				a.send(tgbotapi.NewMessage(chatID, "Номинация не найдена."))
+			} else {
+				log.Println("pub_set get room:", err)
+			}
+			return
+		}
+		if !a.checkCanPublish(chatID, userID, roomID) {
+			return
+		}
+		if err := a.store.SetResultsMode(nominationID, parseResultsMode(modeStr), userID, time.Now()); err != nil {
+			log.Println("SetResultsMode(pub_set):", err)
+			a.send(tgbotapi.NewMessage(chatID, "Не удалось сохранить выбор."))
+			return
+		}
+		a.sendPublishScreen(chatID, roomID)
+
+	case strings.HasPrefix(data, "pub_all:"):
+		roomStr, modeStr, ok := strings.Cut(strings.TrimPrefix(data, "pub_all:"), ":")
+		if !ok {
+			return
+		}
+		roomID, err := strconv.ParseInt(roomStr, 10, 64)
+		if err != nil {
+			return
+		}
+		if !a.checkCanPublish(chatID, userID, roomID) {
+			return
+		}
+		nominations, err := a.store.ListNominations(roomID)
+		if err != nil {
+			log.Println("ListNominations(pub_all):", err)
+			return
+		}
+		mode := parseResultsMode(modeStr)
+		for _, n := range nominations {
+			if err := a.store.SetResultsMode(n.ID, mode, userID, time.Now()); err != nil {
+				log.Println("SetResultsMode(pub_all):", err)
+				a.send(tgbotapi.NewMessage(chatID, "Не удалось сохранить выбор."))
+				return
+			}
+		}
+		a.sendPublishScreen(chatID, roomID)
+	}
+}
+
+// ---------- Утилиты ----------
+
+// checkCanPublish — итоги выбирают организаторы и только после закрытия голосования.
+func (a *App) checkCanPublish(chatID, userID, roomID int64) bool {
+	role, err := a.store.RoomRole(roomID, userID)
+	if err != nil {
+		log.Println("RoomRole(publish):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+		return false
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(chatID, "Публиковать итоги могут только автор или админы комнаты."))
+		return false
+	}
+
+	status, err := a.store.GetRoomStatus(roomID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(chatID, "Комната не найдена."))
+		} else {
+			log.Println("GetRoomStatus(publish):", err)
+			a.send(tgbotapi.NewMessage(chatID, "Не удалось получить статус комнаты."))
+		}
+		return false
+	}
+	if status != domain.RoomClosed && status != domain.RoomPublished {
+		a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Итоги публикуются после закрытия голосования. Сейчас: %s.", status.Title())))
+		return false
+	}
+	return true
+}
+
+func (a *App) sendPublishScreen(chatID, roomID int64) {
+	nominations, err := a.store.ListNominations(roomID)
+	if err != nil {
+		log.Println("ListNominations(publish screen):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить номинации."))
+		return
+	}
+	modes, err := a.store.ListResultsModes(roomID)
+	if err != nil {
+		log.Println("ListResultsModes:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить настройки публикации."))
+		return
+	}
+	status, err := a.store.GetRoomStatus(roomID)
+	if err != nil {
+		log.Println("GetRoomStatus(publish screen):", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Итоги для участников, комната ID %d\nСтатус: %s\n\n", roomID, status.Title())
+	sb.WriteString("Нажимай на номинацию, чтобы выбрать, что увидят участники: скрыто → победители → полные итоги.\n")
+	if status == domain.RoomClosed {
+		sb.WriteString("Когда всё готово — жми «📢 Опубликовать».")
+	} else {
+		sb.WriteString("Итоги уже опубликованы, изменения видны участникам сразу.")
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, n := range nominations {
+		mode := modes[n.ID]
+		label := fmt.Sprintf("%s %s — %s", resultsModeIcon(mode), n.Name, mode.Title())
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("pub_set:%d:%s", n.ID, resultsModeToken(nextResultsMode(mode)))),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Все: победители", fmt.Sprintf("pub_all:%d:%s", roomID, domain.ResultsWinners)),
+		tgbotapi.NewInlineKeyboardButtonData("Все: полностью", fmt.Sprintf("pub_all:%d:%s", roomID, domain.ResultsFull)),
+		tgbotapi.NewInlineKeyboardButtonData("Все: скрыть", fmt.Sprintf("pub_all:%d:off", roomID)),
+	))
+	if status == domain.RoomClosed {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📢 Опубликовать", fmt.Sprintf("room_status:%d:%s", roomID, domain.RoomPublished)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+	))
+
+	m := tgbotapi.NewMessage(chatID, sb.String())
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	a.send(m)
+}
+
+// resultsModeFor — что пользователь видит в номинации: организаторы и наблюдатели — всё и всегда,
+// участники комнаты — только выбранные номинации и только в статусе published.
+func (a *App) resultsModeFor(userID int64, sess *session.Session, roomID, nominationID int64) (domain.ResultsMode, error) {
+	role, err := a.store.RoomRole(roomID, userID)
+	if err != nil {
+		return domain.ResultsHidden, err
+	}
+	if role.CanViewResults() {
+		return domain.ResultsFull, nil
+	}
+	if sess.ActiveRoomID != roomID {
+		return domain.ResultsHidden, nil
+	}
+
+	status, err := a.store.GetRoomStatus(roomID)
+	if err != nil {
+		return domain.ResultsHidden, err
+	}
+	if status != domain.RoomPublished {
+		return domain.ResultsHidden, nil
+	}
+	return a.store.GetResultsMode(nominationID)
+}
+
+// showResults — общий вывод для /results и кнопок «📊 Результаты» / «🏆 Итоги».
+func (a *App) showResults(chatID, userID int64, sess *session.Session, roomID, nominationID int64) {
+	mode, err := a.resultsModeFor(userID, sess, roomID, nominationID)
+	if err != nil {
+		log.Println("resultsModeFor:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Ошибка проверки прав."))
+		return
+	}
+	if mode == domain.ResultsHidden {
+		a.send(tgbotapi.NewMessage(chatID, "Итоги этой номинации пока не опубликованы."))
+		return
+	}
+
+	roomTitle, err := a.store.GetRoomTitle(roomID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Println("results get room title:", err)
+		}
+		roomTitle = fmt.Sprintf("ID %d", roomID)
+	}
+
+	nom, err := a.store.GetNomination(nominationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(chatID, "Номинация не найдена."))
+		} else {
+			log.Println("results get nomination:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Ошибка при получении номинации."))
+		}
+		return
+	}
+
+	body, err := a.resultsBody(nom, mode)
+	if err != nil {
+		log.Println("results body:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить результаты."))
+		return
+	}
+
+	history, err := a.roundHistory(nominationID)
+	if err != nil {
+		log.Println("round history:", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb,
+		"Результаты голосования\nКомната: %s (ID %d)\nНоминация: %s (ID %d)\n\n",
+		roomTitle, roomID, nom.Name, nominationID,
+	)
+	sb.WriteString(body)
+	if history != "" {
+		sb.WriteString("\n" + history)
+	}
+
+	text := sb.String()
+	if len(text) > 4000 {
+		text = text[:4000] + "\n\n(обрезано, слишком много текста)"
+	}
+	m := tgbotapi.NewMessage(chatID, text)
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+		),
+	)
+	a.send(m)
+}
+
+// resultsBody — итоги номинации в зависимости от её типа.
+func (a *App) resultsBody(nom *domain.Nomination, mode domain.ResultsMode) (string, error) {
+	switch nom.Kind {
+	case domain.KindRanked:
+		return a.rankedResults(nom, mode)
+	case domain.KindScore:
+		return a.scoreResults(nom.ID, mode)
+	case domain.KindBracket:
+		return a.bracketResults(nom.ID, mode)
+	case domain.KindPairwise:
+		return a.pairwiseResults(nom.ID, mode)
+	case domain.KindReferendum:
+		return a.referendumResults(nom, mode)
+	}
+
+	if body, ok, err := a.juryResults(nom, mode); err != nil || ok {
+		return body, err
+	}
+
+	results, err := a.store.ResultsByNomination(nom.ID)
+	if err != nil {
+		return "", err
+	}
+	return formatResults(results, mode), nil
+}
+
+// formatResults — тело сообщения с итогами; results отсортированы по голосам (как отдаёт storage).
+func formatResults(results []domain.NomineeResult, mode domain.ResultsMode) string {
+	if len(results) == 0 {
+		return "В этой номинации пока нет номинантов.\n"
+	}
+
+	var sb strings.Builder
+	if mode == domain.ResultsWinners {
+		top := winners(results)
+		switch len(top) {
+		case 0:
+			sb.WriteString("Голосов не было.\n")
+		case 1:
+			fmt.Fprintf(&sb, "🏆 Победитель: %s — %d голос(ов)\n", top[0].Name, top[0].Votes)
+		default:
+			fmt.Fprintf(&sb, "🏆 Победители (поровну, по %d голос(ов)):\n", top[0].Votes)
+			for _, r := range top {
+				fmt.Fprintf(&sb, "• %s\n", r.Name)
+			}
+		}
+		return sb.String()
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(&sb, "• %s (ID %d) — %d голос(ов)\n", r.Name, r.ID, r.Votes)
+	}
+	return sb.String()
+}
+
+// winners — номинанты с наибольшим числом голосов (несколько при ничьей, никого без голосов).
+func winners(results []domain.NomineeResult) []domain.NomineeResult {
+	if len(results) == 0 || results[0].Votes == 0 {
+		return nil
+	}
+	n := 1
+	for n < len(results) && results[n].Votes == results[0].Votes {
+		n++
+	}
+	return results[:n]
+}
+
+func nextResultsMode(m domain.ResultsMode) domain.ResultsMode {
+	switch m {
+	case domain.ResultsHidden:
+		return domain.ResultsWinners
+	case domain.ResultsWinners:
+		return domain.ResultsFull
+	default:
+		return domain.ResultsHidden
+	}
+}
+
+// resultsModeToken/parseResultsMode — режим в callback data («off» вместо пустой строки).
+func resultsModeToken(m domain.ResultsMode) string {
+	if m == domain.ResultsHidden {
+		return "off"
+	}
+	return string(m)
+}
+
+func parseResultsMode(s string) domain.ResultsMode {
+	switch domain.ResultsMode(s) {
+	case domain.ResultsWinners, domain.ResultsFull:
+		return domain.ResultsMode(s)
+	default:
+		return domain.ResultsHidden
+	}
+}
+
+func resultsModeIcon(m domain.ResultsMode) string {
+	switch m {
+	case domain.ResultsWinners:
+		return "🏆"
+	case domain.ResultsFull:
+		return "📊"
+	default:
+		return "🙈"
+	}
+}
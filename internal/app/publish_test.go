@@ -0,0 +1,78 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestWinners(t *testing.T) {
+	t.Parallel()
+
+	r := func(id, votes int64) domain.NomineeResult { return domain.NomineeResult{ID: id, Votes: votes} }
+
+	tests := []struct {
+		name    string
+		results []domain.NomineeResult
+		want    []int64
+	}{
+		{"empty", nil, nil},
+		{"no_votes", []domain.NomineeResult{r(1, 0), r(2, 0)}, nil},
+		{"single", []domain.NomineeResult{r(1, 5), r(2, 3)}, []int64{1}},
+		{"tie", []domain.NomineeResult{r(1, 4), r(2, 4), r(3, 1)}, []int64{1, 2}},
+		{"all_tied", []domain.NomineeResult{r(1, 2), r(2, 2)}, []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := winners(tt.results)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want ids %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].ID != tt.want[i] {
+					t.Fatalf("got %+v, want ids %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatResults_Modes(t *testing.T) {
+	t.Parallel()
+
+	results := []domain.NomineeResult{
+		{ID: 1, Name: "Alice", Votes: 3},
+		{ID: 2, Name: "Bob", Votes: 1},
+	}
+
+	full := formatResults(results, domain.ResultsFull)
+	if !strings.Contains(full, "Alice (ID 1) — 3") || !strings.Contains(full, "Bob (ID 2) — 1") {
+		t.Fatalf("full mode should list every nominee with votes:\n%s", full)
+	}
+
+	win := formatResults(results, domain.ResultsWinners)
+	if !strings.Contains(win, "Победитель: Alice") || strings.Contains(win, "Bob") {
+		t.Fatalf("winners mode should show only the winner:\n%s", win)
+	}
+}
+
+func TestNextResultsMode_Cycles(t *testing.T) {
+	t.Parallel()
+
+	m := domain.ResultsHidden
+	want := []domain.ResultsMode{domain.ResultsWinners, domain.ResultsFull, domain.ResultsHidden}
+	for _, w := range want {
+		m = nextResultsMode(m)
+		if m != w {
+			t.Fatalf("got %q want %q", m, w)
+		}
+		if parseResultsMode(resultsModeToken(m)) != m {
+			t.Fatalf("callback token round-trip failed for %q", m)
+		}
+	}
+}
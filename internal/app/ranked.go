@@ -0,0 +1,434 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+// ---------- Команды ----------
+
+// handleNominationMode — /nomination_mode nominationID plurality|ranked|score
+func (a *App) handleNominationMode(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		text := "Формат: /nomination_mode nominationID режим\n\n" +
+			"Режимы:\n" +
+			"plurality — выбор номинанта (или до K, см. /set_max_choices)\n" +
+			"ranked — участники ранжируют номинантов, итог считается instant-runoff\n" +
+			"score — каждый номинант получает оценку от 1 до 5, итог по средней\n" +
+			"bracket — турнирная сетка на выбывание, матчи один на один (см. /bracket)\n" +
+			"pairwise — участники выбирают лучшего из случайных пар, итог — рейтинг Брэдли–Терри\n" +
+			"referendum — вопрос с ответами «за», «против», «воздержаться» (порог и кворум — /referendum)\n\n" +
+			"Менять можно, пока в номинации нет голосов."
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	nominationID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "nominationID должно быть числом."))
+		return
+	}
+	kind := domain.NominationKind(args[1])
+	switch kind {
+	case domain.KindPlurality, domain.KindRanked, domain.KindScore, domain.KindBracket, domain.KindPairwise, domain.KindReferendum:
+	default:
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Неизвестный режим. Доступны: plurality, ranked, score, bracket, pairwise, referendum."))
+		return
+	}
+
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
+	if err != nil {
+		log.Println("NominationRole(nomination_mode):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Настраивать номинацию могут только автор или админы комнаты."))
+		return
+	}
+
+	if err := a.store.SetNominationKind(nominationID, kind); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Номинация не найдена."))
+		case errors.Is(err, storage.ErrHasVotes):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "В номинации уже есть голоса — режим менять нельзя."))
+		default:
+			log.Println("SetNominationKind:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить настройку."))
+		}
+		return
+	}
+
+	a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Готово ✅ Режим номинации: %s.", kind.Title())))
+}
+
+// handleSetTally — /set_tally nominationID irv|borda
+func (a *App) handleSetTally(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		text := "Формат: /set_tally nominationID способ\n\n" +
+			"Способы подсчёта ранжированных бюллетеней:\n" +
+			"irv — instant-runoff, можно ранжировать не всех\n" +
+			"borda — Борда: за 1-е место n−1 очков, …, за последнее 0; нужно расставить всех\n\n" +
+			"Номинация станет ранжированной. Менять можно, пока в номинации нет голосов."
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	nominationID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "nominationID должно быть числом."))
+		return
+	}
+	method := domain.TallyMethod(args[1])
+	if method != domain.TallyIRV && method != domain.TallyBorda {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Неизвестный способ. Доступны: irv, borda."))
+		return
+	}
+
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
+	if err != nil {
+		log.Println("NominationRole(set_tally):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Настраивать номинацию могут только автор или админы комнаты."))
+		return
+	}
+
+	if err := a.store.SetNominationTally(nominationID, method); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Номинация не найдена."))
+		case errors.Is(err, storage.ErrHasVotes):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "В номинации уже есть голоса — способ подсчёта менять нельзя."))
+		default:
+			log.Println("SetNominationTally:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить настройку."))
+		}
+		return
+	}
+
+	a.send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("Готово ✅ Номинация ранжированная, подсчёт: %s.", method.Title())))
+}
+
+// ---------- Кнопки ----------
+
+// handleRankedCallback — rank_add:<nominationID>:<nomineeID>, rank_reset:<nominationID>, rank_ok:<nominationID>
+func (a *App) handleRankedCallback(cq *tgbotapi.CallbackQuery, sess *session.Session, data string) {
+	chatID := cq.Message.Chat.ID
+	action, rest, ok := strings.Cut(data, ":")
+	if !ok {
+		return
+	}
+	nomStr, nomineeStr, _ := strings.Cut(rest, ":")
+	nominationID, err := strconv.ParseInt(nomStr, 10, 64)
+	if err != nil {
+		return
+	}
+
+	nom, err := a.store.GetNomination(nominationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(chatID, "Эта номинация больше не существует."))
+		} else {
+			log.Println("get nomination(ranked):", err)
+		}
+		return
+	}
+	if sess.ActiveRoomID != nom.RoomID {
+		a.send(tgbotapi.NewMessage(chatID, "У тебя нет доступа к этой комнате. Сначала зайди в неё командой /room."))
+		return
+	}
+
+	userHash := a.hashUserID(cq.From.ID)
+
+	switch action {
+	case "rank_add":
+		nomineeID, err := strconv.ParseInt(nomineeStr, 10, 64)
+		if err != nil {
+			return
+		}
+		if err := a.store.AppendRankingDraft(userHash, nominationID, nomineeID); err != nil {
+			if errors.Is(err, storage.ErrNomineeMismatch) || errors.Is(err, storage.ErrNotFound) {
+				a.send(tgbotapi.NewMessage(chatID, "Этого номинанта нет в номинации — открой её заново."))
+				return
+			}
+			log.Println("AppendRankingDraft:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+			return
+		}
+		a.sendRankedBallot(chatID, cq.From.ID, nom, cq.Message)
+
+	case "rank_reset":
+		if err := a.store.ClearRankingDraft(userHash, nominationID); err != nil {
+			log.Println("ClearRankingDraft:", err)
+			return
+		}
+		a.sendRankedBallot(chatID, cq.From.ID, nom, cq.Message)
+
+	case "rank_ok":
+		order, err := a.store.SubmitRanking(userHash, nominationID, time.Now())
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrVotingClosed), errors.Is(err, storage.ErrNextRound):
+				a.send(tgbotapi.NewMessage(chatID, "Голос не принят: "+a.voteRejectedReason(nom.RoomID, err)+"."))
+			case errors.Is(err, storage.ErrEmptyBallot):
+				a.send(tgbotapi.NewMessage(chatID, "Сначала выбери хотя бы одного номинанта."))
+			case errors.Is(err, storage.ErrIncompleteBallot):
+				a.send(tgbotapi.NewMessage(chatID, "В этой номинации подсчёт по Борда — расставь всех номинантов, потом подтверждай."))
+			case errors.Is(err, storage.ErrVoteLocked):
+				a.send(tgbotapi.NewMessage(chatID, "В этой комнате голос окончательный — отправленный бюллетень уже не поменять."))
+			default:
+				log.Println("SubmitRanking:", err)
+				a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+			}
+			return
+		}
+
+		names, err := a.nomineeNames(nominationID)
+		if err != nil {
+			log.Println("nomineeNames(rank_ok):", err)
+		}
+		a.send(tgbotapi.NewMessage(chatID, "Бюллетень принят ✅\n"+formatRanking(order, names)))
+
+		if err := a.sendNominationsList(chatID, cq.From.ID, nom.RoomID); err != nil {
+			log.Println("sendNominationsList(after ranking):", err)
+		}
+	}
+}
+
+// ---------- Утилиты ----------
+
+// sendRankedBallot — бюллетень ранжирования: кнопки невыбранных номинантов по порядку нажатия.
+// Если edit не nil, обновляем это сообщение вместо отправки нового.
+func (a *App) sendRankedBallot(chatID, userID int64, nom *domain.Nomination, edit *tgbotapi.Message) {
+	nominees, err := a.store.ListNominees(nom.ID)
+	if err != nil {
+		log.Println("ListNominees(ranked ballot):", err)
+		return
+	}
+	userHash := a.hashUserID(userID)
+	draft, err := a.store.RankingDraft(userHash, nom.ID)
+	if err != nil {
+		log.Println("RankingDraft:", err)
+	}
+	submitted, err := a.store.UserRanking(userHash, nom.ID)
+	if err != nil {
+		log.Println("UserRanking:", err)
+	}
+
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		names[n.ID] = n.Name
+	}
+	inDraft := make(map[int64]bool, len(draft))
+	for _, id := range draft {
+		inDraft[id] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🗳 Бюллетень: ранжирование\n")
+	if nom.TallyMethod == domain.TallyBorda {
+		sb.WriteString("Нажимай на номинантов по порядку: первым — самого желанного. Нужно расставить всех — подсчёт по Борда.\n\n")
+	} else {
+		sb.WriteString("Нажимай на номинантов по порядку: первым — самого желанного. Можно выбрать не всех.\n\n")
+	}
+	if len(draft) == 0 {
+		sb.WriteString("Пока никто не выбран.\n")
+	} else {
+		sb.WriteString(formatRanking(draft, names))
+	}
+	if len(submitted) > 0 {
+		sb.WriteString("\nУже отправлено:\n")
+		sb.WriteString(formatRanking(submitted, names))
+		sb.WriteString("Новый бюллетень заменит его.\n")
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	next := len(draft) + 1
+	for _, n := range nominees {
+		if inDraft[n.ID] {
+			continue
+		}
+		label := fmt.Sprintf("%d. %s", next, n.Name)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("rank_add:%d:%d", nom.ID, n.ID)),
+		))
+	}
+	if len(draft) > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Сначала", fmt.Sprintf("rank_reset:%d", nom.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("✅ Подтвердить", fmt.Sprintf("rank_ok:%d", nom.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	if edit != nil {
+		a.send(tgbotapi.NewEditMessageTextAndMarkup(chatID, edit.MessageID, sb.String(), kb))
+		return
+	}
+	m := tgbotapi.NewMessage(chatID, sb.String())
+	m.ReplyMarkup = kb
+	a.send(m)
+}
+
+func (a *App) nomineeNames(nominationID int64) (map[int64]string, error) {
+	nominees, err := a.store.ListNominees(nominationID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		names[n.ID] = n.Name
+	}
+	return names, nil
+}
+
+// rankedResults — итоги по всем отправленным бюллетеням выбранным способом подсчёта.
+func (a *App) rankedResults(nom *domain.Nomination, mode domain.ResultsMode) (string, error) {
+	nominees, err := a.store.ListNominees(nom.ID)
+	if err != nil {
+		return "", err
+	}
+	ballots, err := a.store.RankedBallots(nom.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(nominees) == 0 {
+		return "В этой номинации пока нет номинантов.\n", nil
+	}
+
+	candidates := make([]int64, 0, len(nominees))
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		candidates = append(candidates, n.ID)
+		names[n.ID] = n.Name
+	}
+	if nom.TallyMethod == domain.TallyBorda {
+		return formatBorda(tally.Borda(candidates, ballots), names, len(ballots), mode), nil
+	}
+	return formatIRV(tally.IRV(candidates, ballots), names, candidates, len(ballots), mode), nil
+}
+
+// nominationModeTitle — пометка способа голосования в списке номинаций (пусто для обычного выбора одного).
+func nominationModeTitle(n domain.Nomination) string {
+	switch {
+	case n.Kind == domain.KindRanked:
+		return n.Kind.Title() + ", " + n.TallyMethod.Title()
+	case n.Kind != domain.KindPlurality:
+		return n.Kind.Title()
+	case n.MaxChoices > 1:
+		return fmt.Sprintf("выбор до %d", n.MaxChoices)
+	default:
+		return ""
+	}
+}
+
+func formatRanking(order []int64, names map[int64]string) string {
+	var sb strings.Builder
+	for i, id := range order {
+		name := names[id]
+		if name == "" {
+			name = fmt.Sprintf("ID %d", id)
+		}
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, name)
+	}
+	return sb.String()
+}
+
+// formatIRV — итоги по раундам; в режиме «только победители» раунды не показываем.
+func formatIRV(res tally.IRVResult, names map[int64]string, order []int64, ballots int, mode domain.ResultsMode) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Ранжированное голосование (instant-runoff), бюллетеней: %d\n\n", ballots)
+
+	if mode != domain.ResultsWinners {
+		for i, r := range res.Rounds {
+			fmt.Fprintf(&sb, "Раунд %d:\n", i+1)
+			for _, id := range order {
+				if votes, ok := r.Counts[id]; ok {
+					fmt.Fprintf(&sb, "• %s — %d\n", names[id], votes)
+				}
+			}
+			if r.Exhausted > 0 {
+				fmt.Fprintf(&sb, "Исчерпано бюллетеней: %d\n", r.Exhausted)
+			}
+			if len(r.Eliminated) > 0 {
+				out := make([]string, 0, len(r.Eliminated))
+				for _, id := range r.Eliminated {
+					out = append(out, names[id])
+				}
+				fmt.Fprintf(&sb, "Выбывает: %s\n", strings.Join(out, ", "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	switch len(res.Winners) {
+	case 0:
+		sb.WriteString("Голосов не было.\n")
+	case 1:
+		fmt.Fprintf(&sb, "🏆 Победитель: %s\n", names[res.Winners[0]])
+	default:
+		out := make([]string, 0, len(res.Winners))
+		for _, id := range res.Winners {
+			out = append(out, names[id])
+		}
+		fmt.Fprintf(&sb, "🏆 Ничья: %s\n", strings.Join(out, ", "))
+	}
+	return sb.String()
+}
+
+// formatBorda — очки и раскладка по местам; в режиме «только победители» — только лидеры.
+func formatBorda(rows []tally.BordaRow, names map[int64]string, ballots int, mode domain.ResultsMode) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Подсчёт по Борда (за 1-е место %d очк., за последнее 0), бюллетеней: %d\n\n", max(len(rows)-1, 0), ballots)
+
+	if len(rows) == 0 || ballots == 0 {
+		sb.WriteString("Голосов не было.\n")
+		return sb.String()
+	}
+
+	if mode == domain.ResultsWinners {
+		var top []string
+		for _, r := range rows {
+			if r.Points != rows[0].Points {
+				break
+			}
+			top = append(top, names[r.ID])
+		}
+		if len(top) == 1 {
+			fmt.Fprintf(&sb, "🏆 Победитель: %s — %d очк.\n", top[0], rows[0].Points)
+		} else {
+			fmt.Fprintf(&sb, "🏆 Победители (поровну, по %d очк.): %s\n", rows[0].Points, strings.Join(top, ", "))
+		}
+		return sb.String()
+	}
+
+	for _, r := range rows {
+		places := make([]string, len(r.Positions))
+		for i, cnt := range r.Positions {
+			places[i] = strconv.Itoa(cnt)
+		}
+		fmt.Fprintf(&sb, "• %s (ID %d) — %d очк.; места 1…%d: %s\n",
+			names[r.ID], r.ID, r.Points, len(r.Positions), strings.Join(places, " / "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestFormatIRV(t *testing.T) {
+	t.Parallel()
+
+	names := map[int64]string{1: "Alice", 2: "Bob", 3: "Carol"}
+	order := []int64{1, 2, 3}
+	ballots := []tally.Ballot{{1}, {1}, {1}, {2, 1}, {2}, {2}, {3, 2}, {3, 2}}
+	res := tally.IRV(order, ballots)
+
+	full := formatIRV(res, names, order, len(ballots), domain.ResultsFull)
+	for _, want := range []string{"бюллетеней: 8", "Раунд 1:", "• Carol — 2", "Выбывает: Carol", "Раунд 2:", "• Bob — 5", "Победитель: Bob"} {
+		if !strings.Contains(full, want) {
+			t.Fatalf("full output missing %q:\n%s", want, full)
+		}
+	}
+
+	win := formatIRV(res, names, order, len(ballots), domain.ResultsWinners)
+	if strings.Contains(win, "Раунд") || !strings.Contains(win, "Победитель: Bob") {
+		t.Fatalf("winners output should hide rounds:\n%s", win)
+	}
+}
+
+func TestFormatBorda(t *testing.T) {
+	t.Parallel()
+
+	names := map[int64]string{1: "Alice", 2: "Bob", 3: "Carol"}
+	ballots := []tally.Ballot{{1, 2, 3}, {1, 2, 3}, {3, 2, 1}, {3, 2, 1}, {2, 1, 3}}
+	rows := tally.Borda([]int64{1, 2, 3}, ballots)
+
+	full := formatBorda(rows, names, len(ballots), domain.ResultsFull)
+	for _, want := range []string{"за 1-е место 2 очк.", "бюллетеней: 5", "• Bob (ID 2) — 6 очк.; места 1…3: 1 / 4 / 0", "• Alice (ID 1) — 5 очк."} {
+		if !strings.Contains(full, want) {
+			t.Fatalf("full output missing %q:\n%s", want, full)
+		}
+	}
+
+	win := formatBorda(rows, names, len(ballots), domain.ResultsWinners)
+	if !strings.Contains(win, "Победитель: Bob — 6 очк.") || strings.Contains(win, "Alice") {
+		t.Fatalf("winners output should show only Bob:\n%s", win)
+	}
+
+	// единственный номинант получает 0 очков, но голоса были
+	solo := formatBorda(tally.Borda([]int64{1}, []tally.Ballot{{1}, {1}}), names, 2, domain.ResultsWinners)
+	if strings.Contains(solo, "Голосов не было") || !strings.Contains(solo, "Победитель: Alice — 0 очк.") {
+		t.Fatalf("single nominee with ballots:\n%s", solo)
+	}
+	if empty := formatBorda(tally.Borda([]int64{1, 2}, nil), names, 0, domain.ResultsFull); !strings.Contains(empty, "Голосов не было") {
+		t.Fatalf("no ballots:\n%s", empty)
+	}
+}
+
+func TestNominationModeTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		n    domain.Nomination
+		want string
+	}{
+		{domain.Nomination{Kind: domain.KindPlurality, MaxChoices: 1}, ""},
+		{domain.Nomination{Kind: domain.KindPlurality, MaxChoices: 3}, "выбор до 3"},
+		{domain.Nomination{Kind: domain.KindRanked, TallyMethod: domain.TallyBorda}, "ранжирование, Борда"},
+		{domain.Nomination{Kind: domain.KindScore, MaxChoices: 1}, "оценки 1–5"},
+	}
+	for _, tt := range tests {
+		if got := nominationModeTitle(tt.n); got != tt.want {
+			t.Fatalf("nominationModeTitle(%+v) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
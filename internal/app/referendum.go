@@ -0,0 +1,272 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+// referendumChoices — варианты ответа в порядке кнопок.
+var referendumChoices = []domain.ReferendumChoice{domain.ChoiceYes, domain.ChoiceNo, domain.ChoiceAbstain}
+
+// ---------- Команды ----------
+
+// handleReferendum — /referendum nominationID majority|2/3 [кворум%]
+func (a *App) handleReferendum(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) < 2 || len(args) > 3 {
+		text := "Формат: /referendum nominationID порог [кворум%]\n\n" +
+			"Делает номинацию референдумом: вопрос — название и описание номинации, " +
+			"ответы — «за», «против», «воздержаться».\n" +
+			"Пороги:\n" +
+			"majority — простое большинство: «за» больше, чем «против»\n" +
+			"2/3 — «за» не меньше двух третей от «за» и «против»\n" +
+			"Кворум — сколько процентов участников комнаты должны проголосовать (воздержавшиеся считаются); " +
+			"без него — кворум не нужен.\n\n" +
+			"Менять можно, пока в номинации нет голосов.\n\n" +
+			"Пример:\n/referendum 5 2/3 50"
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	nominationID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "nominationID должно быть числом."))
+		return
+	}
+	rule, ok := parsePassRule(args[1])
+	if !ok {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Неизвестный порог. Доступны: majority, 2/3."))
+		return
+	}
+	quorum := 0
+	if len(args) == 3 {
+		quorum, err = strconv.Atoi(strings.TrimSuffix(args[2], "%"))
+		if err != nil || quorum < 0 || quorum > 100 {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Кворум — число процентов от 0 до 100."))
+			return
+		}
+	}
+
+	role, err := a.store.NominationRole(nominationID, msg.From.ID)
+	if err != nil {
+		log.Println("NominationRole(referendum):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Настраивать номинацию могут только автор или админы комнаты."))
+		return
+	}
+
+	if err := a.store.SetReferendumRule(nominationID, rule, quorum); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Номинация не найдена."))
+		case errors.Is(err, storage.ErrHasVotes):
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "В номинации уже есть голоса — порог менять нельзя."))
+		default:
+			log.Println("SetReferendumRule:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить настройку."))
+		}
+		return
+	}
+
+	a.send(tgbotapi.NewMessage(msg.Chat.ID, "Готово ✅ Номинация стала референдумом.\n"+referendumRuleText(rule, quorum)))
+}
+
+// ---------- Кнопки ----------
+
+// handleReferendumCallback — ref:<nominationID>:<yes|no|abstain>
+func (a *App) handleReferendumCallback(cq *tgbotapi.CallbackQuery, sess *session.Session, data string) {
+	chatID := cq.Message.Chat.ID
+
+	idStr, choiceStr, ok := strings.Cut(strings.TrimPrefix(data, "ref:"), ":")
+	if !ok {
+		return
+	}
+	nominationID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return
+	}
+	choice := domain.ReferendumChoice(choiceStr)
+	switch choice {
+	case domain.ChoiceYes, domain.ChoiceNo, domain.ChoiceAbstain:
+	default:
+		return
+	}
+
+	nom, err := a.store.GetNomination(nominationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(chatID, "Эта номинация больше не существует."))
+		} else {
+			log.Println("get nomination(referendum):", err)
+		}
+		return
+	}
+	if sess.ActiveRoomID != nom.RoomID {
+		a.send(tgbotapi.NewMessage(chatID, "У тебя нет доступа к этой комнате. Сначала зайди в неё командой /room."))
+		return
+	}
+	if nom.Kind != domain.KindReferendum {
+		a.send(tgbotapi.NewMessage(chatID, "Эта номинация больше не референдум — открой её заново."))
+		return
+	}
+
+	if err := a.store.RecordReferendumVote(a.hashUserID(cq.From.ID), nominationID, choice, time.Now()); err != nil {
+		if reason := a.voteRejectedReason(nom.RoomID, err); reason != "" {
+			a.send(tgbotapi.NewMessage(chatID, "Голос не принят: "+reason+"."))
+			return
+		}
+		if errors.Is(err, storage.ErrVoteLocked) {
+			a.send(tgbotapi.NewMessage(chatID, "В этой комнате голос окончательный — ответ уже не поменять."))
+			return
+		}
+		log.Println("RecordReferendumVote:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		return
+	}
+
+	if cq.Message.ReplyMarkup != nil {
+		a.send(tgbotapi.NewEditMessageReplyMarkup(chatID, cq.Message.MessageID, referendumKeyboard(nominationID, choice)))
+	}
+	a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Голос принят: %s ✅ Пока голосование открыто, его можно поменять.", choice.Title())))
+}
+
+// ---------- Утилиты ----------
+
+// sendReferendum — вопрос референдума с кнопками ответа; текущий ответ отмечен.
+func (a *App) sendReferendum(chatID, userID int64, nom *domain.Nomination) {
+	chosen, err := a.store.UserReferendumVote(a.hashUserID(userID), nom.ID)
+	if err != nil {
+		log.Println("UserReferendumVote:", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🗳 %s\n", nom.Name)
+	if nom.Description != "" {
+		fmt.Fprintf(&sb, "\n%s\n", nom.Description)
+	}
+	fmt.Fprintf(&sb, "\n%s\n", referendumRuleText(nom.PassRule, nom.QuorumPct))
+	if chosen != "" {
+		fmt.Fprintf(&sb, "\nТвой ответ: %s.", chosen.Title())
+	}
+
+	m := tgbotapi.NewMessage(chatID, sb.String())
+	m.ReplyMarkup = referendumKeyboard(nom.ID, chosen)
+	a.send(m)
+}
+
+// referendumKeyboard — «за», «против», «воздержаться» (выбранный ответ отмечен) и «назад».
+func referendumKeyboard(nominationID int64, chosen domain.ReferendumChoice) tgbotapi.InlineKeyboardMarkup {
+	icons := map[domain.ReferendumChoice]string{
+		domain.ChoiceYes:     "👍",
+		domain.ChoiceNo:      "👎",
+		domain.ChoiceAbstain: "🤷",
+	}
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(referendumChoices))
+	for _, c := range referendumChoices {
+		label := icons[c] + " " + capitalize(c.Title())
+		if c == chosen {
+			label = "✅ " + capitalize(c.Title())
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("ref:%d:%s", nominationID, c)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		row,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations"),
+		),
+	)
+}
+
+func parsePassRule(s string) (domain.PassRule, bool) {
+	switch strings.ToLower(s) {
+	case "majority":
+		return domain.PassMajority, true
+	case "2/3", "two_thirds":
+		return domain.PassTwoThirds, true
+	}
+	return "", false
+}
+
+func referendumRuleText(rule domain.PassRule, quorumPct int) string {
+	text := "Порог: простое большинство — «за» больше, чем «против»."
+	if rule == domain.PassTwoThirds {
+		text = "Порог: две трети — «за» не меньше 2/3 от голосов «за» и «против»."
+	}
+	if quorumPct > 0 {
+		text += fmt.Sprintf("\nКворум: должны проголосовать не меньше %d%% участников комнаты.", quorumPct)
+	}
+	return text
+}
+
+// ---------- Итоги ----------
+
+func (a *App) referendumResults(nom *domain.Nomination, mode domain.ResultsMode) (string, error) {
+	counts, err := a.store.ReferendumCounts(nom.ID)
+	if err != nil {
+		return "", err
+	}
+	members := 0
+	if nom.QuorumPct > 0 {
+		members, err = a.store.RoomVoterCount(nom.RoomID)
+		if err != nil {
+			return "", err
+		}
+	}
+	r := tally.Referendum(counts[domain.ChoiceYes], counts[domain.ChoiceNo], counts[domain.ChoiceAbstain],
+		nom.PassRule == domain.PassTwoThirds, members, nom.QuorumPct)
+	return formatReferendum(r, nom.PassRule, members, mode), nil
+}
+
+func formatReferendum(r tally.ReferendumResult, rule domain.PassRule, members int, mode domain.ResultsMode) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Референдум, порог: %s\n\n", rule.Title())
+
+	if r.Passed {
+		sb.WriteString("✅ ПРИНЯТО\n")
+	} else {
+		sb.WriteString("❌ НЕ ПРИНЯТО\n")
+	}
+
+	decisive := r.Yes + r.No
+	share := func(n int) string {
+		if decisive == 0 {
+			return "—"
+		}
+		return formatPercent(float64(n) / float64(decisive))
+	}
+	fmt.Fprintf(&sb, "За: %d (%s) · Против: %d (%s) · Воздержались: %d\n", r.Yes, share(r.Yes), r.No, share(r.No), r.Abstain)
+	if mode == domain.ResultsWinners {
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "\nПорог: нужно «за» не меньше %d из %d — ", r.Required, decisive)
+	if r.Margin >= 0 {
+		fmt.Fprintf(&sb, "пройден, запас %d\n", r.Margin)
+	} else {
+		fmt.Fprintf(&sb, "не хватило %d\n", -r.Margin)
+	}
+
+	if r.QuorumNeeded > 0 {
+		fmt.Fprintf(&sb, "Кворум: проголосовали %d из %d, нужно %d — ", r.Turnout, max(members, r.Turnout), r.QuorumNeeded)
+		if r.QuorumMet {
+			fmt.Fprintf(&sb, "набран, запас %d\n", r.Turnout-r.QuorumNeeded)
+		} else {
+			fmt.Fprintf(&sb, "не набран, не хватило %d\n", r.QuorumNeeded-r.Turnout)
+		}
+	}
+	return sb.String()
+}
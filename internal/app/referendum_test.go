@@ -0,0 +1,105 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestParsePassRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want domain.PassRule
+		ok   bool
+	}{
+		{"majority", domain.PassMajority, true},
+		{"MAJORITY", domain.PassMajority, true},
+		{"2/3", domain.PassTwoThirds, true},
+		{"two_thirds", domain.PassTwoThirds, true},
+		{"3/4", "", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parsePassRule(tt.in)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("got %q, %v want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestReferendumKeyboard(t *testing.T) {
+	t.Parallel()
+
+	kb := referendumKeyboard(4, domain.ChoiceNo)
+	row := kb.InlineKeyboard[0]
+	if len(row) != 3 || *row[0].CallbackData != "ref:4:yes" || *row[2].CallbackData != "ref:4:abstain" {
+		t.Fatalf("unexpected row: %+v", row)
+	}
+	if row[1].Text != "✅ Против" || strings.HasPrefix(row[0].Text, "✅") {
+		t.Fatalf("only the chosen answer must be marked: %q, %q", row[0].Text, row[1].Text)
+	}
+}
+
+func TestFormatReferendum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		r       tally.ReferendumResult
+		rule    domain.PassRule
+		members int
+		mode    domain.ResultsMode
+		want    []string
+	}{
+		{
+			name: "passed",
+			r:    tally.Referendum(5, 3, 1, false, 0, 0),
+			rule: domain.PassMajority,
+			mode: domain.ResultsFull,
+			want: []string{"✅ ПРИНЯТО", "За: 5 (62.5%)", "Против: 3 (37.5%)", "Воздержались: 1", "не меньше 5 из 8 — пройден, запас 0"},
+		},
+		{
+			name:    "failed_on_quorum",
+			r:       tally.Referendum(3, 0, 0, true, 10, 50),
+			rule:    domain.PassTwoThirds,
+			members: 10,
+			mode:    domain.ResultsFull,
+			want:    []string{"порог: две трети", "❌ НЕ ПРИНЯТО", "проголосовали 3 из 10, нужно 5 — не набран, не хватило 2"},
+		},
+		{
+			name: "failed_on_threshold",
+			r:    tally.Referendum(2, 2, 0, false, 0, 0),
+			rule: domain.PassMajority,
+			mode: domain.ResultsFull,
+			want: []string{"❌ НЕ ПРИНЯТО", "не хватило 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := formatReferendum(tt.r, tt.rule, tt.members, tt.mode)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Fatalf("missing %q in:\n%s", want, got)
+				}
+			}
+		})
+	}
+
+	winners := formatReferendum(tally.Referendum(5, 3, 0, false, 0, 0), domain.PassMajority, 0, domain.ResultsWinners)
+	if !strings.Contains(winners, "ПРИНЯТО") || strings.Contains(winners, "Порог: нужно") {
+		t.Fatalf("winners mode shows only the verdict and counts:\n%s", winners)
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+// ---------- Команды ----------
+
+// handleAllowRevote — /allow_revote roomID on|off
+func (a *App) handleAllowRevote(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+		text := "Формат: /allow_revote roomID on|off\n\n" +
+			"on — участник может поменять или отозвать голос (по умолчанию)\n" +
+			"off — первый голос окончательный в любом режиме: выбор нельзя сменить или отозвать, " +
+			"отметки — снять, оценку, бюллетень, выбор в паре, ответ референдума и голос в матче — поменять."
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	roomID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "roomID должно быть числом."))
+		return
+	}
+	on := args[1] == "on"
+
+	role, err := a.store.RoomRole(roomID, msg.From.ID)
+	if err != nil {
+		log.Println("RoomRole(allow_revote):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Настраивать переголосование могут только автор или админы комнаты."))
+		return
+	}
+
+	if err := a.store.SetAllowRevote(roomID, on); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Комната не найдена."))
+		} else {
+			log.Println("SetAllowRevote:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить настройку."))
+		}
+		return
+	}
+
+	text := fmt.Sprintf("Готово ✅ В комнате ID %d первый голос окончательный.", roomID)
+	if on {
+		text = fmt.Sprintf("Готово ✅ В комнате ID %d голос можно менять.", roomID)
+	}
+	a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+}
+
+// ---------- Утилиты ----------
+
+// lockedVoteText — отказ в переголосовании с именем уже выбранного номинанта.
+func (a *App) lockedVoteText(userHash string, nominationID int64) string {
+	voted, err := a.store.UserNomineeVotes(userHash, nominationID)
+	if err != nil {
+		log.Println("UserNomineeVotes(locked):", err)
+	}
+	name := ""
+	for id := range voted {
+		if name, err = a.store.GetNomineeName(id); err != nil {
+			log.Println("get nominee name:", err)
+		}
+	}
+	if name == "" {
+		return "Голос не принят: ты уже проголосовал(а) в этой номинации, а в этой комнате голос менять нельзя."
+	}
+	return fmt.Sprintf("Голос не принят: ты уже проголосовал(а) за «%s», а в этой комнате голос менять нельзя.", name)
+}
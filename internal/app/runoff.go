@@ -0,0 +1,200 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+// ---------- Команды ----------
+
+// handleAutoRunoff — /auto_runoff roomID on|off
+func (a *App) handleAutoRunoff(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+		text := "Формат: /auto_runoff roomID on|off\n\n" +
+			"on — при закрытии голосования ничья на первом месте в номинации с выбором " +
+			"сама создаёт переголосование только между лидерами."
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	roomID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "roomID должно быть числом."))
+		return
+	}
+	on := args[1] == "on"
+
+	role, err := a.store.RoomRole(roomID, msg.From.ID)
+	if err != nil {
+		log.Println("RoomRole(auto_runoff):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Настраивать переголосование могут только автор или админы комнаты."))
+		return
+	}
+
+	if err := a.store.SetAutoRunoff(roomID, on); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Комната не найдена."))
+		} else {
+			log.Println("SetAutoRunoff:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить настройку."))
+		}
+		return
+	}
+
+	text := fmt.Sprintf("Готово ✅ Автоматическое переголосование при ничьей в комнате ID %d выключено.", roomID)
+	if on {
+		text = fmt.Sprintf("Готово ✅ В комнате ID %d при закрытии голосования ничья на первом месте создаст переголосование.", roomID)
+	}
+	a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+}
+
+// ---------- Закрытие голосования ----------
+
+// createRunoffs вызывается после перехода open → closed. Если в комнате включён auto_runoff,
+// для каждой номинации с выбором, где на первом месте ничья, создаёт переголосование из лидеров,
+// уведомляет голосовавших в исходной номинации и присылает автору сводку.
+func (a *App) createRunoffs(roomID int64) {
+	room, err := a.store.GetRoom(roomID)
+	if err != nil {
+		log.Println("GetRoom(runoff):", err)
+		return
+	}
+	if !room.AutoRunoff {
+		return
+	}
+
+	nominations, err := a.store.ListNominations(roomID)
+	if err != nil {
+		log.Println("ListNominations(runoff):", err)
+		return
+	}
+
+	var created []string
+	for _, nom := range nominations {
+		if nom.Kind != domain.KindPlurality {
+			continue
+		}
+		// номинация уже ушла в следующий тур — например, комнату закрывают второй раз
+		next, err := a.store.HasNextRound(nom.ID)
+		if err != nil {
+			log.Println("HasNextRound:", err)
+			continue
+		}
+		if next {
+			continue
+		}
+
+		// ничью определяем по тем же местам, что и в /results: с голосами жюри — по взвешенному зачёту
+		rows, err := a.standings(&nom)
+		if err != nil {
+			log.Println("standings(runoff):", err)
+			continue
+		}
+		leaders := runoffLeaders(rows)
+		if leaders == nil {
+			continue
+		}
+
+		ids := make([]int64, 0, len(leaders))
+		names := make([]string, 0, len(leaders))
+		for _, r := range leaders {
+			ids = append(ids, r.ID)
+			names = append(names, r.Name)
+		}
+		name := nom.Name + " — переголосование"
+		description := fmt.Sprintf("Ничья в номинации «%s»: %s.", nom.Name, strings.Join(names, ", "))
+
+		runoffID, err := a.store.CreateNextRound(nom.ID, name, description, ids)
+		if err != nil {
+			log.Println("CreateNextRound(runoff):", err)
+			continue
+		}
+		created = append(created, fmt.Sprintf("• %s (ID %d): %s", name, runoffID, strings.Join(names, ", ")))
+
+		text := fmt.Sprintf("В номинации «%s» (комната «%s») ничья: %s.\n\n"+
+			"Создано переголосование «%s» — только между ними. "+
+			"Проголосовать можно будет, когда организаторы снова откроют голосование: /nominations",
+			nom.Name, room.Title, strings.Join(names, ", "), name)
+		a.notifyVoters(nom.RoomID, nom.ID, text)
+	}
+
+	if len(created) == 0 {
+		return
+	}
+	text := fmt.Sprintf("В комнате «%s» (ID %d) ничья на первом месте — созданы переголосования:\n%s\n\n"+
+		"Голоса в исходных номинациях заморожены. Чтобы участники могли проголосовать, открой голосование заново.",
+		room.Title, roomID, strings.Join(created, "\n"))
+	m := tgbotapi.NewMessage(room.OwnerUserID, text)
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("▶️ Открыть голосование", fmt.Sprintf("room_status:%d:%s", roomID, domain.RoomOpen)),
+		),
+	)
+	a.send(m)
+}
+
+// runoffLeaders — номинанты, поделившие первое место; nil, если победитель один или голосов не было.
+// rows — места из standings, уже по убыванию Score.
+func runoffLeaders(rows []standing) []standing {
+	if len(rows) == 0 || rows[0].Score <= 0 {
+		return nil
+	}
+	n := 1
+	for n < len(rows) && rows[n].Score == rows[0].Score {
+		n++
+	}
+	if n < 2 {
+		return nil
+	}
+	return rows[:n]
+}
+
+// notifyVoters пишет всем, кто голосовал в номинации. В votes лежат только хэши,
+// поэтому сверяем их с хэшами тех, кто входил в комнату (room_members).
+func (a *App) notifyVoters(roomID, nominationID int64, text string) {
+	hashes, err := a.store.NominationVoterHashes(nominationID)
+	if err != nil {
+		log.Println("NominationVoterHashes:", err)
+		return
+	}
+	if len(hashes) == 0 {
+		return
+	}
+	userIDs, err := a.store.RoomMemberIDs(roomID)
+	if err != nil {
+		log.Println("RoomMemberIDs:", err)
+		return
+	}
+	for _, id := range userIDs {
+		if hashes[a.hashUserID(id)] {
+			a.send(tgbotapi.NewMessage(id, text))
+		}
+	}
+}
+
+// voteRejectedReason — почему голос не принят, если err — ErrVotingClosed или ErrNextRound;
+// пустая строка — ошибка другая.
+func (a *App) voteRejectedReason(roomID int64, err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNextRound):
+		return "по этой номинации уже идёт следующий тур, голосуй в нём"
+	case errors.Is(err, storage.ErrVotingClosed):
+		status, _ := a.store.GetRoomStatus(roomID)
+		return fmt.Sprintf("сейчас голосовать нельзя (%s)", status.Title())
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,39 @@
+package app
+
+import "testing"
+
+func TestRunoffLeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rows []standing
+		want []int64
+	}{
+		{"no_nominees", nil, nil},
+		{"no_votes", []standing{{ID: 1}, {ID: 2}}, nil},
+		{"single_winner", []standing{{ID: 1, Score: 3}, {ID: 2, Score: 2}}, nil},
+		{"two_way_tie", []standing{{ID: 2, Score: 3}, {ID: 1, Score: 3}, {ID: 3, Score: 1}}, []int64{2, 1}},
+		{"three_way_tie", []standing{{ID: 1, Score: 1}, {ID: 2, Score: 1}, {ID: 3, Score: 1}}, []int64{1, 2, 3}},
+		// взвешенный зачёт жюри: по сырым голосам ничья, по местам — нет
+		{"weighted_clear_winner", []standing{{ID: 1, Score: 0.62}, {ID: 2, Score: 0.38}}, nil},
+		{"weighted_tie", []standing{{ID: 1, Score: 0.5}, {ID: 2, Score: 0.5}}, []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := runoffLeaders(tt.rows)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v want %v", got, tt.want)
+			}
+			for i, r := range got {
+				if r.ID != tt.want[i] {
+					t.Fatalf("got %v want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
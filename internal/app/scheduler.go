@@ -0,0 +1,241 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+const (
+	// schedulerInterval — как часто проверяем наступившие задачи; точность расписания ±интервал.
+	schedulerInterval = 30 * time.Second
+	scheduleLayout    = "2006-01-02 15:04"
+)
+
+// runScheduler применяет запланированные переходы статуса и закрывает матчи сетки по таймеру,
+// пока не отменён ctx.
+// Первый проход — сразу при старте, чтобы догнать задачи, пропущенные за время простоя.
+func (a *App) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		a.runDueTransitions(now)
+		a.runDueMatches(now)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *App) runDueTransitions(now time.Time) {
+	jobs, err := a.store.DueTransitions(now)
+	if err != nil {
+		log.Println("DueTransitions:", err)
+		return
+	}
+
+	for _, j := range jobs {
+		from, err := a.setRoomStatus(j.RoomID, j.To)
+
+		var result string
+		switch {
+		case err == nil:
+			result = "ok"
+		case errors.Is(err, storage.ErrInvalidTransition):
+			result = "skipped: status " + string(from)
+		case errors.Is(err, storage.ErrNotFound):
+			result = "skipped: room not found"
+		default:
+			// временная ошибка БД — попробуем на следующем тике
+			log.Println("scheduled setRoomStatus:", err)
+			continue
+		}
+
+		if err := a.store.MarkTransitionDone(j.ID, now, result); err != nil {
+			log.Println("MarkTransitionDone:", err)
+		}
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+
+		ownerID, err := a.store.GetRoomOwnerID(j.RoomID)
+		if err != nil {
+			log.Println("GetRoomOwnerID(scheduler):", err)
+			continue
+		}
+		title, _ := a.store.GetRoomTitle(j.RoomID)
+
+		var text string
+		if result == "ok" {
+			text = fmt.Sprintf("⏰ По расписанию: комната «%s» (ID %d) — %s.", title, j.RoomID, j.To.Title())
+		} else {
+			text = fmt.Sprintf("⏰ Запланированный переход в комнате «%s» (ID %d) пропущен: статус уже «%s».",
+				title, j.RoomID, from.Title())
+		}
+		a.send(tgbotapi.NewMessage(ownerID, text))
+	}
+}
+
+// parseScheduleTimes разбирает «открытие | закрытие | таймзона»; «-» — пропустить.
+// Возвращает ошибку с текстом для пользователя.
+func parseScheduleTimes(parts []string, now time.Time) (openAt, closeAt time.Time, tz string, err error) {
+	if len(parts) < 2 {
+		return time.Time{}, time.Time{}, "", errors.New("нужно указать время открытия и закрытия (или «-»)")
+	}
+
+	tz = "UTC"
+	if len(parts) >= 3 {
+		tz = parts[2]
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("неизвестная таймзона %q, пример: Europe/Moscow", tz)
+	}
+
+	parse := func(s, what string) (time.Time, error) {
+		if s == "-" {
+			return time.Time{}, nil
+		}
+		t, err := time.ParseInLocation(scheduleLayout, s, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("не понял время %s %q, формат: ГГГГ-ММ-ДД ЧЧ:ММ", what, s)
+		}
+		if !t.After(now) {
+			return time.Time{}, fmt.Errorf("время %s %q уже прошло", what, s)
+		}
+		return t, nil
+	}
+
+	if openAt, err = parse(parts[0], "открытия"); err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
+	if closeAt, err = parse(parts[1], "закрытия"); err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
+	if openAt.IsZero() && closeAt.IsZero() {
+		return time.Time{}, time.Time{}, "", errors.New("укажи хотя бы одно время (или /schedule roomID off)")
+	}
+	if !openAt.IsZero() && !closeAt.IsZero() && !closeAt.After(openAt) {
+		return time.Time{}, time.Time{}, "", errors.New("закрытие должно быть позже открытия")
+	}
+	return openAt, closeAt, tz, nil
+}
+
+// ---------- Команды ----------
+
+func (a *App) handleSchedule(msg *tgbotapi.Message) {
+	args := strings.TrimSpace(msg.CommandArguments())
+	if args == "" {
+		text := "Форматы:\n" +
+			"/schedule roomID | открытие | закрытие | таймзона – запланировать голосование\n" +
+			"/schedule roomID – показать расписание\n" +
+			"/schedule roomID off – отменить расписание\n\n" +
+			"Время — ГГГГ-ММ-ДД ЧЧ:ММ, «-» — не планировать; таймзона по умолчанию UTC.\n" +
+			"Пример:\n/schedule 1 | 2025-12-31 18:00 | 2026-01-01 12:00 | Europe/Moscow"
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	parts := splitPipeArgs(args, 4)
+	if len(parts) == 0 {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Укажи roomID: /schedule roomID"))
+		return
+	}
+	head := strings.Fields(parts[0])
+	roomID, err := strconv.ParseInt(head[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "roomID должно быть числом."))
+		return
+	}
+
+	role, err := a.store.RoomRole(roomID, msg.From.ID)
+	if err != nil {
+		log.Println("RoomRole(schedule):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Расписание могут менять только автор или админы комнаты."))
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && len(head) == 1:
+		a.sendRoomSchedule(msg.Chat.ID, roomID)
+		return
+
+	case len(parts) == 1 && len(head) == 2 && head[1] == "off":
+		if err := a.store.ReplaceRoomSchedule(roomID, nil); err != nil {
+			log.Println("ReplaceRoomSchedule(off):", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось отменить расписание."))
+			return
+		}
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Расписание комнаты отменено ✅"))
+		return
+	}
+
+	openAt, closeAt, tz, err := parseScheduleTimes(parts[1:], time.Now())
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не получилось: "+err.Error()))
+		return
+	}
+
+	var jobs []domain.ScheduledTransition
+	if !openAt.IsZero() {
+		jobs = append(jobs, domain.ScheduledTransition{RoomID: roomID, To: domain.RoomOpen, RunAt: openAt, Timezone: tz, CreatedBy: msg.From.ID})
+	}
+	if !closeAt.IsZero() {
+		jobs = append(jobs, domain.ScheduledTransition{RoomID: roomID, To: domain.RoomClosed, RunAt: closeAt, Timezone: tz, CreatedBy: msg.From.ID})
+	}
+
+	if err := a.store.ReplaceRoomSchedule(roomID, jobs); err != nil {
+		log.Println("ReplaceRoomSchedule:", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить расписание."))
+		return
+	}
+
+	a.sendRoomSchedule(msg.Chat.ID, roomID)
+}
+
+func (a *App) sendRoomSchedule(chatID, roomID int64) {
+	jobs, err := a.store.ListRoomSchedule(roomID)
+	if err != nil {
+		log.Println("ListRoomSchedule:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить расписание."))
+		return
+	}
+	if len(jobs) == 0 {
+		a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("У комнаты ID %d нет запланированных переходов.", roomID)))
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Расписание комнаты ID %d:\n", roomID)
+	for _, j := range jobs {
+		at := j.RunAt
+		if loc, err := time.LoadLocation(j.Timezone); err == nil {
+			at = at.In(loc)
+		}
+		verb := "открыть голосование"
+		if j.To == domain.RoomClosed {
+			verb = "закрыть голосование"
+		}
+		fmt.Fprintf(&sb, "• %s (%s) — %s\n", at.Format(scheduleLayout), j.Timezone, verb)
+	}
+	sb.WriteString("\nАвтор получит сообщение, когда переход выполнится.")
+	a.send(tgbotapi.NewMessage(chatID, sb.String()))
+}
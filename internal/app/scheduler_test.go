@@ -0,0 +1,70 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleTimes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name      string
+		parts     []string
+		wantOpen  time.Time
+		wantClose time.Time
+		wantTZ    string
+		wantErr   bool
+	}{
+		{
+			name:      "both_utc_default",
+			parts:     []string{"2025-06-02 10:00", "2025-06-03 18:30"},
+			wantOpen:  time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC),
+			wantClose: time.Date(2025, 6, 3, 18, 30, 0, 0, time.UTC),
+			wantTZ:    "UTC",
+		},
+		{
+			name:     "only_open_with_tz",
+			parts:    []string{"2025-06-02 10:00", "-", "Europe/Moscow"},
+			wantOpen: time.Date(2025, 6, 2, 10, 0, 0, 0, msk),
+			wantTZ:   "Europe/Moscow",
+		},
+		{
+			name:      "only_close",
+			parts:     []string{"-", "2025-06-02 10:00"},
+			wantClose: time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC),
+			wantTZ:    "UTC",
+		},
+		{name: "both_skipped", parts: []string{"-", "-"}, wantErr: true},
+		{name: "missing_close", parts: []string{"2025-06-02 10:00"}, wantErr: true},
+		{name: "bad_format", parts: []string{"02.06.2025 10:00", "-"}, wantErr: true},
+		{name: "bad_tz", parts: []string{"2025-06-02 10:00", "-", "Mars/Olympus"}, wantErr: true},
+		{name: "in_past", parts: []string{"2025-05-31 10:00", "-"}, wantErr: true},
+		{name: "close_before_open", parts: []string{"2025-06-03 10:00", "2025-06-02 10:00"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			openAt, closeAt, tz, err := parseScheduleTimes(tt.parts, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got open=%v close=%v", openAt, closeAt)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !openAt.Equal(tt.wantOpen) || !closeAt.Equal(tt.wantClose) || tz != tt.wantTZ {
+				t.Fatalf("got open=%v close=%v tz=%q, want open=%v close=%v tz=%q",
+					openAt, closeAt, tz, tt.wantOpen, tt.wantClose, tt.wantTZ)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+const (
+	minScore = 1
+	maxScore = 5
+)
+
+// ---------- Кнопки ----------
+
+// handleScoreCallback — score:<nomineeID>:<1..5>
+func (a *App) handleScoreCallback(cq *tgbotapi.CallbackQuery, sess *session.Session, data string) {
+	chatID := cq.Message.Chat.ID
+
+	idStr, scoreStr, ok := strings.Cut(strings.TrimPrefix(data, "score:"), ":")
+	if !ok {
+		return
+	}
+	nomineeID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return
+	}
+	score, err := strconv.Atoi(scoreStr)
+	if err != nil || score < minScore || score > maxScore {
+		return
+	}
+
+	nominationID, roomID, err := a.store.GetNomineeNominationAndRoom(nomineeID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(chatID, "Этот номинант больше не существует."))
+		} else {
+			log.Println("get nominee nomination/room(score):", err)
+		}
+		return
+	}
+	if sess.ActiveRoomID != roomID {
+		a.send(tgbotapi.NewMessage(chatID, "У тебя нет доступа к этой комнате. Сначала зайди в неё командой /room."))
+		return
+	}
+
+	nom, err := a.store.GetNomination(nominationID)
+	if err != nil {
+		log.Println("get nomination(score):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		return
+	}
+	if nom.Kind != domain.KindScore {
+		a.send(tgbotapi.NewMessage(chatID, "В этой номинации больше не ставят оценки — открой её заново."))
+		return
+	}
+
+	if err := a.store.RecordScore(a.hashUserID(cq.From.ID), nominationID, nomineeID, score, time.Now()); err != nil {
+		if reason := a.voteRejectedReason(roomID, err); reason != "" {
+			a.send(tgbotapi.NewMessage(chatID, "Оценка не принята: "+reason+"."))
+			return
+		}
+		if errors.Is(err, storage.ErrVoteLocked) {
+			a.send(tgbotapi.NewMessage(chatID, "В этой комнате голос окончательный — поставленную оценку уже не поменять."))
+			return
+		}
+		log.Println("RecordScore:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		return
+	}
+
+	// обновляем кнопки под карточкой, чтобы было видно выставленную оценку
+	if cq.Message.ReplyMarkup != nil {
+		kb := *cq.Message.ReplyMarkup
+		if len(kb.InlineKeyboard) > 0 {
+			kb.InlineKeyboard[0] = scoreRow(nomineeID, score)
+			a.send(tgbotapi.NewEditMessageReplyMarkup(chatID, cq.Message.MessageID, kb))
+		}
+	}
+
+	name, err := a.store.GetNomineeName(nomineeID)
+	if err != nil {
+		log.Println("get nominee name:", err)
+	}
+	if name == "" {
+		name = fmt.Sprintf("ID %d", nomineeID)
+	}
+	a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Оценка принята: %s — %s", name, strings.Repeat("⭐", score))))
+}
+
+// ---------- Утилиты ----------
+
+// scoreRow — кнопки 1..5 под карточкой номинанта; текущая оценка помечена звездой.
+func scoreRow(nomineeID int64, current int) []tgbotapi.InlineKeyboardButton {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, maxScore-minScore+1)
+	for v := minScore; v <= maxScore; v++ {
+		label := strconv.Itoa(v)
+		if v == current {
+			label = "⭐" + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("score:%d:%d", nomineeID, v)))
+	}
+	return row
+}
+
+// scoreResults — итоги номинации с оценками: среднее, число оценок и разброс.
+func (a *App) scoreResults(nominationID int64, mode domain.ResultsMode) (string, error) {
+	nominees, err := a.store.ListNominees(nominationID)
+	if err != nil {
+		return "", err
+	}
+	scores, err := a.store.NominationScores(nominationID)
+	if err != nil {
+		return "", err
+	}
+	if len(nominees) == 0 {
+		return "В этой номинации пока нет номинантов.\n", nil
+	}
+
+	candidates := make([]int64, 0, len(nominees))
+	names := make(map[int64]string, len(nominees))
+	for _, n := range nominees {
+		candidates = append(candidates, n.ID)
+		names[n.ID] = n.Name
+	}
+	return formatScores(tally.RankByScore(candidates, scores), names, mode), nil
+}
+
+func formatScores(rows []tally.ScoreRow, names map[int64]string, mode domain.ResultsMode) string {
+	var sb strings.Builder
+	sb.WriteString("Оценки от 1 до 5: среднее, число оценок, стандартное отклонение\n\n")
+
+	if mode == domain.ResultsWinners {
+		if len(rows) == 0 || rows[0].Count == 0 {
+			sb.WriteString("Оценок не было.\n")
+			return sb.String()
+		}
+		var top []string
+		for _, r := range rows {
+			if r.Count == 0 || r.Mean != rows[0].Mean {
+				break
+			}
+			top = append(top, names[r.ID])
+		}
+		if len(top) == 1 {
+			fmt.Fprintf(&sb, "🏆 Победитель: %s — средняя оценка %.2f\n", top[0], rows[0].Mean)
+		} else {
+			fmt.Fprintf(&sb, "🏆 Победители (поровну, средняя %.2f): %s\n", rows[0].Mean, strings.Join(top, ", "))
+		}
+		return sb.String()
+	}
+
+	for _, r := range rows {
+		if r.Count == 0 {
+			fmt.Fprintf(&sb, "• %s (ID %d) — оценок нет\n", names[r.ID], r.ID)
+			continue
+		}
+		fmt.Fprintf(&sb, "• %s (ID %d) — ⭐ %.2f (оценок: %d, σ = %.2f)\n", names[r.ID], r.ID, r.Mean, r.Count, r.StdDev)
+	}
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestFormatScores(t *testing.T) {
+	t.Parallel()
+
+	names := map[int64]string{1: "Alice", 2: "Bob", 3: "Carol"}
+	rows := tally.RankByScore([]int64{1, 2, 3}, map[int64][]int{1: {3, 5}, 2: {2}})
+
+	full := formatScores(rows, names, domain.ResultsFull)
+	for _, want := range []string{"Alice (ID 1) — ⭐ 4.00 (оценок: 2, σ = 1.00)", "Bob (ID 2) — ⭐ 2.00", "Carol (ID 3) — оценок нет"} {
+		if !strings.Contains(full, want) {
+			t.Fatalf("full output missing %q:\n%s", want, full)
+		}
+	}
+	if strings.Index(full, "Alice") > strings.Index(full, "Bob") {
+		t.Fatalf("rows must be sorted by mean:\n%s", full)
+	}
+
+	win := formatScores(rows, names, domain.ResultsWinners)
+	if !strings.Contains(win, "Победитель: Alice") || strings.Contains(win, "Bob") {
+		t.Fatalf("winners output should show only Alice:\n%s", win)
+	}
+}
+
+func TestScoreRow_MarksCurrent(t *testing.T) {
+	t.Parallel()
+
+	row := scoreRow(7, 3)
+	if len(row) != 5 {
+		t.Fatalf("expected 5 buttons, got %d", len(row))
+	}
+	if row[2].Text != "⭐3" || *row[2].CallbackData != "score:7:3" {
+		t.Fatalf("unexpected current button: %q %q", row[2].Text, *row[2].CallbackData)
+	}
+	if row[0].Text != "1" {
+		t.Fatalf("unexpected button: %q", row[0].Text)
+	}
+}
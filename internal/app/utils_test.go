@@ -1,6 +1,9 @@
 package app
 
-import "testing"
+import (
+	"testing"
+	"unicode/utf8"
+)
 
 func TestSplitPipeArgs(t *testing.T) {
 	t.Parallel()
@@ -83,3 +86,16 @@ func FuzzSplitPipeArgs(f *testing.F) {
 		}
 	})
 }
+
+func TestStartTexts_FitTelegramLimits(t *testing.T) {
+	t.Parallel()
+
+	// подпись к фото — не больше 1024 символов, иначе sendPhoto отклоняется и /start ничего не показывает
+	if n := utf8.RuneCountInString(startCaption); n > 1024 {
+		t.Fatalf("start caption is %d runes, Telegram allows 1024", n)
+	}
+	// обычное сообщение — не больше 4096
+	if n := utf8.RuneCountInString(commandsText); n > 4096 {
+		t.Fatalf("command list is %d runes, Telegram allows 4096", n)
+	}
+}
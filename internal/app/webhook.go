@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webhookSecretHeader — заголовок, в котором Telegram присылает secret_token из setWebhook.
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookConfig — настройки режима BOT_MODE=webhook.
+type WebhookConfig struct {
+	URL    string // публичный адрес, на который Telegram шлёт апдейты (за reverse proxy)
+	Listen string // адрес локального HTTP-сервера, например ":8080"
+	Secret string // secret_token, сверяем с заголовком X-Telegram-Bot-Api-Secret-Token
+}
+
+// RegisterWebhook вызывает setWebhook с URL и secret_token.
+// В tgbotapi v5.5.1 у WebhookConfig нет secret_token, поэтому запрос собираем руками.
+func (a *App) RegisterWebhook(cfg WebhookConfig) error {
+	params := tgbotapi.Params{"url": cfg.URL}
+	params.AddNonEmpty("secret_token", cfg.Secret)
+	_, err := a.bot.MakeRequest("setWebhook", params)
+	return err
+}
+
+// RemoveWebhook снимает вебхук — без этого getUpdates (long polling) Telegram не отдаёт.
+func (a *App) RemoveWebhook() error {
+	_, err := a.bot.Request(tgbotapi.DeleteWebhookConfig{})
+	return err
+}
+
+// RunWebhook поднимает HTTP-сервер и обрабатывает апдейты, пока не отменён ctx.
+// Апдейты обрабатываются последовательно в одной горутине — как и в Run.
+func (a *App) RunWebhook(ctx context.Context, cfg WebhookConfig) error {
+	path := "/"
+	if u, err := url.Parse(cfg.URL); err == nil && u.Path != "" {
+		path = u.Path
+	}
+
+	go a.runScheduler(ctx)
+
+	updates := make(chan tgbotapi.Update, 100)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, webhookHandler(ctx, cfg.Secret, updates))
+
+	srv := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+	log.Printf("webhook: слушаем %s%s", cfg.Listen, path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err := srv.Shutdown(shutdownCtx)
+			// на принятые апдейты Telegram уже получил 200 и повторять их не будет — дорабатываем буфер
+			drainUpdates(updates, a.handleUpdate)
+			return err
+
+		case err := <-errCh:
+			return err
+
+		case update := <-updates:
+			a.handleUpdate(update)
+		}
+	}
+}
+
+// drainUpdates обрабатывает всё, что осталось в буфере, не дожидаясь новых апдейтов.
+// Вызывать после srv.Shutdown: он дожидается активных обработчиков, так что новых записей в канал не будет.
+func drainUpdates(updates <-chan tgbotapi.Update, handle func(tgbotapi.Update)) {
+	for {
+		select {
+		case update := <-updates:
+			handle(update)
+		default:
+			return
+		}
+	}
+}
+
+func webhookHandler(ctx context.Context, secret string, updates chan<- tgbotapi.Update) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		got := r.Header.Get(webhookSecretHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		if ctx.Err() != nil {
+			// выключаемся — пусть Telegram повторит доставку позже
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-ctx.Done():
+			// выключаемся — пусть Telegram повторит доставку позже
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"update_id": 42, "message": {"message_id": 1, "text": "hi", "chat": {"id": 7}}}`
+
+	tests := []struct {
+		name       string
+		method     string
+		secret     string
+		body       string
+		wantStatus int
+		wantUpdate bool
+	}{
+		{"ok", http.MethodPost, "s3cret", body, http.StatusOK, true},
+		{"wrong_secret", http.MethodPost, "nope", body, http.StatusForbidden, false},
+		{"no_secret", http.MethodPost, "", body, http.StatusForbidden, false},
+		{"get", http.MethodGet, "s3cret", "", http.StatusMethodNotAllowed, false},
+		{"bad_json", http.MethodPost, "s3cret", "{", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updates := make(chan tgbotapi.Update, 1)
+			h := webhookHandler(context.Background(), "s3cret", updates)
+
+			req := httptest.NewRequest(tt.method, "/hook", strings.NewReader(tt.body))
+			if tt.secret != "" {
+				req.Header.Set(webhookSecretHeader, tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status: got=%d want=%d", rec.Code, tt.wantStatus)
+			}
+
+			select {
+			case u := <-updates:
+				if !tt.wantUpdate {
+					t.Fatalf("unexpected update: %+v", u)
+				}
+				if u.UpdateID != 42 || u.Message == nil || u.Message.Text != "hi" {
+					t.Fatalf("unexpected update: %+v", u)
+				}
+			default:
+				if tt.wantUpdate {
+					t.Fatalf("expected update to be delivered")
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_ShuttingDown(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// небуферизованный канал, который никто не читает
+	h := webhookHandler(ctx, "s", make(chan tgbotapi.Update))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"update_id": 1}`))
+	req.Header.Set(webhookSecretHeader, "s")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status: got=%d want=%d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestDrainUpdates(t *testing.T) {
+	t.Parallel()
+
+	updates := make(chan tgbotapi.Update, 3)
+	for i := 1; i <= 3; i++ {
+		updates <- tgbotapi.Update{UpdateID: i}
+	}
+
+	var got []int
+	drainUpdates(updates, func(u tgbotapi.Update) { got = append(got, u.UpdateID) })
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Fatalf("drained %v, want [1 2 3]", got)
+	}
+	// пустой буфер — возвращается сразу, не блокируясь
+	drainUpdates(updates, func(u tgbotapi.Update) { t.Fatalf("unexpected update %d", u.UpdateID) })
+}
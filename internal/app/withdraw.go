@@ -0,0 +1,151 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+// ---------- Команды ----------
+
+// handleWithdrawAfterClose — /withdraw_after_close roomID on|off
+func (a *App) handleWithdrawAfterClose(msg *tgbotapi.Message) {
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+		text := "Формат: /withdraw_after_close roomID on|off\n\n" +
+			"Пока голосование открыто, участник всегда может отозвать свой голос.\n" +
+			"on — отзывать можно и после закрытия голосования, пока итоги не опубликованы\n" +
+			"off — после закрытия голоса окончательные (по умолчанию)."
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+		return
+	}
+
+	roomID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "roomID должно быть числом."))
+		return
+	}
+	on := args[1] == "on"
+
+	role, err := a.store.RoomRole(roomID, msg.From.ID)
+	if err != nil {
+		log.Println("RoomRole(withdraw_after_close):", err)
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка проверки прав."))
+		return
+	}
+	if !role.CanManage() {
+		a.send(tgbotapi.NewMessage(msg.Chat.ID, "Настраивать отзыв голосов могут только автор или админы комнаты."))
+		return
+	}
+
+	if err := a.store.SetWithdrawAfterClose(roomID, on); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Комната не найдена."))
+		} else {
+			log.Println("SetWithdrawAfterClose:", err)
+			a.send(tgbotapi.NewMessage(msg.Chat.ID, "Не удалось сохранить настройку."))
+		}
+		return
+	}
+
+	text := fmt.Sprintf("Готово ✅ В комнате ID %d после закрытия голосования голоса отозвать нельзя.", roomID)
+	if on {
+		text = fmt.Sprintf("Готово ✅ В комнате ID %d голос можно отозвать и после закрытия голосования, пока итоги не опубликованы.", roomID)
+	}
+	a.send(tgbotapi.NewMessage(msg.Chat.ID, text))
+}
+
+// ---------- Кнопки ----------
+
+// handleUnvoteCallback — unvote:<nomineeID>
+func (a *App) handleUnvoteCallback(cq *tgbotapi.CallbackQuery, sess *session.Session, data string) {
+	chatID := cq.Message.Chat.ID
+
+	nomineeID, err := strconv.ParseInt(strings.TrimPrefix(data, "unvote:"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	nominationID, roomID, err := a.store.GetNomineeNominationAndRoom(nomineeID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			a.send(tgbotapi.NewMessage(chatID, "Этот номинант больше не существует."))
+		} else {
+			log.Println("get nominee nomination/room(unvote):", err)
+		}
+		return
+	}
+	if sess.ActiveRoomID != roomID {
+		a.send(tgbotapi.NewMessage(chatID, "У тебя нет доступа к этой комнате. Сначала зайди в неё командой /room."))
+		return
+	}
+
+	if err := a.store.WithdrawVote(a.hashUserID(cq.From.ID), nominationID, nomineeID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNoVote):
+			a.send(tgbotapi.NewMessage(chatID, "Твоего голоса за этого номинанта нет — открой номинацию заново."))
+		case errors.Is(err, storage.ErrVoteLocked):
+			a.send(tgbotapi.NewMessage(chatID, "В этой комнате голос окончательный — отозвать его нельзя."))
+		case errors.Is(err, storage.ErrWithdrawLocked):
+			a.send(tgbotapi.NewMessage(chatID, "Голосование закрыто — в этой комнате голос уже не отозвать."))
+		default:
+			if reason := a.voteRejectedReason(roomID, err); reason != "" {
+				a.send(tgbotapi.NewMessage(chatID, "Голос не отозван: "+reason+"."))
+				return
+			}
+			log.Println("WithdrawVote:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+		}
+		return
+	}
+
+	name := fmt.Sprintf("ID %d", nomineeID)
+	if n, err := a.findNominee(nominationID, nomineeID); err != nil {
+		log.Println("findNominee(unvote):", err)
+	} else {
+		name = n.Name
+		a.refreshWithdrawnCard(cq.Message, *n, roomID)
+	}
+	a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Голос за %s отозван ↩️ В этой номинации у тебя сейчас нет голоса.", name)))
+}
+
+// ---------- Утилиты ----------
+
+// refreshWithdrawnCard перерисовывает карточку номинанта после отзыва голоса (см. withdrawnCard).
+func (a *App) refreshWithdrawnCard(card *tgbotapi.Message, n domain.Nominee, roomID int64) {
+	final := false
+	if room, err := a.store.GetRoom(roomID); err != nil {
+		log.Println("GetRoom(unvote):", err)
+	} else {
+		final = !room.AllowRevote
+	}
+	caption, kb := withdrawnCard(n, card.ReplyMarkup, final)
+	a.editNomineeCard(card, caption, kb)
+}
+
+// withdrawnCard — подпись и кнопки карточки после отзыва голоса: без пометки «твой выбор»
+// и с кнопкой голосования вместо «отозвать». Остальные ряды кнопок (медиа, назад) остаются.
+func withdrawnCard(n domain.Nominee, current *tgbotapi.InlineKeyboardMarkup, final bool) (string, tgbotapi.InlineKeyboardMarkup) {
+	row, hint := pluralityRow(n.ID, false, false, final)
+
+	rows := [][]tgbotapi.InlineKeyboardButton{row}
+	if current != nil && len(current.InlineKeyboard) > 0 {
+		rows = append(rows, current.InlineKeyboard[1:]...)
+	}
+	return nomineeCaption(n, hint, false), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// voteRow — кнопка «Голосовать» под карточкой номинанта.
+func voteRow(nomineeID int64) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Голосовать", fmt.Sprintf("vote:%d", nomineeID)),
+	)
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestVoteRow(t *testing.T) {
+	t.Parallel()
+
+	row := voteRow(42)
+	if len(row) != 1 || row[0].Text != "✅ Голосовать" || *row[0].CallbackData != "vote:42" {
+		t.Fatalf("unexpected row: %+v", row)
+	}
+}
+
+func TestWithdrawnCard(t *testing.T) {
+	t.Parallel()
+
+	n := domain.Nominee{ID: 42, Name: "Вася"}
+	chosenRow, chosenHint := pluralityRow(n.ID, true, true, false)
+	current := tgbotapi.NewInlineKeyboardMarkup(
+		chosenRow,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к номинациям", "back:nominations")),
+	)
+	if !strings.Contains(nomineeCaption(n, chosenHint, true), choiceBadge) {
+		t.Fatalf("chosen card must carry the badge")
+	}
+
+	caption, kb := withdrawnCard(n, &current, false)
+	if strings.Contains(caption, choiceBadge) || strings.Contains(caption, "отозвать") {
+		t.Fatalf("caption still shows the withdrawn vote: %q", caption)
+	}
+	if !strings.Contains(caption, "ID 42 — Вася") {
+		t.Fatalf("caption lost the nominee: %q", caption)
+	}
+	if len(kb.InlineKeyboard) != 2 || *kb.InlineKeyboard[0][0].CallbackData != "vote:42" || len(kb.InlineKeyboard[0]) != 1 {
+		t.Fatalf("unexpected keyboard: %+v", kb.InlineKeyboard)
+	}
+	if *kb.InlineKeyboard[1][0].CallbackData != "back:nominations" {
+		t.Fatalf("other rows must stay: %+v", kb.InlineKeyboard)
+	}
+
+	if _, kb := withdrawnCard(n, nil, false); len(kb.InlineKeyboard) != 1 {
+		t.Fatalf("card without buttons: %+v", kb.InlineKeyboard)
+	}
+}
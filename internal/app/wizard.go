@@ -0,0 +1,552 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+	"github.com/maaaruch/tg-vote-bot/internal/storage"
+)
+
+// Мастер «🧭 Голосовать во всём» проходит по всем номинациям комнаты по порядку.
+// В номинациях с выбором одного выбор копится черновиком (ballot_drafts) и становится голосом
+// только после подтверждения на экране проверки. Остальные режимы (отметки, оценки, ранжирование,
+// пары, сетка, референдум) мастер открывает их обычным экраном: голос там записывается сразу,
+// а шаг закрывают кнопки «дальше» или «пропустить». Состояние мастера целиком в черновиках
+// и в данных кнопок, без сессии.
+//
+// Режим шага в данных кнопок: "n" — после выбора перейти к следующей номинации,
+// "r" — вернуться к экрану проверки (номинацию открыли оттуда, чтобы поменять выбор).
+const (
+	wizardNext   = "n"
+	wizardReview = "r"
+)
+
+// wizardStep — номинация в мастере и выбор пользователя в ней.
+type wizardStep struct {
+	Nomination domain.Nomination
+	Nominees   []domain.Nominee
+	Current    int64 // голос, уже записанный в номинации; 0 — голоса нет
+	Draft      int64 // выбор в мастере; 0 — не выбирал(а) или пропустил(а)
+	Locked     bool  // голос окончательный (allow_revote выключен) — мастер номинацию не открывает
+	Voted      bool  // в номинации другого режима уже есть голос
+}
+
+// direct — номинация другого режима: мастер открывает её обычный экран, черновика нет.
+func (st wizardStep) direct() bool {
+	return st.Nomination.Kind != domain.KindPlurality || st.Nomination.MaxChoices > 1
+}
+
+// changed — в мастере выбран другой номинант, чем записанный голос.
+func (st wizardStep) changed() bool {
+	return !st.Locked && st.Draft != 0 && st.Draft != st.Current
+}
+
+// ---------- Кнопки ----------
+
+// handleWizardCallback — wiz:start, wiz:show:<nominationID>, wiz:pick:<nominationID>:<nomineeID>:<n|r>,
+// wiz:next:<nominationID>:<n|r>, wiz:skip:<nominationID>:<n|r>, wiz:review, wiz:confirm, wiz:cancel
+func (a *App) handleWizardCallback(cq *tgbotapi.CallbackQuery, sess *session.Session, data string) {
+	chatID := cq.Message.Chat.ID
+	userID := cq.From.ID
+	roomID := sess.ActiveRoomID
+	if roomID == 0 {
+		a.send(tgbotapi.NewMessage(chatID, "Сначала зайди в комнату: /room ID Пароль"))
+		return
+	}
+	userHash := a.hashUserID(userID)
+
+	parts := strings.Split(strings.TrimPrefix(data, "wiz:"), ":")
+	ids := make([]int64, 0, 2)
+	for _, p := range parts[1:] {
+		if p == wizardNext || p == wizardReview {
+			break
+		}
+		id, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return
+		}
+		ids = append(ids, id)
+	}
+	mode := parts[len(parts)-1]
+
+	// номинация из кнопки должна быть в активной комнате
+	if len(ids) > 0 {
+		ok, err := a.store.CheckNominationInRoom(ids[0], roomID)
+		if err != nil {
+			log.Println("CheckNominationInRoom(wizard):", err)
+			a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+			return
+		}
+		if !ok {
+			a.send(tgbotapi.NewMessage(chatID, "У тебя нет доступа к этой комнате. Сначала зайди в неё командой /room."))
+			return
+		}
+	}
+
+	switch {
+	case parts[0] == "start":
+		status, err := a.store.GetRoomStatus(roomID)
+		if err != nil {
+			log.Println("GetRoomStatus(wizard):", err)
+			a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+			return
+		}
+		if status != domain.RoomOpen {
+			a.send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Сейчас голосовать нельзя (%s).", status.Title())))
+			return
+		}
+		if err := a.store.ClearBallotDrafts(userHash, roomID); err != nil {
+			log.Println("ClearBallotDrafts(start):", err)
+		}
+		a.sendNextWizardStep(chatID, userID, roomID, 0)
+
+	case parts[0] == "show" && len(ids) == 1:
+		a.sendWizardStepFor(chatID, userID, roomID, ids[0])
+
+	case parts[0] == "pick" && len(ids) == 2:
+		nominationID, nomineeID := ids[0], ids[1]
+		if err := a.store.SetBallotDraft(userHash, nominationID, nomineeID); err != nil {
+			if reason := a.voteRejectedReason(roomID, err); reason != "" {
+				a.send(tgbotapi.NewMessage(chatID, "Выбор не принят: "+reason+"."))
+				return
+			}
+			if errors.Is(err, storage.ErrNomineeMismatch) {
+				a.send(tgbotapi.NewMessage(chatID, "Этого номинанта уже удалили — открой номинацию заново."))
+				return
+			}
+			log.Println("SetBallotDraft:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+			return
+		}
+		name, err := a.store.GetNomineeName(nomineeID)
+		if err != nil {
+			log.Println("get nominee name(wizard):", err)
+		}
+		a.closeWizardStep(cq, nominationID, fmt.Sprintf("выбран(а) %s ✅", name))
+		a.continueWizard(chatID, userID, roomID, nominationID, mode)
+
+	case parts[0] == "next" && len(ids) == 1:
+		a.closeWizardStep(cq, ids[0], "готово ➡️")
+		a.continueWizard(chatID, userID, roomID, ids[0], mode)
+
+	case parts[0] == "skip" && len(ids) == 1:
+		if err := a.store.DeleteBallotDraft(userHash, ids[0]); err != nil {
+			log.Println("DeleteBallotDraft:", err)
+			a.send(tgbotapi.NewMessage(chatID, "Что-то пошло не так, попробуй ещё раз."))
+			return
+		}
+		a.closeWizardStep(cq, ids[0], "пропущено ⏭")
+		a.continueWizard(chatID, userID, roomID, ids[0], mode)
+
+	case parts[0] == "review":
+		a.sendWizardReview(chatID, userID, roomID)
+
+	case parts[0] == "confirm":
+		a.send(tgbotapi.NewEditMessageText(chatID, cq.Message.MessageID, cq.Message.Text))
+		a.submitWizard(chatID, userID, roomID)
+
+	case parts[0] == "cancel":
+		if err := a.store.ClearBallotDrafts(userHash, roomID); err != nil {
+			log.Println("ClearBallotDrafts(cancel):", err)
+		}
+		a.send(tgbotapi.NewEditMessageText(chatID, cq.Message.MessageID, "Мастер закрыт. Неподтверждённый выбор в нём не сохранён, голоса на экранах номинаций остаются."))
+		if err := a.sendNominationsList(chatID, userID, roomID); err != nil {
+			log.Println("sendNominationsList(wizard cancel):", err)
+		}
+	}
+}
+
+// ---------- Утилиты ----------
+
+// wizardSteps — номинации комнаты, которые проходит мастер, и сколько номинаций он пропускает:
+// без номинантов или с уже идущим следующим туром.
+func (a *App) wizardSteps(userID, roomID int64) ([]wizardStep, int, error) {
+	nominations, err := a.store.ListNominations(roomID)
+	if err != nil {
+		return nil, 0, err
+	}
+	room, err := a.store.GetRoom(roomID)
+	if err != nil {
+		return nil, 0, err
+	}
+	userHash := a.hashUserID(userID)
+	drafts, err := a.store.BallotDrafts(userHash, roomID)
+	if err != nil {
+		return nil, 0, err
+	}
+	voted, err := a.store.VotedNominations(userHash, roomID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	frozen := make(map[int64]bool)
+	for _, nom := range nominations {
+		if nom.ParentID != 0 {
+			frozen[nom.ParentID] = true
+		}
+	}
+
+	var steps []wizardStep
+	others := 0
+	for _, nom := range nominations {
+		if frozen[nom.ID] {
+			others++
+			continue
+		}
+		nominees, err := a.store.ListNominees(nom.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		// в референдуме номинантов нет — голосуют за саму номинацию
+		if len(nominees) == 0 && nom.Kind != domain.KindReferendum {
+			others++
+			continue
+		}
+		st := wizardStep{Nomination: nom, Nominees: nominees}
+		if st.direct() {
+			st.Voted = voted[nom.ID]
+			steps = append(steps, st)
+			continue
+		}
+		current, err := a.store.UserNomineeVotes(userHash, nom.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		st.Draft = drafts[nom.ID]
+		for id := range current {
+			st.Current = id
+		}
+		st.Locked = !room.AllowRevote && st.Current != 0
+		steps = append(steps, st)
+	}
+	return steps, others, nil
+}
+
+// nextWizardStep — индекс первой открытой для выбора номинации после afterID (0 — с начала); -1 — дальше нет.
+func nextWizardStep(steps []wizardStep, afterID int64) int {
+	start := 0
+	if afterID != 0 {
+		for i, st := range steps {
+			if st.Nomination.ID == afterID {
+				start = i + 1
+				break
+			}
+		}
+	}
+	for i := start; i < len(steps); i++ {
+		if !steps[i].Locked {
+			return i
+		}
+	}
+	return -1
+}
+
+// wizardPosition — номер шага среди открытых для выбора номинаций и их число.
+func wizardPosition(steps []wizardStep, idx int) (int, int) {
+	pos, total := 0, 0
+	for i, st := range steps {
+		if st.Locked {
+			continue
+		}
+		total++
+		if i <= idx {
+			pos = total
+		}
+	}
+	return pos, total
+}
+
+// continueWizard — после выбора или пропуска: следующая номинация или экран проверки.
+func (a *App) continueWizard(chatID, userID, roomID, nominationID int64, mode string) {
+	if mode == wizardReview {
+		a.sendWizardReview(chatID, userID, roomID)
+		return
+	}
+	a.sendNextWizardStep(chatID, userID, roomID, nominationID)
+}
+
+func (a *App) sendNextWizardStep(chatID, userID, roomID, afterID int64) {
+	steps, _, err := a.wizardSteps(userID, roomID)
+	if err != nil {
+		log.Println("wizardSteps:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить номинации 😔"))
+		return
+	}
+	if len(steps) == 0 {
+		a.send(tgbotapi.NewMessage(chatID, "В этой комнате пока нет номинаций, в которых можно голосовать."))
+		return
+	}
+	idx := nextWizardStep(steps, afterID)
+	if idx < 0 {
+		a.sendWizardReview(chatID, userID, roomID)
+		return
+	}
+	a.sendWizardStep(chatID, userID, steps, idx, wizardNext)
+}
+
+// sendWizardStepFor — номинация, открытая с экрана проверки, чтобы поменять выбор.
+func (a *App) sendWizardStepFor(chatID, userID, roomID, nominationID int64) {
+	steps, _, err := a.wizardSteps(userID, roomID)
+	if err != nil {
+		log.Println("wizardSteps:", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить номинации 😔"))
+		return
+	}
+	for i, st := range steps {
+		if st.Nomination.ID != nominationID {
+			continue
+		}
+		if st.Locked {
+			a.send(tgbotapi.NewMessage(chatID, "В этой номинации голос уже окончательный."))
+			return
+		}
+		a.sendWizardStep(chatID, userID, steps, i, wizardReview)
+		return
+	}
+	a.send(tgbotapi.NewMessage(chatID, "Эту номинацию мастер не проходит — открой её из списка /nominations."))
+}
+
+// sendWizardStep — карточки номинантов и сообщение с кнопками выбора; в номинации другого режима —
+// её обычный экран и сообщение с кнопками «дальше» и «пропустить».
+func (a *App) sendWizardStep(chatID, userID int64, steps []wizardStep, idx int, mode string) {
+	st := steps[idx]
+	if st.direct() {
+		if err := a.sendNominees(chatID, userID, st.Nomination.ID); err != nil {
+			log.Println("sendNominees(wizard):", err)
+		}
+	} else {
+		for _, n := range st.Nominees {
+			caption := fmt.Sprintf("ID %d — %s", n.ID, n.Name)
+			if n.ID == st.Current {
+				caption = choiceBadge + "\n" + caption
+			}
+			a.sendNomineeCard(chatID, n, caption, nil)
+		}
+	}
+
+	pos, total := wizardPosition(steps, idx)
+	m := tgbotapi.NewMessage(chatID, wizardStepText(st, pos, total))
+	m.ReplyMarkup = wizardStepKeyboard(st, mode)
+	a.send(m)
+}
+
+// closeWizardStep убирает кнопки у пройденного шага, чтобы по нему не нажимали повторно.
+func (a *App) closeWizardStep(cq *tgbotapi.CallbackQuery, nominationID int64, outcome string) {
+	name, err := a.store.GetNominationName(nominationID)
+	if err != nil {
+		log.Println("GetNominationName(wizard):", err)
+	}
+	a.send(tgbotapi.NewEditMessageText(cq.Message.Chat.ID, cq.Message.MessageID, fmt.Sprintf("%s: %s", name, outcome)))
+}
+
+func wizardStepText(st wizardStep, pos, total int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🧭 Шаг %d из %d · %s\n", pos, total, st.Nomination.Name)
+	if st.Nomination.Description != "" {
+		fmt.Fprintf(&sb, "\n%s\n", st.Nomination.Description)
+	}
+	sb.WriteString("\n")
+	if st.direct() {
+		fmt.Fprintf(&sb, "Режим: %s. Голосуй на экране номинации выше — голос записывается сразу, без подтверждения.\n", nominationModeTitle(st.Nomination))
+		if st.Voted {
+			sb.WriteString("Твой голос здесь уже есть.\n")
+		}
+		sb.WriteString("Когда закончишь, жми «Дальше».")
+		return sb.String()
+	}
+	if st.Current != 0 {
+		fmt.Fprintf(&sb, "Твой голос сейчас: %s.\n", wizardNomineeName(st, st.Current))
+	}
+	if st.Draft != 0 {
+		fmt.Fprintf(&sb, "В мастере выбран(а): %s.\n", wizardNomineeName(st, st.Draft))
+	}
+	sb.WriteString("Выбор станет голосом, только когда ты подтвердишь его на последнем шаге.")
+	return sb.String()
+}
+
+// wizardStepKeyboard — по кнопке на номинанта (👉 — выбор в мастере, ✅ — записанный голос),
+// «пропустить», «к проверке» и «выйти». В номинации другого режима вместо номинантов — «дальше».
+func wizardStepKeyboard(st wizardStep, mode string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if st.direct() {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➡️ Дальше", fmt.Sprintf("wiz:next:%d:%s", st.Nomination.ID, mode)),
+		))
+	} else {
+		for _, n := range st.Nominees {
+			label := n.Name
+			switch {
+			case n.ID == st.Draft:
+				label = "👉 " + label
+			case n.ID == st.Current && st.Draft == 0:
+				label = "✅ " + label
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("wiz:pick:%d:%d:%s", st.Nomination.ID, n.ID, mode)),
+			))
+		}
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏭ Пропустить", fmt.Sprintf("wiz:skip:%d:%s", st.Nomination.ID, mode)),
+			tgbotapi.NewInlineKeyboardButtonData("📋 К проверке", "wiz:review"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏹ Выйти без сохранения", "wiz:cancel"),
+		),
+	)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func wizardNomineeName(st wizardStep, id int64) string {
+	for _, n := range st.Nominees {
+		if n.ID == id {
+			return n.Name
+		}
+	}
+	return fmt.Sprintf("ID %d", id)
+}
+
+// ---------- Проверка и подтверждение ----------
+
+func (a *App) sendWizardReview(chatID, userID, roomID int64) {
+	steps, others, err := a.wizardSteps(userID, roomID)
+	if err != nil {
+		log.Println("wizardSteps(review):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить номинации 😔"))
+		return
+	}
+	m := tgbotapi.NewMessage(chatID, formatWizardReview(steps, others))
+	m.ReplyMarkup = wizardReviewKeyboard(steps)
+	a.send(m)
+}
+
+func formatWizardReview(steps []wizardStep, others int) string {
+	changes := 0
+	var sb strings.Builder
+	sb.WriteString("📋 Проверь выбор\n\n")
+	for _, st := range steps {
+		name := st.Nomination.Name
+		switch {
+		case st.direct() && st.Voted:
+			fmt.Fprintf(&sb, "✅ %s — голос записан (%s)\n", name, nominationModeTitle(st.Nomination))
+		case st.direct():
+			fmt.Fprintf(&sb, "▫️ %s — не голосовал(а) (%s)\n", name, nominationModeTitle(st.Nomination))
+		case st.Locked:
+			fmt.Fprintf(&sb, "🔒 %s — %s (голос окончательный)\n", name, wizardNomineeName(st, st.Current))
+		case st.changed() && st.Current != 0:
+			changes++
+			fmt.Fprintf(&sb, "🆕 %s — %s (сейчас: %s)\n", name, wizardNomineeName(st, st.Draft), wizardNomineeName(st, st.Current))
+		case st.changed():
+			changes++
+			fmt.Fprintf(&sb, "🆕 %s — %s\n", name, wizardNomineeName(st, st.Draft))
+		case st.Current != 0:
+			fmt.Fprintf(&sb, "✅ %s — %s (без изменений)\n", name, wizardNomineeName(st, st.Current))
+		default:
+			fmt.Fprintf(&sb, "▫️ %s — пропущено\n", name)
+		}
+	}
+	if others > 0 {
+		fmt.Fprintf(&sb, "\nЕщё номинаций без номинантов или со следующим туром: %d — мастер их не открывает.\n", others)
+	}
+	if slices.ContainsFunc(steps, wizardStep.direct) {
+		sb.WriteString("\nВ номинациях других режимов голос записан сразу на их экране — подтверждать его не нужно.\n")
+	}
+	if changes == 0 {
+		sb.WriteString("\nНовых голосов нет — подтверждать нечего.")
+	} else {
+		fmt.Fprintf(&sb, "\nНовых голосов: %d. Пока ты их не подтвердишь, они не записаны.", changes)
+	}
+	return sb.String()
+}
+
+// wizardReviewKeyboard — «подтвердить» (если есть что), «изменить» у каждой номинации и «выйти».
+func wizardReviewKeyboard(steps []wizardStep) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	changes := 0
+	for _, st := range steps {
+		if st.changed() {
+			changes++
+		}
+	}
+	if changes > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ Подтвердить (%d)", changes), "wiz:confirm"),
+		))
+	}
+	for _, st := range steps {
+		if st.Locked {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ "+st.Nomination.Name, fmt.Sprintf("wiz:show:%d", st.Nomination.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⏹ Выйти без сохранения", "wiz:cancel"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// submitWizard записывает выбор мастера голосами. Каждая номинация пишется отдельно через RecordVote,
+// со всеми его проверками: закрытая или ушедшая в следующий тур номинация не мешает остальным.
+func (a *App) submitWizard(chatID, userID, roomID int64) {
+	steps, _, err := a.wizardSteps(userID, roomID)
+	if err != nil {
+		log.Println("wizardSteps(confirm):", err)
+		a.send(tgbotapi.NewMessage(chatID, "Не удалось получить номинации 😔"))
+		return
+	}
+
+	userHash := a.hashUserID(userID)
+	now := time.Now()
+	recorded := 0
+	var failed []string
+	for _, st := range steps {
+		if !st.changed() {
+			continue
+		}
+		err := a.store.RecordVote(userHash, st.Nomination.ID, st.Draft, now)
+		if err == nil {
+			recorded++
+			continue
+		}
+		reason := a.voteRejectedReason(roomID, err)
+		switch {
+		case reason != "":
+		case errors.Is(err, storage.ErrVoteLocked):
+			reason = "голос в этой номинации уже окончательный"
+		default:
+			log.Println("RecordVote(wizard):", err)
+			reason = "не удалось сохранить"
+		}
+		failed = append(failed, fmt.Sprintf("• %s — %s", st.Nomination.Name, reason))
+	}
+	if err := a.store.ClearBallotDrafts(userHash, roomID); err != nil {
+		log.Println("ClearBallotDrafts(confirm):", err)
+	}
+
+	text := fmt.Sprintf("Готово ✅ Записано голосов: %d.", recorded)
+	if len(failed) > 0 {
+		text += "\n\nНе записаны:\n" + strings.Join(failed, "\n")
+	}
+	a.send(tgbotapi.NewMessage(chatID, text))
+
+	votes, err := a.myVotes(userID, roomID)
+	if err != nil {
+		log.Println("myVotes(wizard):", err)
+		return
+	}
+	m := tgbotapi.NewMessage(chatID, formatMyVotes("", votes))
+	m.ReplyMarkup = myVotesKeyboard(votes)
+	a.send(m)
+}
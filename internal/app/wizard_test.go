@@ -0,0 +1,160 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func wizardTestSteps() []wizardStep {
+	nominees := []domain.Nominee{{ID: 11, Name: "Вася"}, {ID: 12, Name: "Петя"}}
+	single := domain.KindPlurality
+	return []wizardStep{
+		{Nomination: domain.Nomination{ID: 1, Name: "Код", Kind: single, MaxChoices: 1}, Nominees: nominees, Draft: 11},
+		{Nomination: domain.Nomination{ID: 2, Name: "Дизайн", Kind: single, MaxChoices: 1}, Nominees: nominees, Current: 12, Locked: true},
+		{Nomination: domain.Nomination{ID: 3, Name: "Тесты", Kind: single, MaxChoices: 1}, Nominees: nominees, Current: 11, Draft: 12},
+		{Nomination: domain.Nomination{ID: 4, Name: "Доки", Kind: single, MaxChoices: 1}, Nominees: nominees, Current: 12},
+		{Nomination: domain.Nomination{ID: 5, Name: "Ревью", Kind: single, MaxChoices: 1}, Nominees: nominees},
+		{Nomination: domain.Nomination{ID: 6, Name: "Рейтинг", Kind: domain.KindScore}, Nominees: nominees, Voted: true},
+		{Nomination: domain.Nomination{ID: 7, Name: "Бюджет", Kind: domain.KindReferendum}},
+	}
+}
+
+func TestNextWizardStep(t *testing.T) {
+	t.Parallel()
+
+	steps := wizardTestSteps()
+	tests := []struct {
+		name    string
+		afterID int64
+		want    int
+	}{
+		{name: "from the start", afterID: 0, want: 0},
+		{name: "locked nomination is skipped", afterID: 1, want: 2},
+		{name: "middle", afterID: 3, want: 3},
+		{name: "other kinds are steps too", afterID: 5, want: 5},
+		{name: "after the last", afterID: 7, want: -1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := nextWizardStep(steps, tt.afterID); got != tt.want {
+				t.Fatalf("nextWizardStep(%d) = %d, want %d", tt.afterID, got, tt.want)
+			}
+		})
+	}
+
+	if pos, total := wizardPosition(steps, 2); pos != 2 || total != 6 {
+		t.Fatalf("wizardPosition = %d из %d, want 2 из 6", pos, total)
+	}
+}
+
+func TestWizardStepDirect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		nom  domain.Nomination
+		want bool
+	}{
+		{name: "single choice", nom: domain.Nomination{Kind: domain.KindPlurality, MaxChoices: 1}, want: false},
+		{name: "approval", nom: domain.Nomination{Kind: domain.KindPlurality, MaxChoices: 3}, want: true},
+		{name: "ranked", nom: domain.Nomination{Kind: domain.KindRanked, MaxChoices: 1}, want: true},
+		{name: "score", nom: domain.Nomination{Kind: domain.KindScore, MaxChoices: 1}, want: true},
+		{name: "pairwise", nom: domain.Nomination{Kind: domain.KindPairwise, MaxChoices: 1}, want: true},
+		{name: "bracket", nom: domain.Nomination{Kind: domain.KindBracket, MaxChoices: 1}, want: true},
+		{name: "referendum", nom: domain.Nomination{Kind: domain.KindReferendum, MaxChoices: 1}, want: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := (wizardStep{Nomination: tt.nom}).direct(); got != tt.want {
+				t.Fatalf("direct(%+v) = %v, want %v", tt.nom, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatWizardReview(t *testing.T) {
+	t.Parallel()
+
+	got := formatWizardReview(wizardTestSteps(), 2)
+	for _, want := range []string{
+		"🆕 Код — Вася\n",
+		"🔒 Дизайн — Петя (голос окончательный)",
+		"🆕 Тесты — Петя (сейчас: Вася)",
+		"✅ Доки — Петя (без изменений)",
+		"▫️ Ревью — пропущено",
+		"✅ Рейтинг — голос записан",
+		"▫️ Бюджет — не голосовал(а)",
+		"со следующим туром: 2",
+		"подтверждать его не нужно",
+		"Новых голосов: 2.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+
+	none := formatWizardReview(wizardTestSteps()[3:5], 0)
+	if !strings.Contains(none, "подтверждать нечего") || strings.Contains(none, "следующим туром") ||
+		strings.Contains(none, "других режимов") {
+		t.Fatalf("unexpected review without changes:\n%s", none)
+	}
+}
+
+func TestWizardReviewKeyboard(t *testing.T) {
+	t.Parallel()
+
+	kb := wizardReviewKeyboard(wizardTestSteps())
+	var got []string
+	for _, row := range kb.InlineKeyboard {
+		got = append(got, *row[0].CallbackData)
+	}
+	// окончательный голос менять нельзя — у «Дизайна» нет кнопки
+	want := "wiz:confirm wiz:show:1 wiz:show:3 wiz:show:4 wiz:show:5 wiz:show:6 wiz:show:7 wiz:cancel"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("callbacks = %v, want %s", got, want)
+	}
+	if kb.InlineKeyboard[0][0].Text != "✅ Подтвердить (2)" {
+		t.Fatalf("unexpected confirm label %q", kb.InlineKeyboard[0][0].Text)
+	}
+
+	noChanges := wizardReviewKeyboard(wizardTestSteps()[3:])
+	if *noChanges.InlineKeyboard[0][0].CallbackData == "wiz:confirm" {
+		t.Fatalf("nothing to confirm, but the confirm button is shown")
+	}
+}
+
+func TestWizardStepKeyboard(t *testing.T) {
+	t.Parallel()
+
+	steps := wizardTestSteps()
+	kb := wizardStepKeyboard(steps[2], wizardReview)
+	if kb.InlineKeyboard[0][0].Text != "Вася" || kb.InlineKeyboard[1][0].Text != "👉 Петя" {
+		t.Fatalf("draft must be marked over the current vote: %q, %q", kb.InlineKeyboard[0][0].Text, kb.InlineKeyboard[1][0].Text)
+	}
+	if got := *kb.InlineKeyboard[1][0].CallbackData; got != "wiz:pick:3:12:r" {
+		t.Fatalf("unexpected pick callback %q", got)
+	}
+	if got := *kb.InlineKeyboard[2][0].CallbackData; got != "wiz:skip:3:r" {
+		t.Fatalf("unexpected skip callback %q", got)
+	}
+
+	current := wizardStepKeyboard(steps[3], wizardNext)
+	if current.InlineKeyboard[1][0].Text != "✅ Петя" {
+		t.Fatalf("current vote must be marked: %q", current.InlineKeyboard[1][0].Text)
+	}
+
+	// в номинации другого режима голосуют на её экране — в шаге только «дальше» и «пропустить»
+	direct := wizardStepKeyboard(steps[5], wizardNext)
+	if got := *direct.InlineKeyboard[0][0].CallbackData; got != "wiz:next:6:n" {
+		t.Fatalf("unexpected next callback %q", got)
+	}
+	if got := *direct.InlineKeyboard[1][0].CallbackData; got != "wiz:skip:6:n" {
+		t.Fatalf("unexpected skip callback %q", got)
+	}
+}
@@ -3,11 +3,58 @@ package domain
 import "time"
 
 type Room struct {
-	ID          int64
-	OwnerUserID int64
-	Title       string
-	Password    string
-	CreatedAt   time.Time
+	ID                 int64
+	OwnerUserID        int64
+	Title              string
+	PasswordHash       string
+	Status             RoomStatus
+	JuryWeight         int  // вес жюри в процентах, остальное — зрители
+	AutoRunoff         bool // при закрытии ничья на первом месте порождает переголосование
+	WithdrawAfterClose bool // голос можно отозвать и после закрытия голосования
+	AllowRevote        bool // отданный голос (в любом режиме номинации) можно поменять или отозвать
+	CreatedAt          time.Time
+}
+
+// RoomStatus — этап жизни комнаты. Голоса принимаются только в RoomOpen.
+type RoomStatus string
+
+const (
+	RoomDraft     RoomStatus = "draft"
+	RoomOpen      RoomStatus = "open"
+	RoomClosed    RoomStatus = "closed"
+	RoomPublished RoomStatus = "published"
+)
+
+func (s RoomStatus) Title() string {
+	switch s {
+	case RoomDraft:
+		return "📝 черновик, голосование ещё не началось"
+	case RoomOpen:
+		return "🟢 голосование открыто"
+	case RoomClosed:
+		return "🔴 голосование закрыто"
+	case RoomPublished:
+		return "🏁 итоги опубликованы"
+	default:
+		return string(s)
+	}
+}
+
+// CanTransition — допустимые переходы: открыть черновик, закрыть, открыть заново,
+// опубликовать итоги закрытой комнаты и снять публикацию обратно в closed.
+func (s RoomStatus) CanTransition(to RoomStatus) bool {
+	switch s {
+	case RoomDraft:
+		return to == RoomOpen
+	case RoomOpen:
+		return to == RoomClosed
+	case RoomClosed:
+		return to == RoomOpen || to == RoomPublished
+	case RoomPublished:
+		return to == RoomClosed
+	default:
+		return false
+	}
 }
 
 type Nomination struct {
@@ -15,6 +62,96 @@ type Nomination struct {
 	RoomID      int64
 	Name        string
 	Description string
+	MaxChoices  int // сколько номинантов можно отметить; 1 — обычный выбор одного
+	Kind        NominationKind
+	TallyMethod TallyMethod // как считать ранжированные бюллетени
+	ParentID    int64       // номинация предыдущего тура; 0 — первый тур
+	MatchMins   int         // длительность матча в сетке; 0 — матчи переключает организатор
+	PassRule    PassRule    // порог принятия референдума
+	QuorumPct   int         // кворум референдума в процентах от участников комнаты; 0 — без кворума
+}
+
+// NominationKind — способ голосования в номинации.
+type NominationKind string
+
+const (
+	KindPlurality  NominationKind = "plurality"  // выбор одного (или до MaxChoices)
+	KindRanked     NominationKind = "ranked"     // ранжирование, подсчёт instant-runoff
+	KindScore      NominationKind = "score"      // оценка каждого номинанта от 1 до 5
+	KindBracket    NominationKind = "bracket"    // турнирная сетка на выбывание, матчи один на один
+	KindPairwise   NominationKind = "pairwise"   // попарные сравнения, рейтинг Брэдли–Терри
+	KindReferendum NominationKind = "referendum" // вопрос с ответами «за», «против», «воздержаться»
+)
+
+// TallyMethod — способ подсчёта ранжированных бюллетеней (KindRanked).
+type TallyMethod string
+
+const (
+	TallyIRV   TallyMethod = "irv"   // instant-runoff, бюллетень может быть неполным
+	TallyBorda TallyMethod = "borda" // очки по местам, нужен полный порядок
+)
+
+func (m TallyMethod) Title() string {
+	switch m {
+	case TallyBorda:
+		return "Борда"
+	default:
+		return "instant-runoff"
+	}
+}
+
+// PassRule — порог принятия решения в референдуме (KindReferendum).
+type PassRule string
+
+const (
+	PassMajority  PassRule = "majority"   // «за» больше, чем «против»
+	PassTwoThirds PassRule = "two_thirds" // «за» не меньше 2/3 от «за» и «против»
+)
+
+func (r PassRule) Title() string {
+	switch r {
+	case PassTwoThirds:
+		return "две трети"
+	default:
+		return "простое большинство"
+	}
+}
+
+// ReferendumChoice — ответ в референдуме.
+type ReferendumChoice string
+
+const (
+	ChoiceYes     ReferendumChoice = "yes"
+	ChoiceNo      ReferendumChoice = "no"
+	ChoiceAbstain ReferendumChoice = "abstain"
+)
+
+func (c ReferendumChoice) Title() string {
+	switch c {
+	case ChoiceYes:
+		return "за"
+	case ChoiceNo:
+		return "против"
+	default:
+		return "воздержаться"
+	}
+}
+
+func (k NominationKind) Title() string {
+	switch k {
+	case KindRanked:
+		return "ранжирование"
+	case KindScore:
+		return "оценки 1–5"
+	case KindBracket:
+		return "турнирная сетка"
+	case KindPairwise:
+		return "попарное сравнение"
+	case KindReferendum:
+		return "референдум"
+	default:
+		return "выбор"
+	}
 }
 
 type Nominee struct {
@@ -30,3 +167,155 @@ type NomineeResult struct {
 	Name  string
 	Votes int64
 }
+
+// JoinFailure — счётчик неудачных попыток входа в комнату по паролю.
+type JoinFailure struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Invite — пригласительная ссылка в комнату. Нулевые ExpiresAt/RevokedAt — «нет», MaxUses = 0 — без ограничения.
+type Invite struct {
+	Token     string
+	RoomID    int64
+	CreatedBy int64
+	MaxUses   int
+	Uses      int
+	ExpiresAt time.Time
+	RevokedAt time.Time
+	CreatedAt time.Time
+	Role      Role       // роль, которую получает вошедший (RoleNone — обычный участник)
+	Group     VoterGroup // группа голосующих вошедшего (жюри или зрители)
+}
+
+// Usable сообщает, можно ли ещё войти по приглашению в момент now.
+func (i Invite) Usable(now time.Time) bool {
+	if !i.RevokedAt.IsZero() {
+		return false
+	}
+	if !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt) {
+		return false
+	}
+	return i.MaxUses == 0 || i.Uses < i.MaxUses
+}
+
+// Role — роль пользователя в комнате. RoleNone — обычный участник (или чужой).
+type Role string
+
+const (
+	RoleNone     Role = ""
+	RoleOwner    Role = "owner"
+	RoleAdmin    Role = "admin"
+	RoleObserver Role = "observer"
+)
+
+// CanManage — можно менять номинации, номинантов, приглашения и настройки комнаты.
+func (r Role) CanManage() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
+
+// CanViewResults — можно смотреть результаты до публикации.
+func (r Role) CanViewResults() bool {
+	return r.CanManage() || r == RoleObserver
+}
+
+func (r Role) Title() string {
+	switch r {
+	case RoleOwner:
+		return "автор"
+	case RoleAdmin:
+		return "админ"
+	case RoleObserver:
+		return "наблюдатель"
+	default:
+		return "участник"
+	}
+}
+
+// RoomAdmin — запись из room_admins.
+type RoomAdmin struct {
+	RoomID      int64
+	UserID      int64
+	Role        Role
+	DisplayName string
+	AddedAt     time.Time
+}
+
+// AdminRoom — комната вместе с ролью пользователя в ней (для /my_rooms).
+type AdminRoom struct {
+	Room Room
+	Role Role
+}
+
+// ScheduledTransition — запланированная смена статуса комнаты.
+type ScheduledTransition struct {
+	ID        int64
+	RoomID    int64
+	To        RoomStatus
+	RunAt     time.Time
+	Timezone  string
+	CreatedBy int64
+}
+
+// ResultsMode — что участники видят в опубликованной номинации.
+type ResultsMode string
+
+const (
+	ResultsHidden  ResultsMode = ""
+	ResultsWinners ResultsMode = "winners"
+	ResultsFull    ResultsMode = "full"
+)
+
+func (m ResultsMode) Title() string {
+	switch m {
+	case ResultsWinners:
+		return "только победители"
+	case ResultsFull:
+		return "полные итоги"
+	default:
+		return "скрыто"
+	}
+}
+
+// VoterGroup — группа голосующих в комнате; голоса групп считаются отдельно и сводятся с весами.
+type VoterGroup string
+
+const (
+	GroupAudience VoterGroup = "audience"
+	GroupJury     VoterGroup = "jury"
+)
+
+func (g VoterGroup) Title() string {
+	switch g {
+	case GroupJury:
+		return "жюри"
+	default:
+		return "зрители"
+	}
+}
+
+// MatchStatus — этап матча турнирной сетки. Открыт одновременно только один матч номинации.
+type MatchStatus string
+
+const (
+	MatchPending MatchStatus = "pending"
+	MatchOpen    MatchStatus = "open"
+	MatchDone    MatchStatus = "done"
+)
+
+// BracketMatch — матч сетки вместе с голосами. Нулевые NomineeA/NomineeB — место пустое
+// (соперника нет или он ещё не определился), нулевой ClosesAt — матч закрывает организатор.
+type BracketMatch struct {
+	ID           int64
+	NominationID int64
+	Round        int // 1 — первый круг
+	Rounds       int // всего кругов в сетке
+	Position     int
+	NomineeA     int64
+	NomineeB     int64
+	VotesA       int
+	VotesB       int
+	WinnerID     int64
+	Status       MatchStatus
+	ClosesAt     time.Time
+}
@@ -1,6 +1,12 @@
 package session
 
-import "sync"
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultCacheSize — сколько сессий Manager держит в памяти; остальные лежат только в Store.
+const DefaultCacheSize = 10_000
 
 type Session struct {
 	ActiveRoomID                   int64
@@ -8,25 +14,112 @@ type Session struct {
 	CreatingNomineeForNominationID int64
 }
 
+// Store — бэкенд, в котором сессии переживают перезапуск процесса.
+// LoadSession возвращает nil, nil, если сессии ещё нет.
+type Store interface {
+	LoadSession(userID int64) (*Session, error)
+	SaveSession(userID int64, s *Session) error
+}
+
+// Manager кэширует сессии в памяти и сохраняет их в Store.
+// Кэш ограничен: при переполнении вытесняется сессия, к которой дольше всех не обращались, —
+// она уже сохранена в Store и при следующем обращении загрузится оттуда.
 type Manager struct {
-	mu       sync.RWMutex
-	sessions map[int64]*Session
+	mu       sync.Mutex
+	store    Store
+	capacity int
+	order    *list.List // от свежих к давним, значения — *entry
+	sessions map[int64]*list.Element
 }
 
-func NewManager() *Manager {
+type entry struct {
+	userID  int64
+	session *Session
+}
+
+func NewManager(store Store) *Manager {
+	return NewManagerSize(store, DefaultCacheSize)
+}
+
+// NewManagerSize — Manager с кэшем не больше capacity сессий.
+func NewManagerSize(store Store, capacity int) *Manager {
 	return &Manager{
-		sessions: make(map[int64]*Session),
+		store:    store,
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		sessions: make(map[int64]*list.Element),
 	}
 }
 
-func (m *Manager) Get(userID int64) *Session {
+func (m *Manager) Get(userID int64) (*Session, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	s := m.sessions[userID]
+	if el := m.sessions[userID]; el != nil {
+		m.order.MoveToFront(el)
+		return el.Value.(*entry).session, nil
+	}
+
+	s, err := m.store.LoadSession(userID)
+	if err != nil {
+		return nil, err
+	}
 	if s == nil {
 		s = &Session{}
-		m.sessions[userID] = s
 	}
-	return s
+	m.put(userID, s)
+	return s, nil
+}
+
+func (m *Manager) Save(userID int64, s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(userID, s)
+	return m.store.SaveSession(userID, s)
+}
+
+func (m *Manager) put(userID int64, s *Session) {
+	if el := m.sessions[userID]; el != nil {
+		el.Value.(*entry).session = s
+		m.order.MoveToFront(el)
+		return
+	}
+	m.sessions[userID] = m.order.PushFront(&entry{userID: userID, session: s})
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.sessions, oldest.Value.(*entry).userID)
+	}
+}
+
+// MemoryStore держит сессии только в памяти процесса — для тестов и локальной отладки.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[int64]Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[int64]Session),
+	}
+}
+
+func (m *MemoryStore) LoadSession(userID int64) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (m *MemoryStore) SaveSession(userID int64, s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[userID] = *s
+	return nil
 }
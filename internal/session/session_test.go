@@ -0,0 +1,35 @@
+package session
+
+import "testing"
+
+func TestManager_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+	m := NewManagerSize(store, 2)
+
+	for _, id := range []int64{1, 2} {
+		if err := m.Save(id, &Session{ActiveRoomID: id * 10}); err != nil {
+			t.Fatalf("Save(%d): %v", id, err)
+		}
+	}
+	// обращение к 1 делает его свежим — вытеснен будет 2
+	if _, err := m.Get(1); err != nil {
+		t.Fatalf("Get(1): %v", err)
+	}
+	if _, err := m.Get(3); err != nil {
+		t.Fatalf("Get(3): %v", err)
+	}
+	if got := m.order.Len(); got != 2 {
+		t.Fatalf("cache size = %d, want 2", got)
+	}
+	if _, cached := m.sessions[2]; cached {
+		t.Fatalf("user 2 should have been evicted")
+	}
+
+	// вытесненная сессия не теряется — она загружается из Store
+	s, err := m.Get(2)
+	if err != nil || s.ActiveRoomID != 20 {
+		t.Fatalf("Get(2) after eviction: %+v err=%v", s, err)
+	}
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"database/sql"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// ---------- Room admins / roles ----------
+
+func scanRole(row *sql.Row) (domain.Role, error) {
+	var role string
+	if err := row.Scan(&role); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.RoleNone, nil
+		}
+		return domain.RoleNone, err
+	}
+	return domain.Role(role), nil
+}
+
+// RoomRole — роль пользователя в комнате; RoleNone, если её нет.
+func (s *Store) RoomRole(roomID, userID int64) (domain.Role, error) {
+	return scanRole(s.db.QueryRow(`SELECT role FROM room_admins WHERE room_id = ? AND user_id = ?`, roomID, userID))
+}
+
+// NominationRole — роль пользователя в комнате, которой принадлежит номинация.
+func (s *Store) NominationRole(nominationID, userID int64) (domain.Role, error) {
+	return scanRole(s.db.QueryRow(`
+SELECT ra.role
+FROM nominations nom
+JOIN room_admins ra ON ra.room_id = nom.room_id
+WHERE nom.id = ? AND ra.user_id = ?
+`, nominationID, userID))
+}
+
+// NomineeRole — роль пользователя в комнате, которой принадлежит номинант.
+func (s *Store) NomineeRole(nomineeID, userID int64) (domain.Role, error) {
+	return scanRole(s.db.QueryRow(`
+SELECT ra.role
+FROM nominees n
+JOIN nominations nom ON n.nomination_id = nom.id
+JOIN room_admins ra ON ra.room_id = nom.room_id
+WHERE n.id = ? AND ra.user_id = ?
+`, nomineeID, userID))
+}
+
+// ListRoomsByAdmin — комнаты, где у пользователя есть роль, свежие первыми.
+func (s *Store) ListRoomsByAdmin(userID int64) ([]domain.AdminRoom, error) {
+	rows, err := s.db.Query(`
+SELECT r.id, r.owner_user_id, r.title, ra.role
+FROM room_admins ra
+JOIN rooms r ON r.id = ra.room_id
+WHERE ra.user_id = ?
+ORDER BY r.created_at DESC, r.id DESC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var rooms []domain.AdminRoom
+	for rows.Next() {
+		var ar domain.AdminRoom
+		var role string
+		if err := rows.Scan(&ar.Room.ID, &ar.Room.OwnerUserID, &ar.Room.Title, &role); err != nil {
+			return nil, err
+		}
+		ar.Role = domain.Role(role)
+		rooms = append(rooms, ar)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (s *Store) ListRoomAdmins(roomID int64) ([]domain.RoomAdmin, error) {
+	rows, err := s.db.Query(`
+SELECT user_id, role, display_name, added_at
+FROM room_admins
+WHERE room_id = ?
+ORDER BY CASE role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, added_at
+`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var admins []domain.RoomAdmin
+	for rows.Next() {
+		a := domain.RoomAdmin{RoomID: roomID}
+		var role string
+		if err := rows.Scan(&a.UserID, &role, &a.DisplayName, &a.AddedAt); err != nil {
+			return nil, err
+		}
+		a.Role = domain.Role(role)
+		admins = append(admins, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return admins, nil
+}
+
+// RemoveRoomAdmin снимает роль admin/observer. Автора снять нельзя.
+func (s *Store) RemoveRoomAdmin(roomID, userID int64) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM room_admins WHERE room_id = ? AND user_id = ? AND role != 'owner'`, roomID, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// addRoomAdmin выдаёт роль; роль автора не понижается.
+func addRoomAdmin(tx *sql.Tx, roomID, userID int64, role domain.Role, displayName string) error {
+	_, err := tx.Exec(`
+INSERT INTO room_admins(room_id, user_id, role, display_name)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(room_id, user_id) DO UPDATE SET
+    role = excluded.role,
+    display_name = excluded.display_name
+WHERE room_admins.role != 'owner'
+`, roomID, userID, role, displayName)
+	return err
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_RoomRoles_OwnerAdminObserver(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	const owner, admin, observer, stranger = 1, 2, 3, 4
+
+	roomID, _ := s.CreateRoom(owner, "room", "pw")
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	nomineeID, _ := s.CreateNominee(nomID, "A")
+
+	now := time.Now()
+	for _, inv := range []domain.Invite{
+		{Token: "adm", RoomID: roomID, CreatedBy: owner, MaxUses: 1, CreatedAt: now, Role: domain.RoleAdmin},
+		{Token: "obs", RoomID: roomID, CreatedBy: owner, MaxUses: 1, CreatedAt: now, Role: domain.RoleObserver},
+		{Token: "own", RoomID: roomID, CreatedBy: owner, CreatedAt: now, Role: domain.RoleAdmin},
+	} {
+		if err := s.CreateInvite(inv); err != nil {
+			t.Fatalf("CreateInvite(%s): %v", inv.Token, err)
+		}
+	}
+
+	if _, role, _, err := s.RedeemInvite("adm", admin, "hadmin", "@admin", now); err != nil || role != domain.RoleAdmin {
+		t.Fatalf("RedeemInvite(adm): role=%q err=%v", role, err)
+	}
+	if _, _, _, err := s.RedeemInvite("obs", observer, "hobserver", "", now); err != nil {
+		t.Fatalf("RedeemInvite(obs): %v", err)
+	}
+	// автор по админской ссылке не понижается
+	if _, _, _, err := s.RedeemInvite("own", owner, "howner", "", now); err != nil {
+		t.Fatalf("RedeemInvite(own): %v", err)
+	}
+
+	want := map[int64]domain.Role{owner: domain.RoleOwner, admin: domain.RoleAdmin, observer: domain.RoleObserver, stranger: domain.RoleNone}
+	for userID, wantRole := range want {
+		for name, get := range map[string]func() (domain.Role, error){
+			"RoomRole":       func() (domain.Role, error) { return s.RoomRole(roomID, userID) },
+			"NominationRole": func() (domain.Role, error) { return s.NominationRole(nomID, userID) },
+			"NomineeRole":    func() (domain.Role, error) { return s.NomineeRole(nomineeID, userID) },
+		} {
+			got, err := get()
+			if err != nil {
+				t.Fatalf("%s(user %d): %v", name, userID, err)
+			}
+			if got != wantRole {
+				t.Fatalf("%s(user %d): got=%q want=%q", name, userID, got, wantRole)
+			}
+		}
+	}
+
+	rooms, err := s.ListRoomsByAdmin(admin)
+	if err != nil {
+		t.Fatalf("ListRoomsByAdmin: %v", err)
+	}
+	if len(rooms) != 1 || rooms[0].Room.ID != roomID || rooms[0].Role != domain.RoleAdmin {
+		t.Fatalf("unexpected admin rooms: %+v", rooms)
+	}
+
+	// автора снять нельзя, админа — можно
+	if removed, _ := s.RemoveRoomAdmin(roomID, owner); removed {
+		t.Fatalf("owner must not be removable")
+	}
+	if removed, err := s.RemoveRoomAdmin(roomID, admin); err != nil || !removed {
+		t.Fatalf("RemoveRoomAdmin(admin): removed=%v err=%v", removed, err)
+	}
+	if role, _ := s.RoomRole(roomID, admin); role != domain.RoleNone {
+		t.Fatalf("expected no role after removal, got %q", role)
+	}
+
+	admins, err := s.ListRoomAdmins(roomID)
+	if err != nil {
+		t.Fatalf("ListRoomAdmins: %v", err)
+	}
+	if len(admins) != 2 || admins[0].Role != domain.RoleOwner || admins[1].UserID != observer {
+		t.Fatalf("unexpected admins: %+v", admins)
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore_ToggleVote_Approval(t *testing.T) {
+	s, db := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	c, _ := s.CreateNominee(nomID, "C")
+
+	if err := s.SetNominationMaxChoices(nomID, 2); err != nil {
+		t.Fatalf("SetNominationMaxChoices: %v", err)
+	}
+	nom, err := s.GetNomination(nomID)
+	if err != nil || nom.MaxChoices != 2 {
+		t.Fatalf("GetNomination: %+v err=%v", nom, err)
+	}
+
+	now := time.Now()
+	for _, id := range []int64{a, b} {
+		added, _, err := s.ToggleVote("u1", nomID, id, now)
+		if err != nil || !added {
+			t.Fatalf("ToggleVote(%d): added=%v err=%v", id, added, err)
+		}
+	}
+
+	// третья отметка сверх лимита
+	if _, selected, err := s.ToggleVote("u1", nomID, c, now); err != ErrTooManyChoices || selected != 2 {
+		t.Fatalf("expected ErrTooManyChoices with 2 selected, got selected=%d err=%v", selected, err)
+	}
+
+	// повторное нажатие снимает отметку и освобождает место
+	added, selected, err := s.ToggleVote("u1", nomID, a, now)
+	if err != nil || added || selected != 1 {
+		t.Fatalf("untoggle: added=%v selected=%d err=%v", added, selected, err)
+	}
+	if _, _, err := s.ToggleVote("u1", nomID, c, now); err != nil {
+		t.Fatalf("ToggleVote(c): %v", err)
+	}
+	if _, _, err := s.ToggleVote("u2", nomID, c, now); err != nil {
+		t.Fatalf("ToggleVote(u2): %v", err)
+	}
+
+	voted, err := s.UserNomineeVotes("u1", nomID)
+	if err != nil {
+		t.Fatalf("UserNomineeVotes: %v", err)
+	}
+	if len(voted) != 2 || !voted[b] || !voted[c] {
+		t.Fatalf("unexpected u1 votes: %v", voted)
+	}
+
+	results, err := s.ResultsByNomination(nomID)
+	if err != nil {
+		t.Fatalf("ResultsByNomination: %v", err)
+	}
+	got := map[int64]int64{}
+	for _, r := range results {
+		got[r.ID] = r.Votes
+	}
+	if got[a] != 0 || got[b] != 1 || got[c] != 2 {
+		t.Fatalf("unexpected approvals: %v", got)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM votes WHERE nomination_id = ?`, nomID); n != 3 {
+		t.Fatalf("expected 3 vote rows, got %d", n)
+	}
+
+	// после первых голосов лимит менять нельзя
+	if err := s.SetNominationMaxChoices(nomID, 3); err != ErrHasVotes {
+		t.Fatalf("expected ErrHasVotes, got %v", err)
+	}
+	if err := s.SetNominationMaxChoices(999, 3); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
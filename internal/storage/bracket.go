@@ -0,0 +1,428 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+var (
+	ErrNotBracket    = errors.New("nomination is not a bracket")
+	ErrBracketExists = errors.New("bracket already created")
+	ErrMatchClosed   = errors.New("match is not open")
+)
+
+// ---------- Bracket ----------
+
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+const matchColumns = `
+SELECT m.id, r.nomination_id, r.number,
+       (SELECT COUNT(*) FROM bracket_rounds rr WHERE rr.nomination_id = r.nomination_id),
+       m.position, IFNULL(m.nominee_a, 0), IFNULL(m.nominee_b, 0),
+       (SELECT COUNT(*) FROM match_votes v WHERE v.match_id = m.id AND v.nominee_id = m.nominee_a),
+       (SELECT COUNT(*) FROM match_votes v WHERE v.match_id = m.id AND v.nominee_id = m.nominee_b),
+       IFNULL(m.winner_id, 0), m.status, m.closes_at
+FROM bracket_matches m
+JOIN bracket_rounds r ON r.id = m.round_id
+`
+
+func listMatches(db queryer, where string, args ...any) ([]domain.BracketMatch, error) {
+	rows, err := db.Query(matchColumns+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var matches []domain.BracketMatch
+	for rows.Next() {
+		var m domain.BracketMatch
+		var closesAt sql.NullTime
+		if err := rows.Scan(&m.ID, &m.NominationID, &m.Round, &m.Rounds, &m.Position, &m.NomineeA, &m.NomineeB,
+			&m.VotesA, &m.VotesB, &m.WinnerID, &m.Status, &closesAt); err != nil {
+			return nil, err
+		}
+		m.ClosesAt = closesAt.Time
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+func firstMatch(db queryer, where string, args ...any) (*domain.BracketMatch, error) {
+	matches, err := listMatches(db, where+" LIMIT 1", args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, ErrNotFound
+	}
+	return &matches[0], nil
+}
+
+// CreateBracket строит сетку номинации типа bracket: pairs — пары первого круга (0 — пустое место,
+// см. tally.BracketPairs), число пар — степень двойки. Матчи без соперника сразу закрываются,
+// первый настоящий матч открывается. minutes — длительность матча, 0 — переключает организатор.
+// Возвращает открытый матч (nil, если играть некому).
+func (s *Store) CreateBracket(nominationID int64, pairs [][2]int64, minutes int, now time.Time) (*domain.BracketMatch, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var kind domain.NominationKind
+	var exists bool
+	err = tx.QueryRow(`
+SELECT kind, EXISTS (SELECT 1 FROM bracket_rounds WHERE nomination_id = nominations.id)
+FROM nominations WHERE id = ?
+`, nominationID).Scan(&kind, &exists)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if kind != domain.KindBracket {
+		return nil, ErrNotBracket
+	}
+	if exists {
+		return nil, ErrBracketExists
+	}
+
+	if _, err := tx.Exec(`UPDATE nominations SET match_minutes = ? WHERE id = ?`, minutes, nominationID); err != nil {
+		return nil, err
+	}
+
+	for number, matches := 1, len(pairs); matches >= 1; number, matches = number+1, matches/2 {
+		var roundID int64
+		err := tx.QueryRow(`INSERT INTO bracket_rounds(nomination_id, number) VALUES (?, ?) RETURNING id`,
+			nominationID, number).Scan(&roundID)
+		if err != nil {
+			return nil, err
+		}
+		for pos := 0; pos < matches; pos++ {
+			var a, b any
+			if number == 1 {
+				a, b = nullID(pairs[pos][0]), nullID(pairs[pos][1])
+			}
+			_, err := tx.Exec(`INSERT INTO bracket_matches(round_id, position, nominee_a, nominee_b) VALUES (?, ?, ?, ?)`,
+				roundID, pos, a, b)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_, opened, err := advanceBracket(tx, nominationID, nil, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return opened, nil
+}
+
+// AdvanceBracket закрывает матч matchID (победитель — у кого больше голосов, при равенстве —
+// номинант, добавленный раньше) и открывает следующий. Матч закрывается, только если он всё ещё
+// открытый матч этой номинации, иначе — ErrMatchClosed и ничего не меняется: две нажатые подряд
+// кнопки не закроют заодно и следующий матч. opened == nil после закрытия финала — турнир окончен.
+func (s *Store) AdvanceBracket(nominationID, matchID int64, now time.Time) (closed, opened *domain.BracketMatch, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM bracket_rounds WHERE nomination_id = ?)`, nominationID).Scan(&exists); err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, ErrNotFound
+	}
+
+	current, err := firstMatch(tx, `WHERE r.nomination_id = ? AND m.id = ? AND m.status = 'open'`, nominationID, matchID)
+	if err == ErrNotFound {
+		return nil, nil, ErrMatchClosed
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closed, opened, err = advanceBracket(tx, nominationID, current, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return closed, opened, nil
+}
+
+// AdvanceDueMatch — то же по таймеру: матч закрывается, только если он всё ещё открыт,
+// комната открыта и его время вышло к now. Иначе (организатор успел переключить матч
+// сам) closed и opened — nil и ничего не меняется.
+func (s *Store) AdvanceDueMatch(matchID int64, now time.Time) (closed, opened *domain.BracketMatch, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := firstMatch(tx, `
+WHERE m.id = ? AND m.status = 'open' AND m.closes_at IS NOT NULL AND m.closes_at <= ?
+  AND EXISTS (SELECT 1 FROM nominations n JOIN rooms ro ON ro.id = n.room_id WHERE n.id = r.nomination_id AND ro.status = 'open')`,
+		matchID, now.UTC())
+	if err == ErrNotFound {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closed, opened, err = advanceBracket(tx, current.NominationID, current, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return closed, opened, nil
+}
+
+// advanceBracket: закрыть current (если он есть), затем открыть первый по порядку ожидающий.
+// Матчи играются строго по порядку (круг, позиция), поэтому у первого ожидающего матча
+// оба места уже определены; если одно пустое — матч закрывается без голосования.
+func advanceBracket(tx *sql.Tx, nominationID int64, current *domain.BracketMatch, now time.Time) (closed, opened *domain.BracketMatch, err error) {
+	if current != nil {
+		if err := finishMatch(tx, current); err != nil {
+			return nil, nil, err
+		}
+		closed = current
+	}
+
+	for {
+		next, err := firstMatch(tx, `WHERE r.nomination_id = ? AND m.status = 'pending' ORDER BY r.number, m.position`, nominationID)
+		if err == ErrNotFound {
+			return closed, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if next.NomineeA == 0 || next.NomineeB == 0 {
+			if err := finishMatch(tx, next); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		var minutes int
+		var roomOpen bool
+		err = tx.QueryRow(`
+SELECT n.match_minutes, ro.status = 'open'
+FROM nominations n
+JOIN rooms ro ON ro.id = n.room_id
+WHERE n.id = ?
+`, nominationID).Scan(&minutes, &roomOpen)
+		if err != nil {
+			return nil, nil, err
+		}
+		// таймер запускаем, только если комната открыта, иначе его выставит DueMatches
+		var closesAt any
+		if minutes > 0 && roomOpen {
+			closesAt = now.UTC().Add(time.Duration(minutes) * time.Minute)
+		}
+		if _, err := tx.Exec(`UPDATE bracket_matches SET status = 'open', closes_at = ? WHERE id = ?`, closesAt, next.ID); err != nil {
+			return nil, nil, err
+		}
+		opened, err = firstMatch(tx, `WHERE m.id = ?`, next.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return closed, opened, nil
+	}
+}
+
+// finishMatch выбирает победителя, закрывает матч и ставит победителя в матч следующего круга.
+func finishMatch(tx *sql.Tx, m *domain.BracketMatch) error {
+	m.WinnerID = matchWinner(*m)
+	m.Status = domain.MatchDone
+	if _, err := tx.Exec(`UPDATE bracket_matches SET status = 'done', winner_id = ? WHERE id = ?`, nullID(m.WinnerID), m.ID); err != nil {
+		return err
+	}
+	if m.Round == m.Rounds {
+		return nil
+	}
+
+	slot := "nominee_a"
+	if m.Position%2 == 1 {
+		slot = "nominee_b"
+	}
+	_, err := tx.Exec(`
+UPDATE bracket_matches SET `+slot+` = ?
+WHERE position = ? AND round_id = (SELECT id FROM bracket_rounds WHERE nomination_id = ? AND number = ?)
+`, nullID(m.WinnerID), m.Position/2, m.NominationID, m.Round+1)
+	return err
+}
+
+func matchWinner(m domain.BracketMatch) int64 {
+	switch {
+	case m.NomineeA == 0:
+		return m.NomineeB
+	case m.NomineeB == 0:
+		return m.NomineeA
+	case m.VotesA != m.VotesB:
+		if m.VotesA > m.VotesB {
+			return m.NomineeA
+		}
+		return m.NomineeB
+	default:
+		return min(m.NomineeA, m.NomineeB)
+	}
+}
+
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// BracketMatches — все матчи сетки по кругам и позициям; пусто, если сетку ещё не построили.
+func (s *Store) BracketMatches(nominationID int64) ([]domain.BracketMatch, error) {
+	return listMatches(s.db, `WHERE r.nomination_id = ? ORDER BY r.number, m.position`, nominationID)
+}
+
+func (s *Store) GetMatch(matchID int64) (*domain.BracketMatch, error) {
+	return firstMatch(s.db, `WHERE m.id = ?`, matchID)
+}
+
+// CurrentMatch — открытый матч номинации; ErrNotFound, если сетки нет или турнир окончен.
+func (s *Store) CurrentMatch(nominationID int64) (*domain.BracketMatch, error) {
+	return firstMatch(s.db, `WHERE r.nomination_id = ? AND m.status = 'open'`, nominationID)
+}
+
+// RecordMatchVote сохраняет (или меняет) голос в открытом матче. Закрытый матч — ErrMatchClosed,
+// номинант не из этого матча — ErrNomineeMismatch, вне статуса open — ErrVotingClosed.
+// Без переголосования (allow_revote) другой голос в том же матче — ErrVoteLocked.
+func (s *Store) RecordMatchVote(userHash string, matchID, nomineeID int64, at time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	m, err := firstMatch(tx, `WHERE m.id = ?`, matchID)
+	if err != nil {
+		return err
+	}
+	if err := checkVotingOpen(tx, m.NominationID); err != nil {
+		return err
+	}
+	if m.Status != domain.MatchOpen {
+		return ErrMatchClosed
+	}
+	if nomineeID != m.NomineeA && nomineeID != m.NomineeB {
+		return ErrNomineeMismatch
+	}
+	allow, err := allowRevote(tx, m.NominationID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+INSERT INTO match_votes(match_id, user_hash, nominee_id, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(match_id, user_hash) DO UPDATE SET
+    nominee_id = excluded.nominee_id,
+    created_at = excluded.created_at
+WHERE ?
+`, matchID, userHash, nomineeID, at.UTC(), allow)
+	if err != nil {
+		return err
+	}
+	if err := checkFinalVote(tx, res, `SELECT nominee_id = ? FROM match_votes WHERE match_id = ? AND user_hash = ?`,
+		nomineeID, matchID, userHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UserMatchVote — за кого пользователь голосует в матче; 0 — ещё не голосовал.
+func (s *Store) UserMatchVote(userHash string, matchID int64) (int64, error) {
+	var nomineeID int64
+	err := s.db.QueryRow(`SELECT nominee_id FROM match_votes WHERE match_id = ? AND user_hash = ?`, matchID, userHash).Scan(&nomineeID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return nomineeID, err
+}
+
+// UserMatchVotes — за кого пользователь голосовал в матчах сетки, по порядку матчей.
+func (s *Store) UserMatchVotes(userHash string, nominationID int64) ([]int64, error) {
+	return s.listIDs(`
+SELECT mv.nominee_id
+FROM match_votes mv
+JOIN bracket_matches m ON m.id = mv.match_id
+JOIN bracket_rounds r ON r.id = m.round_id
+WHERE r.nomination_id = ? AND mv.user_hash = ?
+ORDER BY r.number, m.position
+`, nominationID, userHash)
+}
+
+// DueMatches — открытые матчи, которые пора закрывать по таймеру (см. AdvanceDueMatch).
+// Заодно запускает таймер у матчей, открытых, пока комната была закрыта.
+func (s *Store) DueMatches(now time.Time) ([]int64, error) {
+	rows, err := s.db.Query(`
+SELECT m.id, n.match_minutes
+FROM bracket_matches m
+JOIN bracket_rounds r ON r.id = m.round_id
+JOIN nominations n ON n.id = r.nomination_id
+JOIN rooms ro ON ro.id = n.room_id
+WHERE m.status = 'open' AND m.closes_at IS NULL AND n.match_minutes > 0 AND ro.status = 'open'
+`)
+	if err != nil {
+		return nil, err
+	}
+	type pending struct {
+		id      int64
+		minutes int
+	}
+	var start []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.minutes); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		start = append(start, p)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	for _, p := range start {
+		closesAt := now.UTC().Add(time.Duration(p.minutes) * time.Minute)
+		if _, err := s.db.Exec(`UPDATE bracket_matches SET closes_at = ? WHERE id = ?`, closesAt, p.id); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.listIDs(`
+SELECT m.id
+FROM bracket_matches m
+JOIN bracket_rounds r ON r.id = m.round_id
+JOIN nominations n ON n.id = r.nomination_id
+JOIN rooms ro ON ro.id = n.room_id
+WHERE m.status = 'open' AND m.closes_at IS NOT NULL AND m.closes_at <= ? AND ro.status = 'open'
+ORDER BY m.closes_at, m.id
+`, now.UTC())
+}
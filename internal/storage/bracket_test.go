@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestStore_Bracket_PlaysToTheFinal(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Cup", "")
+	var ids []int64
+	for _, name := range []string{"A", "B", "C"} {
+		id, _ := s.CreateNominee(nomID, name)
+		ids = append(ids, id)
+	}
+	a, b, c := ids[0], ids[1], ids[2]
+	now := time.Unix(1_000_000, 0)
+
+	if _, err := s.CreateBracket(nomID, tally.BracketPairs(ids), 0, now); err != ErrNotBracket {
+		t.Fatalf("expected ErrNotBracket, got %v", err)
+	}
+	if err := s.SetNominationKind(nomID, domain.KindBracket); err != nil {
+		t.Fatalf("SetNominationKind: %v", err)
+	}
+
+	// 3 номинанта: A проходит без соперника, первый матч — B против C
+	opened, err := s.CreateBracket(nomID, tally.BracketPairs(ids), 0, now)
+	if err != nil {
+		t.Fatalf("CreateBracket: %v", err)
+	}
+	if opened == nil || opened.Round != 1 || opened.Rounds != 2 || opened.NomineeA != b || opened.NomineeB != c {
+		t.Fatalf("unexpected first match: %+v", opened)
+	}
+	if _, err := s.CreateBracket(nomID, tally.BracketPairs(ids), 0, now); err != ErrBracketExists {
+		t.Fatalf("expected ErrBracketExists, got %v", err)
+	}
+	if err := s.SetNominationKind(nomID, domain.KindPlurality); err != ErrHasVotes {
+		t.Fatalf("kind must be locked once the bracket exists, got %v", err)
+	}
+
+	if err := s.RecordMatchVote("u1", opened.ID, a, now); err != ErrNomineeMismatch {
+		t.Fatalf("expected ErrNomineeMismatch, got %v", err)
+	}
+	for user, nominee := range map[string]int64{"u1": b, "u2": c, "u3": c} {
+		if err := s.RecordMatchVote(user, opened.ID, nominee, now); err != nil {
+			t.Fatalf("RecordMatchVote(%s): %v", user, err)
+		}
+	}
+	// передумал: голос меняется, а не добавляется
+	if err := s.RecordMatchVote("u3", opened.ID, b, now); err != nil {
+		t.Fatalf("RecordMatchVote(u3 again): %v", err)
+	}
+	if got, err := s.UserMatchVote("u3", opened.ID); err != nil || got != b {
+		t.Fatalf("UserMatchVote: %d err=%v", got, err)
+	}
+	if got, err := s.UserMatchVotes("u3", nomID); err != nil || len(got) != 1 || got[0] != b {
+		t.Fatalf("UserMatchVotes: %v err=%v", got, err)
+	}
+
+	// 2:1 в пользу B, финал — A против B
+	closed, final, err := s.AdvanceBracket(nomID, opened.ID, now)
+	if err != nil {
+		t.Fatalf("AdvanceBracket: %v", err)
+	}
+	if closed == nil || closed.WinnerID != b || closed.VotesA != 2 || closed.VotesB != 1 {
+		t.Fatalf("unexpected closed match: %+v", closed)
+	}
+	if final == nil || final.Round != 2 || final.NomineeA != a || final.NomineeB != b {
+		t.Fatalf("unexpected final: %+v", final)
+	}
+	if err := s.RecordMatchVote("u1", closed.ID, b, now); err != ErrMatchClosed {
+		t.Fatalf("expected ErrMatchClosed, got %v", err)
+	}
+
+	// повторное нажатие на кнопку уже закрытого матча не трогает финал
+	if _, _, err := s.AdvanceBracket(nomID, closed.ID, now); err != ErrMatchClosed {
+		t.Fatalf("stale AdvanceBracket: expected ErrMatchClosed, got %v", err)
+	}
+	if m, err := s.CurrentMatch(nomID); err != nil || m.ID != final.ID {
+		t.Fatalf("final must stay open: %+v err=%v", m, err)
+	}
+
+	// без голосов ничья — проходит номинант, добавленный раньше
+	closed, next, err := s.AdvanceBracket(nomID, final.ID, now)
+	if err != nil || closed.WinnerID != a || next != nil {
+		t.Fatalf("final: closed=%+v next=%+v err=%v", closed, next, err)
+	}
+	if _, err := s.CurrentMatch(nomID); err != ErrNotFound {
+		t.Fatalf("expected no open match, got %v", err)
+	}
+
+	matches, err := s.BracketMatches(nomID)
+	if err != nil || len(matches) != 3 {
+		t.Fatalf("BracketMatches: %+v err=%v", matches, err)
+	}
+	if matches[0].WinnerID != a || matches[0].NomineeB != 0 || matches[0].Status != domain.MatchDone {
+		t.Fatalf("bye match: %+v", matches[0])
+	}
+}
+
+func TestStore_DueMatches_StartsTimerWhenRoomOpens(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, _ := s.CreateRoom(1, "room", "pw")
+	nomID, _ := s.CreateNomination(roomID, "Cup", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	if err := s.SetNominationKind(nomID, domain.KindBracket); err != nil {
+		t.Fatalf("SetNominationKind: %v", err)
+	}
+	now := time.Unix(1_000_000, 0)
+
+	// сетку строят в черновике — таймер не идёт
+	opened, err := s.CreateBracket(nomID, tally.BracketPairs([]int64{a, b}), 10, now)
+	if err != nil || opened == nil || !opened.ClosesAt.IsZero() {
+		t.Fatalf("CreateBracket: %+v err=%v", opened, err)
+	}
+	if due, err := s.DueMatches(now.Add(time.Hour)); err != nil || len(due) != 0 {
+		t.Fatalf("draft room must not be due: %v err=%v", due, err)
+	}
+
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomOpen); err != nil {
+		t.Fatalf("open room: %v", err)
+	}
+	start := now.Add(time.Hour)
+	if due, err := s.DueMatches(start); err != nil || len(due) != 0 {
+		t.Fatalf("timer just started: %v err=%v", due, err)
+	}
+	m, err := s.CurrentMatch(nomID)
+	if err != nil || !m.ClosesAt.Equal(start.Add(10*time.Minute)) {
+		t.Fatalf("CurrentMatch: %+v err=%v", m, err)
+	}
+
+	if due, err := s.DueMatches(start.Add(10 * time.Minute)); err != nil || len(due) != 1 || due[0] != m.ID {
+		t.Fatalf("DueMatches: %v err=%v", due, err)
+	}
+}
+
+func TestStore_AdvanceDueMatch_SkipsMatchAdvancedManually(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, _ := s.CreateRoom(1, "room", "pw")
+	nomID, _ := s.CreateNomination(roomID, "Cup", "")
+	var ids []int64
+	for _, name := range []string{"A", "B", "C", "D"} {
+		id, _ := s.CreateNominee(nomID, name)
+		ids = append(ids, id)
+	}
+	if err := s.SetNominationKind(nomID, domain.KindBracket); err != nil {
+		t.Fatalf("SetNominationKind: %v", err)
+	}
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomOpen); err != nil {
+		t.Fatalf("open room: %v", err)
+	}
+	now := time.Unix(1_000_000, 0)
+	first, err := s.CreateBracket(nomID, tally.BracketPairs(ids), 10, now)
+	if err != nil || first == nil {
+		t.Fatalf("CreateBracket: %+v err=%v", first, err)
+	}
+
+	due := now.Add(10 * time.Minute)
+	if _, _, err := s.AdvanceDueMatch(first.ID, due.Add(-time.Second)); err != nil {
+		t.Fatalf("AdvanceDueMatch(early): %v", err)
+	}
+	if m, err := s.CurrentMatch(nomID); err != nil || m.ID != first.ID {
+		t.Fatalf("match closed before its time: %+v err=%v", m, err)
+	}
+
+	// организатор переключил матч сам, пока планировщик держал старый ID
+	_, second, err := s.AdvanceBracket(nomID, first.ID, due)
+	if err != nil || second == nil {
+		t.Fatalf("AdvanceBracket: %+v err=%v", second, err)
+	}
+	closed, opened, err := s.AdvanceDueMatch(first.ID, due)
+	if err != nil || closed != nil || opened != nil {
+		t.Fatalf("stale AdvanceDueMatch: closed=%+v opened=%+v err=%v", closed, opened, err)
+	}
+	if _, _, err := s.AdvanceDueMatch(second.ID, due); err != nil {
+		t.Fatalf("AdvanceDueMatch(second): %v", err)
+	}
+	if m, err := s.CurrentMatch(nomID); err != nil || m.ID != second.ID {
+		t.Fatalf("fresh match must stay open: %+v err=%v", m, err)
+	}
+
+	closed, _, err = s.AdvanceDueMatch(second.ID, second.ClosesAt)
+	if err != nil || closed == nil || closed.ID != second.ID {
+		t.Fatalf("AdvanceDueMatch(due): closed=%+v err=%v", closed, err)
+	}
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+// ---------- Comparisons ----------
+
+// RecordComparison сохраняет попарное сравнение: из пары winnerID и loserID выбран winnerID.
+// У голосующего одно мнение на пару — повторное сравнение той же пары его заменяет,
+// а без переголосования (allow_revote) другой выбор в уже сравнённой паре — ErrVoteLocked.
+// Номинант не из этой номинации (или оба — один и тот же) — ErrNomineeMismatch,
+// вне статуса open — ErrVotingClosed.
+func (s *Store) RecordComparison(userHash string, nominationID, winnerID, loserID int64, createdAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkVotingOpen(tx, nominationID); err != nil {
+		return err
+	}
+
+	if winnerID == loserID {
+		return ErrNomineeMismatch
+	}
+	var found int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM nominees WHERE nomination_id = ? AND id IN (?, ?)`,
+		nominationID, winnerID, loserID).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if found != 2 {
+		return ErrNomineeMismatch
+	}
+	allow, err := allowRevote(tx, nominationID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+INSERT INTO comparisons(user_hash, nomination_id, winner_id, loser_id, created_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(user_hash, nomination_id, MIN(winner_id, loser_id), MAX(winner_id, loser_id)) DO UPDATE SET
+    winner_id = excluded.winner_id,
+    loser_id = excluded.loser_id,
+    created_at = excluded.created_at
+WHERE ?
+`, userHash, nominationID, winnerID, loserID, createdAt.UTC(), allow)
+	if err != nil {
+		return err
+	}
+	if err := checkFinalVote(tx, res, `
+SELECT winner_id = ? FROM comparisons
+WHERE user_hash = ? AND nomination_id = ? AND MIN(winner_id, loser_id) = ? AND MAX(winner_id, loser_id) = ?
+`, winnerID, userHash, nominationID, min(winnerID, loserID), max(winnerID, loserID)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UserComparisonCount — сколько разных пар пользователь сравнил в номинации.
+func (s *Store) UserComparisonCount(userHash string, nominationID int64) (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM comparisons WHERE user_hash = ? AND nomination_id = ?`,
+		userHash, nominationID).Scan(&n)
+	return n, err
+}
+
+// UserComparedPairs — пары, которые пользователь уже сравнил: ключ — {меньший ID, больший ID}.
+func (s *Store) UserComparedPairs(userHash string, nominationID int64) (map[[2]int64]bool, error) {
+	rows, err := s.db.Query(`
+SELECT MIN(winner_id, loser_id), MAX(winner_id, loser_id) FROM comparisons
+WHERE user_hash = ? AND nomination_id = ?
+`, userHash, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	pairs := make(map[[2]int64]bool)
+	for rows.Next() {
+		var pair [2]int64
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, err
+		}
+		pairs[pair] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// NominationComparisons — все сравнения номинации в порядке поступления, для tally.BradleyTerry.
+func (s *Store) NominationComparisons(nominationID int64) ([]tally.Comparison, error) {
+	rows, err := s.db.Query(`SELECT winner_id, loser_id FROM comparisons WHERE nomination_id = ? ORDER BY id`, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var comparisons []tally.Comparison
+	for rows.Next() {
+		var c tally.Comparison
+		if err := rows.Scan(&c.Winner, &c.Loser); err != nil {
+			return nil, err
+		}
+		comparisons = append(comparisons, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return comparisons, nil
+}
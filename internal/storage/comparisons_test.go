@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestStore_Comparisons(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	c, _ := s.CreateNominee(nomID, "C")
+	otherNom, _ := s.CreateNomination(roomID, "Other", "")
+	foreign, _ := s.CreateNominee(otherNom, "X")
+
+	if err := s.SetNominationKind(nomID, domain.KindPairwise); err != nil {
+		t.Fatalf("SetNominationKind: %v", err)
+	}
+
+	now := time.Now()
+	must := func(user string, winner, loser int64) {
+		t.Helper()
+		if err := s.RecordComparison(user, nomID, winner, loser, now); err != nil {
+			t.Fatalf("RecordComparison(%s, %d, %d): %v", user, winner, loser, err)
+		}
+	}
+	must("u1", a, b)
+	must("u1", a, b) // та же пара ещё раз — всё ещё одно сравнение
+	must("u1", c, a)
+	must("u1", a, c) // передумал: заменяет c > a
+	must("u2", b, c)
+
+	if err := s.RecordComparison("u1", nomID, a, foreign, now); err != ErrNomineeMismatch {
+		t.Fatalf("foreign nominee: expected ErrNomineeMismatch, got %v", err)
+	}
+	if err := s.RecordComparison("u1", nomID, a, a, now); err != ErrNomineeMismatch {
+		t.Fatalf("same nominee: expected ErrNomineeMismatch, got %v", err)
+	}
+
+	if n, err := s.UserComparisonCount("u1", nomID); err != nil || n != 2 {
+		t.Fatalf("UserComparisonCount: %d err=%v", n, err)
+	}
+
+	pairs, err := s.UserComparedPairs("u1", nomID)
+	if err != nil || !reflect.DeepEqual(pairs, map[[2]int64]bool{{a, b}: true, {a, c}: true}) {
+		t.Fatalf("UserComparedPairs: got=%v err=%v", pairs, err)
+	}
+
+	all, err := s.NominationComparisons(nomID)
+	want := []tally.Comparison{{Winner: a, Loser: b}, {Winner: a, Loser: c}, {Winner: b, Loser: c}}
+	if err != nil || !reflect.DeepEqual(all, want) {
+		t.Fatalf("NominationComparisons: got=%v err=%v", all, err)
+	}
+
+	if err := s.SetNominationKind(nomID, domain.KindPlurality); err != ErrHasVotes {
+		t.Fatalf("expected ErrHasVotes, got %v", err)
+	}
+
+	// удалённый номинант уносит свои сравнения
+	if _, err := s.DeleteNominee(c); err != nil {
+		t.Fatalf("DeleteNominee: %v", err)
+	}
+	if all, _ := s.NominationComparisons(nomID); len(all) != 1 {
+		t.Fatalf("comparisons with deleted nominee must be gone: %v", all)
+	}
+
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomClosed); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := s.RecordComparison("u3", nomID, a, b, now); err != ErrVotingClosed {
+		t.Fatalf("expected ErrVotingClosed, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import "database/sql"
+
+// ---------- Ballot drafts ----------
+
+// SetBallotDraft запоминает выбор пользователя в мастере голосования; прежний выбор в номинации заменяется.
+// Номинант не из этой номинации — ErrNomineeMismatch, вне статуса open — ErrVotingClosed.
+func (s *Store) SetBallotDraft(userHash string, nominationID, nomineeID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkVotingOpen(tx, nominationID); err != nil {
+		return err
+	}
+
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM nominees WHERE id = ? AND nomination_id = ?`, nomineeID, nominationID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNomineeMismatch
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`
+INSERT INTO ballot_drafts(user_hash, nomination_id, nominee_id)
+VALUES (?, ?, ?)
+ON CONFLICT(user_hash, nomination_id) DO UPDATE SET nominee_id = excluded.nominee_id
+`, userHash, nominationID, nomineeID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteBallotDraft забывает выбор мастера в одной номинации.
+func (s *Store) DeleteBallotDraft(userHash string, nominationID int64) error {
+	_, err := s.db.Exec(`DELETE FROM ballot_drafts WHERE user_hash = ? AND nomination_id = ?`, userHash, nominationID)
+	return err
+}
+
+// BallotDrafts — выбор мастера во всех номинациях комнаты: номинация → номинант.
+func (s *Store) BallotDrafts(userHash string, roomID int64) (map[int64]int64, error) {
+	rows, err := s.db.Query(`
+SELECT d.nomination_id, d.nominee_id
+FROM ballot_drafts d
+JOIN nominations nom ON nom.id = d.nomination_id
+WHERE d.user_hash = ? AND nom.room_id = ?
+`, userHash, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	drafts := make(map[int64]int64)
+	for rows.Next() {
+		var nominationID, nomineeID int64
+		if err := rows.Scan(&nominationID, &nomineeID); err != nil {
+			return nil, err
+		}
+		drafts[nominationID] = nomineeID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+// ClearBallotDrafts удаляет весь выбор мастера в комнате — после подтверждения или выхода из мастера.
+func (s *Store) ClearBallotDrafts(userHash string, roomID int64) error {
+	_, err := s.db.Exec(`
+DELETE FROM ballot_drafts
+WHERE user_hash = ? AND nomination_id IN (SELECT id FROM nominations WHERE room_id = ?)
+`, userHash, roomID)
+	return err
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_BallotDrafts(t *testing.T) {
+	s, db := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomA, _ := s.CreateNomination(roomID, "A", "")
+	nomB, _ := s.CreateNomination(roomID, "B", "")
+	a1, _ := s.CreateNominee(nomA, "a1")
+	a2, _ := s.CreateNominee(nomA, "a2")
+	b1, _ := s.CreateNominee(nomB, "b1")
+
+	otherRoom := newOpenRoom(t, s, 2)
+	otherNom, _ := s.CreateNomination(otherRoom, "X", "")
+	x1, _ := s.CreateNominee(otherNom, "x1")
+
+	if err := s.SetBallotDraft("u1", nomA, b1); err != ErrNomineeMismatch {
+		t.Fatalf("foreign nominee: expected ErrNomineeMismatch, got %v", err)
+	}
+	for _, d := range [][2]int64{{nomA, a1}, {nomA, a2}, {nomB, b1}, {otherNom, x1}} {
+		if err := s.SetBallotDraft("u1", d[0], d[1]); err != nil {
+			t.Fatalf("SetBallotDraft(%d, %d): %v", d[0], d[1], err)
+		}
+	}
+	if err := s.SetBallotDraft("u2", nomA, a1); err != nil {
+		t.Fatalf("SetBallotDraft(u2): %v", err)
+	}
+
+	// выбор в номинации заменяется, чужая комната и чужой пользователь не видны
+	got, err := s.BallotDrafts("u1", roomID)
+	if err != nil {
+		t.Fatalf("BallotDrafts: %v", err)
+	}
+	if want := map[int64]int64{nomA: a2, nomB: b1}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("BallotDrafts = %v, want %v", got, want)
+	}
+	// черновик — не голос
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM votes`); n != 0 {
+		t.Fatalf("drafts must not create votes, got %d", n)
+	}
+
+	if err := s.DeleteBallotDraft("u1", nomB); err != nil {
+		t.Fatalf("DeleteBallotDraft: %v", err)
+	}
+	if got, _ := s.BallotDrafts("u1", roomID); !reflect.DeepEqual(got, map[int64]int64{nomA: a2}) {
+		t.Fatalf("after DeleteBallotDraft: %v", got)
+	}
+
+	if err := s.ClearBallotDrafts("u1", roomID); err != nil {
+		t.Fatalf("ClearBallotDrafts: %v", err)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM ballot_drafts WHERE user_hash = 'u1'`); n != 1 {
+		t.Fatalf("only the draft from the other room must survive, got %d", n)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM ballot_drafts WHERE user_hash = 'u2'`); n != 1 {
+		t.Fatalf("other user's draft must survive, got %d", n)
+	}
+
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomClosed); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := s.SetBallotDraft("u1", nomA, a1); err != ErrVotingClosed {
+		t.Fatalf("closed room: expected ErrVotingClosed, got %v", err)
+	}
+}
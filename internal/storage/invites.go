@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// ---------- Invites ----------
+
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func (s *Store) CreateInvite(inv domain.Invite) error {
+	_, err := s.db.Exec(`
+INSERT INTO room_invites(token, room_id, created_by, max_uses, expires_at, created_at, role, voter_group)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`, inv.Token, inv.RoomID, inv.CreatedBy, inv.MaxUses, nullTime(inv.ExpiresAt), inv.CreatedAt.UTC(), inv.Role, voterGroupOrDefault(inv.Group))
+	return err
+}
+
+func scanInvite(row interface{ Scan(...any) error }) (domain.Invite, error) {
+	var inv domain.Invite
+	var expiresAt, revokedAt sql.NullTime
+	var role, group string
+	if err := row.Scan(&inv.Token, &inv.RoomID, &inv.CreatedBy, &inv.MaxUses, &inv.Uses, &expiresAt, &revokedAt, &inv.CreatedAt, &role, &group); err != nil {
+		return domain.Invite{}, err
+	}
+	inv.ExpiresAt = expiresAt.Time
+	inv.RevokedAt = revokedAt.Time
+	inv.Role = domain.Role(role)
+	inv.Group = domain.VoterGroup(group)
+	return inv, nil
+}
+
+const inviteColumns = `token, room_id, created_by, max_uses, uses, expires_at, revoked_at, created_at, role, voter_group`
+
+func voterGroupOrDefault(g domain.VoterGroup) domain.VoterGroup {
+	if g == "" {
+		return domain.GroupAudience
+	}
+	return g
+}
+
+func (s *Store) GetInvite(token string) (*domain.Invite, error) {
+	inv, err := scanInvite(s.db.QueryRow(`SELECT `+inviteColumns+` FROM room_invites WHERE token = ?`, token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListInvites возвращает неотозванные приглашения комнаты, свежие первыми.
+func (s *Store) ListInvites(roomID int64) ([]domain.Invite, error) {
+	rows, err := s.db.Query(`
+SELECT `+inviteColumns+`
+FROM room_invites
+WHERE room_id = ? AND revoked_at IS NULL
+ORDER BY created_at DESC
+`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var invites []domain.Invite
+	for rows.Next() {
+		inv, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (s *Store) RevokeInvite(token string, now time.Time) (bool, error) {
+	res, err := s.db.Exec(`UPDATE room_invites SET revoked_at = ? WHERE token = ? AND revoked_at IS NULL`, now.UTC(), token)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RedeemInvite атомарно списывает одно использование, выдаёт пользователю роль из приглашения
+// (если она есть), записывает его участником комнаты в группу из приглашения и возвращает
+// комнату вместе с ролью и группой.
+// Отозванное, просроченное или исчерпанное приглашение — ErrNotFound.
+func (s *Store) RedeemInvite(token string, userID int64, userHash, displayName string, now time.Time) (*domain.Room, domain.Role, domain.VoterGroup, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, domain.RoleNone, "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var roomID int64
+	var role, group string
+	err = tx.QueryRow(`
+UPDATE room_invites
+SET uses = uses + 1
+WHERE token = ?
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > ?)
+  AND (max_uses = 0 OR uses < max_uses)
+RETURNING room_id, role, voter_group
+`, token, now.UTC()).Scan(&roomID, &role, &group)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.RoleNone, "", ErrNotFound
+		}
+		return nil, domain.RoleNone, "", err
+	}
+
+	if domain.Role(role) != domain.RoleNone {
+		if err := addRoomAdmin(tx, roomID, userID, domain.Role(role), displayName); err != nil {
+			return nil, domain.RoleNone, "", err
+		}
+	}
+	if err := addRoomVoter(tx, roomID, userHash, domain.VoterGroup(group), now); err != nil {
+		return nil, domain.RoleNone, "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domain.RoleNone, "", err
+	}
+
+	room, err := s.GetRoom(roomID)
+	if err != nil {
+		return nil, domain.RoleNone, "", err
+	}
+	return room, domain.Role(role), domain.VoterGroup(group), nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_RedeemInvite_MaxUsesExpiryRevoke(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, _ := s.CreateRoom(1, "room", "pw")
+	now := time.Unix(1_000_000, 0)
+
+	mustCreate := func(inv domain.Invite) {
+		t.Helper()
+		inv.RoomID = roomID
+		inv.CreatedBy = 1
+		inv.CreatedAt = now
+		if err := s.CreateInvite(inv); err != nil {
+			t.Fatalf("CreateInvite: %v", err)
+		}
+	}
+
+	mustCreate(domain.Invite{Token: "twice", MaxUses: 2})
+	mustCreate(domain.Invite{Token: "hour", ExpiresAt: now.Add(time.Hour)})
+	mustCreate(domain.Invite{Token: "revoked"})
+
+	// max_uses = 2: два входа, третий — нет
+	for i := 0; i < 2; i++ {
+		room, role, _, err := s.RedeemInvite("twice", 2, "h2", "", now)
+		if err != nil {
+			t.Fatalf("RedeemInvite(twice #%d): %v", i+1, err)
+		}
+		if room.ID != roomID || room.Title != "room" || role != domain.RoleNone {
+			t.Fatalf("unexpected room: %+v", room)
+		}
+	}
+	if _, _, _, err := s.RedeemInvite("twice", 2, "h2", "", now); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound after max uses, got %v", err)
+	}
+
+	// срок действия
+	if _, _, _, err := s.RedeemInvite("hour", 2, "h2", "", now.Add(59*time.Minute)); err != nil {
+		t.Fatalf("RedeemInvite(hour, before expiry): %v", err)
+	}
+	if _, _, _, err := s.RedeemInvite("hour", 2, "h2", "", now.Add(time.Hour)); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound after expiry, got %v", err)
+	}
+
+	// отзыв
+	revoked, err := s.RevokeInvite("revoked", now)
+	if err != nil || !revoked {
+		t.Fatalf("RevokeInvite: revoked=%v err=%v", revoked, err)
+	}
+	if _, _, _, err := s.RedeemInvite("revoked", 2, "h2", "", now); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for revoked invite, got %v", err)
+	}
+	if again, _ := s.RevokeInvite("revoked", now); again {
+		t.Fatalf("expected second revoke to be a no-op")
+	}
+
+	if _, _, _, err := s.RedeemInvite("unknown", 2, "h2", "", now); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for unknown token, got %v", err)
+	}
+
+	// в списке — только неотозванные, счётчик использований виден
+	invites, err := s.ListInvites(roomID)
+	if err != nil {
+		t.Fatalf("ListInvites: %v", err)
+	}
+	if len(invites) != 2 {
+		t.Fatalf("expected 2 non-revoked invites, got %+v", invites)
+	}
+	for _, inv := range invites {
+		if inv.Token == "twice" && (inv.Uses != 2 || inv.Usable(now)) {
+			t.Fatalf("unexpected state of used-up invite: %+v", inv)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// Области, по которым считаются неудачные попытки входа.
+const (
+	JoinScopeUser = "user"
+	JoinScopeRoom = "room"
+)
+
+// ---------- Join failures ----------
+
+func (s *Store) GetJoinFailure(scope string, subjectID int64) (domain.JoinFailure, error) {
+	var f domain.JoinFailure
+	var lockedUntil sql.NullTime
+	err := s.db.QueryRow(`SELECT failures, locked_until FROM join_failures WHERE scope = ? AND subject_id = ?`, scope, subjectID).
+		Scan(&f.Failures, &lockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.JoinFailure{}, nil
+		}
+		return domain.JoinFailure{}, err
+	}
+	f.LockedUntil = lockedUntil.Time
+	return f, nil
+}
+
+// RecordJoinFailure увеличивает счётчик и возвращает новое значение.
+// Если последняя неудача была раньше now-window, счёт начинается заново.
+func (s *Store) RecordJoinFailure(scope string, subjectID int64, now time.Time, window time.Duration) (int, error) {
+	// время храним в UTC, иначе строковое сравнение в SQLite врёт
+	now = now.UTC()
+
+	var failures int
+	err := s.db.QueryRow(`
+INSERT INTO join_failures(scope, subject_id, failures, updated_at)
+VALUES (?, ?, 1, ?)
+ON CONFLICT(scope, subject_id) DO UPDATE SET
+    failures = CASE WHEN join_failures.updated_at < ? THEN 1 ELSE join_failures.failures + 1 END,
+    updated_at = excluded.updated_at
+RETURNING failures
+`, scope, subjectID, now, now.Add(-window)).Scan(&failures)
+	if err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+func (s *Store) SetJoinLock(scope string, subjectID int64, until time.Time) error {
+	_, err := s.db.Exec(`UPDATE join_failures SET locked_until = ? WHERE scope = ? AND subject_id = ?`, until.UTC(), scope, subjectID)
+	return err
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// Migration — одна пронумерованная миграция: файл migrations/NNNN_name.sql
+// или Go-функция из codeMigrations (когда SQL не хватает, например для bcrypt).
+// AppliedAt нулевой, если миграция ещё не применена.
+type Migration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+
+	sql string
+	up  func(tx *sql.Tx) error
+}
+
+// codeMigrations нумеруются вместе с SQL-файлами и не должны пересекаться с ними по версии.
+var codeMigrations = []Migration{
+	{Version: 3, Name: "hash_room_passwords", up: migrateHashRoomPasswords},
+}
+
+func loadMigrations() ([]Migration, error) {
+	files, err := fs.Glob(embeddedMigrations, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, f := range files {
+		base := strings.TrimSuffix(strings.TrimPrefix(f, "migrations/"), ".sql")
+		num, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %q: expected NNNN_name.sql", f)
+		}
+		version, err := strconv.Atoi(num)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %q: bad version %q", f, num)
+		}
+		if prev, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migration version %d is used twice: %q and %q", version, prev, f)
+		}
+		seen[version] = f
+
+		b, err := embeddedMigrations.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, sql: strings.TrimSpace(string(b))})
+	}
+
+	for _, m := range codeMigrations {
+		if prev, dup := seen[m.Version]; dup {
+			return nil, fmt.Errorf("migration version %d is used twice: %q and %q", m.Version, prev, m.Name)
+		}
+		seen[m.Version] = m.Name
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func (s *Store) ensureMigrationsTable() error {
+	_, err := s.db.Exec(`
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at DATETIME NOT NULL
+)`)
+	return err
+}
+
+func (s *Store) appliedMigrations() (map[int]time.Time, error) {
+	rows, err := s.db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// MigrationStatus возвращает все известные миграции с отметкой, применены ли они.
+func (s *Store) MigrationStatus() ([]Migration, error) {
+	if err := s.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	for i := range migrations {
+		migrations[i].AppliedAt = applied[migrations[i].Version]
+	}
+	return migrations, nil
+}
+
+// Migrate применяет все ещё не применённые миграции по порядку, каждую в своей транзакции,
+// и возвращает список применённых за этот вызов.
+func (s *Store) Migrate() ([]Migration, error) {
+	// PRAGMA внутри транзакции игнорируется, поэтому включаем заранее (на пуле из одного соединения)
+	if _, err := s.db.Exec(`PRAGMA foreign_keys = ON;`); err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range statuses {
+		if !m.AppliedAt.IsZero() {
+			continue
+		}
+		m.AppliedAt = time.Now().UTC()
+		if err := s.applyMigration(m); err != nil {
+			return done, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+func (s *Store) applyMigration(m Migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if m.up != nil {
+		err = m.up(tx)
+	} else {
+		_, err = tx.Exec(m.sql)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations(version, name, applied_at) VALUES (?, ?, ?)`, m.Version, m.Name, m.AppliedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
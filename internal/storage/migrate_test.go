@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_Migrate_AppliesOnceAndReportsStatus(t *testing.T) {
+	s, db := newTestStore(t)
+
+	all, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if got := mustCount(t, db, `SELECT COUNT(*) FROM schema_migrations`); got != int64(len(all)) {
+		t.Fatalf("expected %d applied migrations, got %d", len(all), got)
+	}
+
+	// повторный запуск ничего не применяет
+	applied, err := s.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate(again): %v", err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("expected nothing to apply, got %+v", applied)
+	}
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus: %v", err)
+	}
+	if len(statuses) != len(all) {
+		t.Fatalf("expected %d statuses, got %d", len(all), len(statuses))
+	}
+	for i, m := range statuses {
+		if m.AppliedAt.IsZero() {
+			t.Fatalf("migration %04d_%s is not marked applied", m.Version, m.Name)
+		}
+		if i > 0 && statuses[i-1].Version >= m.Version {
+			t.Fatalf("migrations are not ordered: %+v", statuses)
+		}
+	}
+}
+
+func TestStore_Migrate_UpgradesLegacyInitSchemaDB(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	db.SetMaxOpenConns(1)
+
+	// так выглядела база, созданная старым InitSchema: таблицы есть, schema_migrations нет
+	if _, err := db.Exec(`
+CREATE TABLE rooms (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_user_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    password TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO rooms(owner_user_id, title, password) VALUES (1, 'old room', 'pw');
+`); err != nil {
+		t.Fatalf("legacy schema: %v", err)
+	}
+
+	s := New(db)
+	if _, err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	if got := mustCount(t, db, `SELECT COUNT(*) FROM rooms WHERE title = 'old room'`); got != 1 {
+		t.Fatalf("legacy room lost after migrate, count=%d", got)
+	}
+
+	// автор старой комнаты получил роль owner
+	if got := mustCount(t, db, `SELECT COUNT(*) FROM room_admins WHERE room_id = 1 AND user_id = 1 AND role = 'owner'`); got != 1 {
+		t.Fatalf("legacy owner was not backfilled into room_admins")
+	}
+
+	// открытый пароль заменён хэшем, но по-прежнему подходит
+	if got := mustCount(t, db, `SELECT COUNT(*) FROM rooms WHERE password_hash = 'pw'`); got != 0 {
+		t.Fatalf("legacy password was not rehashed")
+	}
+	if _, err := s.GetRoomByIDAndPassword(1, "pw"); err != nil {
+		t.Fatalf("GetRoomByIDAndPassword(legacy): %v", err)
+	}
+	if _, err := s.GetRoomByIDAndPassword(1, "nope"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for wrong legacy password, got %v", err)
+	}
+}
@@ -1,3 +1,4 @@
+-- IF NOT EXISTS: базы, созданные ещё через InitSchema, проходят эту миграцию без ошибок.
 CREATE TABLE IF NOT EXISTS rooms (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     owner_user_id INTEGER NOT NULL,
@@ -28,4 +29,4 @@ CREATE TABLE IF NOT EXISTS votes (
     nominee_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     UNIQUE (user_hash, nomination_id)
-);
\ No newline at end of file
+);
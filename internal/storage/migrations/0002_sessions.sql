@@ -0,0 +1,8 @@
+-- Сессии пользователей (активная комната, незаконченное создание номинанта).
+CREATE TABLE IF NOT EXISTS sessions (
+    user_id INTEGER PRIMARY KEY,
+    active_room_id INTEGER NOT NULL DEFAULT 0,
+    waiting_media_for_nominee_id INTEGER NOT NULL DEFAULT 0,
+    creating_nominee_for_nomination_id INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+-- Неудачные попытки /room ID Пароль: отдельно по пользователю (scope = 'user')
+-- и по комнате (scope = 'room'), чтобы блокировки переживали перезапуск.
+CREATE TABLE join_failures (
+    scope TEXT NOT NULL,
+    subject_id INTEGER NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    locked_until DATETIME,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (scope, subject_id)
+);
@@ -0,0 +1,14 @@
+-- Пригласительные токены для входа через t.me/<bot>?start=<token>.
+-- max_uses = 0 — без ограничения, expires_at IS NULL — бессрочно.
+CREATE TABLE room_invites (
+    token TEXT PRIMARY KEY,
+    room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    created_by INTEGER NOT NULL,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME,
+    revoked_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX room_invites_room_id ON room_invites(room_id);
@@ -0,0 +1,18 @@
+-- Роли в комнате: owner (автор), admin (соорганизатор), observer (только смотрит результаты).
+-- Все проверки прав идут через эту таблицу; rooms.owner_user_id остаётся как автор комнаты.
+CREATE TABLE room_admins (
+    room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'observer')),
+    display_name TEXT NOT NULL DEFAULT '',
+    added_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (room_id, user_id)
+);
+
+CREATE INDEX room_admins_user_id ON room_admins(user_id);
+
+INSERT INTO room_admins(room_id, user_id, role)
+SELECT id, owner_user_id, 'owner' FROM rooms;
+
+-- приглашение может выдавать роль (пусто — обычный участник)
+ALTER TABLE room_invites ADD COLUMN role TEXT NOT NULL DEFAULT '';
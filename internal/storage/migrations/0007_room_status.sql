@@ -0,0 +1,4 @@
+-- Жизненный цикл комнаты: draft -> open -> closed -> published.
+-- Существующие комнаты раньше принимали голоса всегда, поэтому они становятся open.
+ALTER TABLE rooms ADD COLUMN status TEXT NOT NULL DEFAULT 'open'
+    CHECK (status IN ('draft', 'open', 'closed', 'published'));
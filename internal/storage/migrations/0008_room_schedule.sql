@@ -0,0 +1,15 @@
+-- Запланированные переходы статуса комнаты (открыть/закрыть голосование).
+-- run_at хранится в UTC, timezone — как её указал автор, для показа.
+-- done_at IS NULL — задача ещё ждёт; после перезапуска планировщик подхватывает такие задачи.
+CREATE TABLE room_schedule (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    target_status TEXT NOT NULL CHECK (target_status IN ('open', 'closed')),
+    run_at DATETIME NOT NULL,
+    timezone TEXT NOT NULL,
+    created_by INTEGER NOT NULL,
+    done_at DATETIME,
+    result TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX room_schedule_pending ON room_schedule(done_at, run_at);
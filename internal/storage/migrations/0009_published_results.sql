@@ -0,0 +1,8 @@
+-- Номинации, итоги которых видны участникам, пока комната в статусе published.
+-- mode: winners — только победители, full — полные итоги с голосами.
+CREATE TABLE published_results (
+    nomination_id INTEGER PRIMARY KEY REFERENCES nominations(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL CHECK (mode IN ('winners', 'full')),
+    published_by INTEGER NOT NULL,
+    published_at DATETIME NOT NULL
+);
@@ -0,0 +1,21 @@
+-- Approval voting: в номинации можно отметить до max_choices номинантов (1 — обычный выбор одного).
+ALTER TABLE nominations ADD COLUMN max_choices INTEGER NOT NULL DEFAULT 1 CHECK (max_choices >= 1);
+
+-- Уникальность голоса теперь по (user_hash, nomination_id, nominee_id): у одного голосующего
+-- может быть несколько строк в номинации. SQLite не умеет менять UNIQUE, поэтому пересобираем таблицу.
+CREATE TABLE votes_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_hash TEXT NOT NULL,
+    nomination_id INTEGER NOT NULL REFERENCES nominations(id) ON DELETE CASCADE,
+    nominee_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_hash, nomination_id, nominee_id)
+);
+
+INSERT INTO votes_new(id, user_hash, nomination_id, nominee_id, created_at)
+SELECT id, user_hash, nomination_id, nominee_id, created_at FROM votes;
+
+DROP TABLE votes;
+ALTER TABLE votes_new RENAME TO votes;
+
+CREATE INDEX votes_nomination_user ON votes(nomination_id, user_hash);
@@ -0,0 +1,25 @@
+-- Тип номинации: plurality — выбор (одного или до max_choices), ranked — ранжирование (IRV).
+-- Без CHECK: новые типы добавляются кодом, а менять CHECK в SQLite можно только пересборкой таблицы.
+ALTER TABLE nominations ADD COLUMN kind TEXT NOT NULL DEFAULT 'plurality';
+
+-- Отправленные ранжированные бюллетени: rank 1 — самый желанный номинант.
+CREATE TABLE rankings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_hash TEXT NOT NULL,
+    nomination_id INTEGER NOT NULL REFERENCES nominations(id) ON DELETE CASCADE,
+    nominee_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_hash, nomination_id, nominee_id)
+);
+
+CREATE INDEX rankings_nomination_user ON rankings(nomination_id, user_hash, rank);
+
+-- Черновик бюллетеня, пока участник выбирает порядок; после подтверждения переезжает в rankings.
+CREATE TABLE ranking_drafts (
+    user_hash TEXT NOT NULL,
+    nomination_id INTEGER NOT NULL REFERENCES nominations(id) ON DELETE CASCADE,
+    nominee_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (user_hash, nomination_id, nominee_id)
+);
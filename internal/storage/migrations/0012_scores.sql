@@ -0,0 +1,12 @@
+-- Оценки номинантов от 1 до 5 в номинациях типа score: одна оценка на номинанта от голосующего.
+CREATE TABLE scores (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_hash TEXT NOT NULL,
+    nomination_id INTEGER NOT NULL REFERENCES nominations(id) ON DELETE CASCADE,
+    nominee_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score BETWEEN 1 AND 5),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_hash, nominee_id)
+);
+
+CREATE INDEX scores_nomination ON scores(nomination_id);
@@ -0,0 +1,2 @@
+-- Способ подсчёта ранжированных бюллетеней: irv — instant-runoff, borda — очки по местам.
+ALTER TABLE nominations ADD COLUMN tally_method TEXT NOT NULL DEFAULT 'irv';
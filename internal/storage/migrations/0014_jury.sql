@@ -0,0 +1,23 @@
+-- Участники комнаты и их группа голосующих: audience — зрители, jury — жюри.
+CREATE TABLE room_voters (
+    room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_hash TEXT NOT NULL,
+    voter_group TEXT NOT NULL DEFAULT 'audience' CHECK (voter_group IN ('audience', 'jury')),
+    joined_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (room_id, user_hash)
+);
+
+-- Кто уже голосовал, тот участник комнаты (зритель).
+INSERT OR IGNORE INTO room_voters(room_id, user_hash)
+SELECT DISTINCT nom.room_id, v.user_hash
+FROM votes v
+JOIN nominations nom ON nom.id = v.nomination_id;
+
+-- Приглашение в жюри — обычное приглашение с voter_group = 'jury'.
+ALTER TABLE room_invites ADD COLUMN voter_group TEXT NOT NULL DEFAULT 'audience';
+
+-- Группа голосующего на момент голоса.
+ALTER TABLE votes ADD COLUMN voter_group TEXT NOT NULL DEFAULT 'audience';
+
+-- Вес голосов жюри в процентах, остальное — зрители.
+ALTER TABLE rooms ADD COLUMN jury_weight INTEGER NOT NULL DEFAULT 50 CHECK (jury_weight BETWEEN 0 AND 100);
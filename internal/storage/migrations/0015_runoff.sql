@@ -0,0 +1,7 @@
+-- Номинация следующего тура (переголосование, финал) ссылается на исходную.
+ALTER TABLE nominations ADD COLUMN parent_nomination_id INTEGER REFERENCES nominations(id) ON DELETE SET NULL;
+
+CREATE INDEX nominations_parent ON nominations(parent_nomination_id);
+
+-- При закрытии голосования ничья на первом месте сама порождает переголосование.
+ALTER TABLE rooms ADD COLUMN auto_runoff INTEGER NOT NULL DEFAULT 0;
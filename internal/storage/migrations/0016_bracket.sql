@@ -0,0 +1,33 @@
+-- Турнирная сетка на выбывание (nominations.kind = 'bracket').
+-- Сколько минут длится матч; 0 — следующий матч запускает организатор.
+ALTER TABLE nominations ADD COLUMN match_minutes INTEGER NOT NULL DEFAULT 0 CHECK (match_minutes >= 0);
+
+-- Круги сетки: 1 — первый, последний — финал.
+CREATE TABLE bracket_rounds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    nomination_id INTEGER NOT NULL REFERENCES nominations(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL,
+    UNIQUE(nomination_id, number)
+);
+
+-- Матч: победитель матча position уходит в матч position/2 следующего круга.
+-- Пустое место (NULL) — соперника нет, второй проходит без голосования.
+CREATE TABLE bracket_matches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    round_id INTEGER NOT NULL REFERENCES bracket_rounds(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    nominee_a INTEGER REFERENCES nominees(id) ON DELETE SET NULL,
+    nominee_b INTEGER REFERENCES nominees(id) ON DELETE SET NULL,
+    winner_id INTEGER REFERENCES nominees(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'open', 'done')),
+    closes_at DATETIME,
+    UNIQUE(round_id, position)
+);
+
+CREATE TABLE match_votes (
+    match_id INTEGER NOT NULL REFERENCES bracket_matches(id) ON DELETE CASCADE,
+    user_hash TEXT NOT NULL,
+    nominee_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (match_id, user_hash)
+);
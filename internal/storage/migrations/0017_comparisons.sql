@@ -0,0 +1,15 @@
+-- Попарные сравнения в номинациях типа pairwise: голосующий выбрал winner_id из пары с loser_id.
+-- У голосующего одно мнение на пару: повторное сравнение той же пары заменяет прежнее.
+CREATE TABLE comparisons (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_hash TEXT NOT NULL,
+    nomination_id INTEGER NOT NULL REFERENCES nominations(id) ON DELETE CASCADE,
+    winner_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
+    loser_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (winner_id <> loser_id)
+);
+
+CREATE INDEX comparisons_nomination ON comparisons(nomination_id);
+CREATE UNIQUE INDEX comparisons_user_pair
+    ON comparisons(user_hash, nomination_id, MIN(winner_id, loser_id), MAX(winner_id, loser_id));
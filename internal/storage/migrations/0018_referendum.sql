@@ -0,0 +1,15 @@
+-- Референдум (nominations.kind = 'referendum'): вопрос с вариантами «за», «против», «воздержаться».
+-- Порог: majority — «за» больше, чем «против»; two_thirds — «за» не меньше 2/3 от «за» и «против».
+ALTER TABLE nominations ADD COLUMN pass_rule TEXT NOT NULL DEFAULT 'majority';
+
+-- Кворум в процентах от участников комнаты (room_voters); 0 — без кворума.
+ALTER TABLE nominations ADD COLUMN quorum_percent INTEGER NOT NULL DEFAULT 0 CHECK (quorum_percent BETWEEN 0 AND 100);
+
+-- Голоса референдума: один на участника, повторный голос заменяет прежний.
+CREATE TABLE referendum_votes (
+    nomination_id INTEGER NOT NULL REFERENCES nominations(id) ON DELETE CASCADE,
+    user_hash TEXT NOT NULL,
+    choice TEXT NOT NULL CHECK (choice IN ('yes', 'no', 'abstain')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (nomination_id, user_hash)
+);
@@ -0,0 +1,3 @@
+-- Можно ли отзывать голос после закрытия голосования (до публикации итогов); пока голосование открыто — можно всегда.
+-- По умолчанию нельзя: закрытое голосование окончательное, пока организатор не включит /withdraw_after_close.
+ALTER TABLE rooms ADD COLUMN withdraw_after_close INTEGER NOT NULL DEFAULT 0;
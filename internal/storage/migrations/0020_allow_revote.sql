@@ -0,0 +1,2 @@
+-- Можно ли менять голос в номинациях с выбором; 0 — первый голос окончательный (и отозвать его нельзя).
+ALTER TABLE rooms ADD COLUMN allow_revote INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,8 @@
+-- Выбор в пошаговом мастере голосования: пока участник не подтвердил его на экране проверки,
+-- это не голос. После подтверждения черновики переезжают в votes и удаляются.
+CREATE TABLE ballot_drafts (
+    user_hash TEXT NOT NULL,
+    nomination_id INTEGER NOT NULL REFERENCES nominations(id) ON DELETE CASCADE,
+    nominee_id INTEGER NOT NULL REFERENCES nominees(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_hash, nomination_id)
+);
@@ -0,0 +1,14 @@
+-- Telegram ID тех, кто входил в комнату, — адресаты уведомлений (например, о переголосовании).
+-- В votes и room_voters лежат только хэши, по ним написать человеку нельзя.
+CREATE TABLE room_members (
+    room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    joined_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (room_id, user_id)
+);
+
+-- Кто сейчас в комнате, тот в неё входил.
+INSERT OR IGNORE INTO room_members(room_id, user_id)
+SELECT active_room_id, user_id
+FROM sessions
+WHERE active_room_id IN (SELECT id FROM rooms);
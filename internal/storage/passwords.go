@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"database/sql"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPasswordTooLong — bcrypt учитывает только первые 72 байта пароля, длиннее не принимаем.
+var ErrPasswordTooLong = bcrypt.ErrPasswordTooLong
+
+// passwordCost — стоимость bcrypt; в тестах понижается до bcrypt.MinCost.
+var passwordCost = bcrypt.DefaultCost
+
+func hashPassword(password string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func checkPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+func isPasswordHash(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
+
+// migrateHashRoomPasswords переименовывает rooms.password в password_hash
+// и заменяет открытые пароли существующих комнат на bcrypt-хэши.
+func migrateHashRoomPasswords(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE rooms RENAME COLUMN password TO password_hash`); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`SELECT id, password_hash FROM rooms`)
+	if err != nil {
+		return err
+	}
+	plain := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var password string
+		if err := rows.Scan(&id, &password); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if !isPasswordHash(password) {
+			plain[id] = password
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return err
+	}
+	_ = rows.Close()
+
+	for id, password := range plain {
+		// старые пароли длиннее 72 байт всё равно не пройдут bcrypt — сверяем по первым 72
+		if len(password) > 72 {
+			password = password[:72]
+		}
+		hash, err := hashPassword(password)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE rooms SET password_hash = ? WHERE id = ?`, hash, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
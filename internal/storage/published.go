@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// ---------- Published results ----------
+
+// SetResultsMode задаёт, что участники увидят в номинации после публикации; ResultsHidden снимает её.
+func (s *Store) SetResultsMode(nominationID int64, mode domain.ResultsMode, by int64, now time.Time) error {
+	if mode == domain.ResultsHidden {
+		_, err := s.db.Exec(`DELETE FROM published_results WHERE nomination_id = ?`, nominationID)
+		return err
+	}
+	_, err := s.db.Exec(`
+INSERT INTO published_results(nomination_id, mode, published_by, published_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(nomination_id) DO UPDATE SET
+    mode = excluded.mode,
+    published_by = excluded.published_by,
+    published_at = excluded.published_at
+`, nominationID, mode, by, now.UTC())
+	return err
+}
+
+// GetResultsMode — режим публикации номинации; ResultsHidden, если она не выбрана.
+func (s *Store) GetResultsMode(nominationID int64) (domain.ResultsMode, error) {
+	var mode string
+	err := s.db.QueryRow(`SELECT mode FROM published_results WHERE nomination_id = ?`, nominationID).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return domain.ResultsHidden, nil
+	}
+	if err != nil {
+		return domain.ResultsHidden, err
+	}
+	return domain.ResultsMode(mode), nil
+}
+
+// ListResultsModes — режимы публикации всех выбранных номинаций комнаты.
+func (s *Store) ListResultsModes(roomID int64) (map[int64]domain.ResultsMode, error) {
+	rows, err := s.db.Query(`
+SELECT pr.nomination_id, pr.mode
+FROM published_results pr
+JOIN nominations nom ON nom.id = pr.nomination_id
+WHERE nom.room_id = ?
+`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	modes := make(map[int64]domain.ResultsMode)
+	for rows.Next() {
+		var id int64
+		var mode string
+		if err := rows.Scan(&id, &mode); err != nil {
+			return nil, err
+		}
+		modes[id] = domain.ResultsMode(mode)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return modes, nil
+}
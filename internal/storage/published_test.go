@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_ResultsModes(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, err := s.CreateRoom(1, "room", "pw")
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	nom1, err := s.CreateNomination(roomID, "n1", "")
+	if err != nil {
+		t.Fatalf("CreateNomination: %v", err)
+	}
+	nom2, err := s.CreateNomination(roomID, "n2", "")
+	if err != nil {
+		t.Fatalf("CreateNomination: %v", err)
+	}
+
+	mode, err := s.GetResultsMode(nom1)
+	if err != nil || mode != domain.ResultsHidden {
+		t.Fatalf("expected hidden by default, got %q err=%v", mode, err)
+	}
+
+	now := time.Now()
+	if err := s.SetResultsMode(nom1, domain.ResultsWinners, 1, now); err != nil {
+		t.Fatalf("SetResultsMode: %v", err)
+	}
+	if err := s.SetResultsMode(nom2, domain.ResultsFull, 1, now); err != nil {
+		t.Fatalf("SetResultsMode: %v", err)
+	}
+	// повторная публикация меняет режим
+	if err := s.SetResultsMode(nom1, domain.ResultsFull, 1, now); err != nil {
+		t.Fatalf("SetResultsMode(update): %v", err)
+	}
+
+	modes, err := s.ListResultsModes(roomID)
+	if err != nil {
+		t.Fatalf("ListResultsModes: %v", err)
+	}
+	if len(modes) != 2 || modes[nom1] != domain.ResultsFull || modes[nom2] != domain.ResultsFull {
+		t.Fatalf("unexpected modes: %v", modes)
+	}
+
+	if err := s.SetResultsMode(nom2, domain.ResultsHidden, 1, now); err != nil {
+		t.Fatalf("SetResultsMode(hide): %v", err)
+	}
+	// удаление номинации убирает и публикацию
+	if _, err := s.DeleteNomination(nom1); err != nil {
+		t.Fatalf("DeleteNomination: %v", err)
+	}
+
+	modes, err = s.ListResultsModes(roomID)
+	if err != nil {
+		t.Fatalf("ListResultsModes: %v", err)
+	}
+	if len(modes) != 0 {
+		t.Fatalf("expected no published nominations, got %v", modes)
+	}
+}
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+var (
+	// ErrEmptyBallot — попытка отправить бюллетень, в котором никто не выбран.
+	ErrEmptyBallot = errors.New("empty ballot")
+	// ErrIncompleteBallot — для подсчёта Борда в бюллетене должны быть все номинанты.
+	ErrIncompleteBallot = errors.New("ballot must rank every nominee")
+)
+
+// ---------- Nomination kind ----------
+
+// SetNominationKind меняет тип номинации, пока в ней нет ни голосов, ни бюллетеней, ни оценок, ни сетки
+// (иначе ErrHasVotes).
+func (s *Store) SetNominationKind(nominationID int64, kind domain.NominationKind) error {
+	return s.updateUnvotedNomination(nominationID, `kind = ?`, kind)
+}
+
+// SetNominationTally задаёт способ подсчёта и заодно делает номинацию ранжированной:
+// и IRV, и Борда работают только с ранжированными бюллетенями.
+func (s *Store) SetNominationTally(nominationID int64, method domain.TallyMethod) error {
+	return s.updateUnvotedNomination(nominationID, `kind = ?, tally_method = ?`, domain.KindRanked, method)
+}
+
+// updateUnvotedNomination — UPDATE nominations SET <set> при условии, что голосов любого типа ещё нет.
+func (s *Store) updateUnvotedNomination(nominationID int64, set string, args ...any) error {
+	args = append(args, nominationID, nominationID, nominationID, nominationID, nominationID, nominationID, nominationID)
+	res, err := s.db.Exec(`
+UPDATE nominations SET `+set+`
+WHERE id = ?
+  AND NOT EXISTS (SELECT 1 FROM votes WHERE nomination_id = ?)
+  AND NOT EXISTS (SELECT 1 FROM rankings WHERE nomination_id = ?)
+  AND NOT EXISTS (SELECT 1 FROM scores WHERE nomination_id = ?)
+  AND NOT EXISTS (SELECT 1 FROM bracket_rounds WHERE nomination_id = ?)
+  AND NOT EXISTS (SELECT 1 FROM comparisons WHERE nomination_id = ?)
+  AND NOT EXISTS (SELECT 1 FROM referendum_votes WHERE nomination_id = ?)
+`, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+	if _, err := s.GetNominationRoomID(nominationID); err != nil {
+		return err
+	}
+	return ErrHasVotes
+}
+
+// ---------- Rankings ----------
+
+// RankingDraft — номинанты, выбранные в черновике бюллетеня, по порядку.
+func (s *Store) RankingDraft(userHash string, nominationID int64) ([]int64, error) {
+	return s.listIDs(`
+SELECT nominee_id FROM ranking_drafts
+WHERE user_hash = ? AND nomination_id = ?
+ORDER BY position
+`, userHash, nominationID)
+}
+
+// AppendRankingDraft ставит номинанта следующим в черновик; повторное добавление ничего не меняет.
+// Номинант не из этой номинации — ErrNomineeMismatch, несуществующий — ErrNotFound.
+func (s *Store) AppendRankingDraft(userHash string, nominationID, nomineeID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var owner int64
+	err = tx.QueryRow(`SELECT nomination_id FROM nominees WHERE id = ?`, nomineeID).Scan(&owner)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if owner != nominationID {
+		return ErrNomineeMismatch
+	}
+
+	_, err = tx.Exec(`
+INSERT INTO ranking_drafts(user_hash, nomination_id, nominee_id, position)
+SELECT ?, ?, ?, IFNULL(MAX(position), 0) + 1
+FROM ranking_drafts
+WHERE user_hash = ? AND nomination_id = ?
+ON CONFLICT(user_hash, nomination_id, nominee_id) DO NOTHING
+`, userHash, nominationID, nomineeID, userHash, nominationID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) ClearRankingDraft(userHash string, nominationID int64) error {
+	_, err := s.db.Exec(`DELETE FROM ranking_drafts WHERE user_hash = ? AND nomination_id = ?`, userHash, nominationID)
+	return err
+}
+
+// SubmitRanking переносит черновик в rankings, заменяя прежний бюллетень пользователя.
+// Вне статуса open — ErrVotingClosed, пустой черновик — ErrEmptyBallot,
+// неполный порядок в номинации с подсчётом Борда — ErrIncompleteBallot. Без переголосования
+// (allow_revote) другой порядок поверх отправленного — ErrVoteLocked, тот же — ничего не меняет.
+func (s *Store) SubmitRanking(userHash string, nominationID int64, createdAt time.Time) ([]int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkVotingOpen(tx, nominationID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+SELECT nominee_id FROM ranking_drafts
+WHERE user_hash = ? AND nomination_id = ?
+ORDER BY position
+`, userHash, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	var order []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		order = append(order, id)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	_ = rows.Close()
+
+	if len(order) == 0 {
+		return nil, ErrEmptyBallot
+	}
+
+	var method domain.TallyMethod
+	var nominees int
+	err = tx.QueryRow(`
+SELECT nom.tally_method, (SELECT COUNT(*) FROM nominees n WHERE n.nomination_id = nom.id)
+FROM nominations nom
+WHERE nom.id = ?
+`, nominationID).Scan(&method, &nominees)
+	if err != nil {
+		return nil, err
+	}
+	if method == domain.TallyBorda && len(order) < nominees {
+		return nil, ErrIncompleteBallot
+	}
+
+	allow, err := allowRevote(tx, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	if !allow {
+		submitted, err := queryIDs(tx, `SELECT nominee_id FROM rankings WHERE user_hash = ? AND nomination_id = ? ORDER BY rank`,
+			userHash, nominationID)
+		if err != nil {
+			return nil, err
+		}
+		if len(submitted) > 0 && !slices.Equal(submitted, order) {
+			return nil, ErrVoteLocked
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM rankings WHERE user_hash = ? AND nomination_id = ?`, userHash, nominationID); err != nil {
+		return nil, err
+	}
+	for i, id := range order {
+		_, err := tx.Exec(`
+INSERT INTO rankings(user_hash, nomination_id, nominee_id, rank, created_at)
+VALUES (?, ?, ?, ?, ?)
+`, userHash, nominationID, id, i+1, createdAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM ranking_drafts WHERE user_hash = ? AND nomination_id = ?`, userHash, nominationID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// UserRanking — отправленный бюллетень пользователя по порядку (пусто, если не голосовал).
+func (s *Store) UserRanking(userHash string, nominationID int64) ([]int64, error) {
+	return s.listIDs(`
+SELECT nominee_id FROM rankings
+WHERE user_hash = ? AND nomination_id = ?
+ORDER BY rank
+`, userHash, nominationID)
+}
+
+// RankedBallots — все отправленные бюллетени номинации для подсчёта.
+func (s *Store) RankedBallots(nominationID int64) ([]tally.Ballot, error) {
+	rows, err := s.db.Query(`
+SELECT user_hash, nominee_id
+FROM rankings
+WHERE nomination_id = ?
+ORDER BY user_hash, rank
+`, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ballots []tally.Ballot
+	var current string
+	for rows.Next() {
+		var userHash string
+		var nomineeID int64
+		if err := rows.Scan(&userHash, &nomineeID); err != nil {
+			return nil, err
+		}
+		if len(ballots) == 0 || userHash != current {
+			ballots = append(ballots, nil)
+			current = userHash
+		}
+		ballots[len(ballots)-1] = append(ballots[len(ballots)-1], nomineeID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ballots, nil
+}
+
+func (s *Store) listIDs(query string, args ...any) ([]int64, error) {
+	return queryIDs(s.db, query, args...)
+}
+
+// queryIDs — первая колонка выборки как список ID; db — база или открытая транзакция.
+func queryIDs(db queryer, query string, args ...any) ([]int64, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestStore_RankedBallots(t *testing.T) {
+	s, db := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	c, _ := s.CreateNominee(nomID, "C")
+
+	if err := s.SetNominationKind(nomID, domain.KindRanked); err != nil {
+		t.Fatalf("SetNominationKind: %v", err)
+	}
+	if nom, err := s.GetNomination(nomID); err != nil || nom.Kind != domain.KindRanked {
+		t.Fatalf("GetNomination: %+v err=%v", nom, err)
+	}
+
+	now := time.Now()
+	if _, err := s.SubmitRanking("u1", nomID, now); err != ErrEmptyBallot {
+		t.Fatalf("expected ErrEmptyBallot, got %v", err)
+	}
+
+	for _, id := range []int64{b, a, b} { // повторный b игнорируется
+		if err := s.AppendRankingDraft("u1", nomID, id); err != nil {
+			t.Fatalf("AppendRankingDraft: %v", err)
+		}
+	}
+	draft, err := s.RankingDraft("u1", nomID)
+	if err != nil || !reflect.DeepEqual(draft, []int64{b, a}) {
+		t.Fatalf("draft: got=%v err=%v", draft, err)
+	}
+
+	order, err := s.SubmitRanking("u1", nomID, now)
+	if err != nil || !reflect.DeepEqual(order, []int64{b, a}) {
+		t.Fatalf("SubmitRanking: order=%v err=%v", order, err)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM ranking_drafts`); n != 0 {
+		t.Fatalf("draft should be cleared after submit, got %d rows", n)
+	}
+
+	// повторная отправка заменяет бюллетень целиком
+	_ = s.AppendRankingDraft("u1", nomID, c)
+	if _, err := s.SubmitRanking("u1", nomID, now); err != nil {
+		t.Fatalf("SubmitRanking(again): %v", err)
+	}
+	_ = s.AppendRankingDraft("u2", nomID, a)
+	_ = s.AppendRankingDraft("u2", nomID, c)
+	if _, err := s.SubmitRanking("u2", nomID, now); err != nil {
+		t.Fatalf("SubmitRanking(u2): %v", err)
+	}
+
+	mine, err := s.UserRanking("u1", nomID)
+	if err != nil || !reflect.DeepEqual(mine, []int64{c}) {
+		t.Fatalf("UserRanking: got=%v err=%v", mine, err)
+	}
+
+	ballots, err := s.RankedBallots(nomID)
+	if err != nil {
+		t.Fatalf("RankedBallots: %v", err)
+	}
+	if len(ballots) != 2 {
+		t.Fatalf("expected 2 ballots, got %v", ballots)
+	}
+	// порядок бюллетеней зависит от user_hash, поэтому сравниваем как множество
+	found := map[string]bool{}
+	for _, bl := range ballots {
+		found[fmt.Sprint(bl)] = true
+	}
+	if !found[fmt.Sprint(tally.Ballot{c})] || !found[fmt.Sprint(tally.Ballot{a, c})] {
+		t.Fatalf("unexpected ballots: %v", ballots)
+	}
+
+	// с бюллетенями тип номинации не меняется
+	if err := s.SetNominationKind(nomID, domain.KindPlurality); err != ErrHasVotes {
+		t.Fatalf("expected ErrHasVotes, got %v", err)
+	}
+
+	// после закрытия бюллетени не принимаются
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomClosed); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	_ = s.AppendRankingDraft("u3", nomID, a)
+	if _, err := s.SubmitRanking("u3", nomID, now); err != ErrVotingClosed {
+		t.Fatalf("expected ErrVotingClosed, got %v", err)
+	}
+}
+
+func TestStore_SubmitRanking_BordaNeedsFullOrder(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+
+	if err := s.SetNominationTally(nomID, domain.TallyBorda); err != nil {
+		t.Fatalf("SetNominationTally: %v", err)
+	}
+	nom, err := s.GetNomination(nomID)
+	if err != nil || nom.Kind != domain.KindRanked || nom.TallyMethod != domain.TallyBorda {
+		t.Fatalf("GetNomination: %+v err=%v", nom, err)
+	}
+
+	// номинант из другой номинации в черновик не попадает и не «дополняет» неполный порядок
+	otherNom, _ := s.CreateNomination(roomID, "Other", "")
+	foreign, _ := s.CreateNominee(otherNom, "X")
+	if err := s.AppendRankingDraft("u1", nomID, foreign); err != ErrNomineeMismatch {
+		t.Fatalf("foreign nominee: expected ErrNomineeMismatch, got %v", err)
+	}
+
+	_ = s.AppendRankingDraft("u1", nomID, b)
+	if _, err := s.SubmitRanking("u1", nomID, time.Now()); err != ErrIncompleteBallot {
+		t.Fatalf("expected ErrIncompleteBallot, got %v", err)
+	}
+
+	_ = s.AppendRankingDraft("u1", nomID, a)
+	if _, err := s.SubmitRanking("u1", nomID, time.Now()); err != nil {
+		t.Fatalf("SubmitRanking(full): %v", err)
+	}
+
+	if err := s.SetNominationTally(nomID, domain.TallyIRV); err != ErrHasVotes {
+		t.Fatalf("expected ErrHasVotes, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// ---------- Referendum ----------
+
+// SetReferendumRule делает номинацию референдумом с порогом rule и кворумом quorumPct процентов
+// участников комнаты (0 — без кворума). Только пока голосов нет, иначе ErrHasVotes.
+func (s *Store) SetReferendumRule(nominationID int64, rule domain.PassRule, quorumPct int) error {
+	return s.updateUnvotedNomination(nominationID, `kind = ?, pass_rule = ?, quorum_percent = ?`,
+		domain.KindReferendum, rule, quorumPct)
+}
+
+// RecordReferendumVote сохраняет (или меняет) ответ в референдуме. Вне статуса open — ErrVotingClosed.
+// Без переголосования (allow_revote) другой ответ поверх данного — ErrVoteLocked.
+func (s *Store) RecordReferendumVote(userHash string, nominationID int64, choice domain.ReferendumChoice, createdAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkVotingOpen(tx, nominationID); err != nil {
+		return err
+	}
+	allow, err := allowRevote(tx, nominationID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+INSERT INTO referendum_votes(nomination_id, user_hash, choice, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(nomination_id, user_hash) DO UPDATE SET
+    choice = excluded.choice,
+    created_at = excluded.created_at
+WHERE ?
+`, nominationID, userHash, choice, createdAt.UTC(), allow)
+	if err != nil {
+		return err
+	}
+	if err := checkFinalVote(tx, res, `SELECT choice = ? FROM referendum_votes WHERE nomination_id = ? AND user_hash = ?`,
+		choice, nominationID, userHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UserReferendumVote — ответ пользователя; пустая строка — ещё не голосовал.
+func (s *Store) UserReferendumVote(userHash string, nominationID int64) (domain.ReferendumChoice, error) {
+	var choice domain.ReferendumChoice
+	err := s.db.QueryRow(`SELECT choice FROM referendum_votes WHERE nomination_id = ? AND user_hash = ?`,
+		nominationID, userHash).Scan(&choice)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return choice, err
+}
+
+// ReferendumCounts — число ответов каждого варианта; варианты без голосов в карте отсутствуют.
+func (s *Store) ReferendumCounts(nominationID int64) (map[domain.ReferendumChoice]int, error) {
+	rows, err := s.db.Query(`SELECT choice, COUNT(*) FROM referendum_votes WHERE nomination_id = ? GROUP BY choice`, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[domain.ReferendumChoice]int)
+	for rows.Next() {
+		var choice domain.ReferendumChoice
+		var n int
+		if err := rows.Scan(&choice, &n); err != nil {
+			return nil, err
+		}
+		counts[choice] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
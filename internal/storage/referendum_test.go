@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_Referendum(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Поднять взносы", "")
+
+	if err := s.SetReferendumRule(nomID, domain.PassTwoThirds, 50); err != nil {
+		t.Fatalf("SetReferendumRule: %v", err)
+	}
+	nom, err := s.GetNomination(nomID)
+	if err != nil || nom.Kind != domain.KindReferendum || nom.PassRule != domain.PassTwoThirds || nom.QuorumPct != 50 {
+		t.Fatalf("GetNomination: %+v err=%v", nom, err)
+	}
+
+	now := time.Now()
+	must := func(user string, choice domain.ReferendumChoice) {
+		t.Helper()
+		if err := s.RecordReferendumVote(user, nomID, choice, now); err != nil {
+			t.Fatalf("RecordReferendumVote(%s, %s): %v", user, choice, err)
+		}
+	}
+	must("u1", domain.ChoiceNo)
+	must("u1", domain.ChoiceYes) // передумал — голос заменяется
+	must("u2", domain.ChoiceYes)
+	must("u3", domain.ChoiceAbstain)
+
+	if got, err := s.UserReferendumVote("u1", nomID); err != nil || got != domain.ChoiceYes {
+		t.Fatalf("UserReferendumVote: %q err=%v", got, err)
+	}
+	if got, err := s.UserReferendumVote("u9", nomID); err != nil || got != "" {
+		t.Fatalf("UserReferendumVote(no vote): %q err=%v", got, err)
+	}
+
+	counts, err := s.ReferendumCounts(nomID)
+	want := map[domain.ReferendumChoice]int{domain.ChoiceYes: 2, domain.ChoiceAbstain: 1}
+	if err != nil || !reflect.DeepEqual(counts, want) {
+		t.Fatalf("ReferendumCounts: %v err=%v", counts, err)
+	}
+
+	if err := s.SetReferendumRule(nomID, domain.PassMajority, 0); err != ErrHasVotes {
+		t.Fatalf("rule must be locked once voting started, got %v", err)
+	}
+
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomClosed); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := s.RecordReferendumVote("u4", nomID, domain.ChoiceNo, now); err != ErrVotingClosed {
+		t.Fatalf("expected ErrVotingClosed, got %v", err)
+	}
+}
+
+func TestStore_RoomVoterCount(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, _ := s.CreateRoom(1, "room", "pw")
+	now := time.Now()
+	for _, u := range []string{"a", "b", "a"} {
+		if err := s.AddRoomVoter(roomID, u, domain.GroupAudience, now); err != nil {
+			t.Fatalf("AddRoomVoter: %v", err)
+		}
+	}
+	if n, err := s.RoomVoterCount(roomID); err != nil || n != 2 {
+		t.Fatalf("RoomVoterCount: %d err=%v", n, err)
+	}
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrVoteLocked — в комнате выключено переголосование, а у пользователя уже есть другой голос.
+var ErrVoteLocked = errors.New("vote is final in this room")
+
+// ---------- Revote ----------
+
+// SetAllowRevote разрешает или запрещает менять и отзывать голос — в номинациях любого режима.
+func (s *Store) SetAllowRevote(roomID int64, on bool) error {
+	res, err := s.db.Exec(`UPDATE rooms SET allow_revote = ? WHERE id = ?`, on, roomID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// allowRevote — включено ли переголосование в комнате номинации. Читается в той же транзакции,
+// что и запись голоса, чтобы переключение настройки не проскочило между проверкой и записью.
+func allowRevote(tx *sql.Tx, nominationID int64) (bool, error) {
+	var allow bool
+	err := tx.QueryRow(`
+SELECT r.allow_revote
+FROM nominations nom
+JOIN rooms r ON r.id = nom.room_id
+WHERE nom.id = ?
+`, nominationID).Scan(&allow)
+	if err == sql.ErrNoRows {
+		return false, ErrNotFound
+	}
+	return allow, err
+}
+
+// checkFinalVote разбирает итог upsert'а с условием «WHERE allow_revote»: если строка не вставлена
+// и не обновлена, голос уже был, а переголосование выключено. same — запрос, отвечающий, совпадает ли
+// сохранённый голос с новым: повтор того же голоса не ошибка, другой — ErrVoteLocked.
+func checkFinalVote(tx *sql.Tx, res sql.Result, same string, args ...any) error {
+	affected, err := res.RowsAffected()
+	if err != nil || affected > 0 {
+		return err
+	}
+	var unchanged bool
+	if err := tx.QueryRow(same, args...).Scan(&unchanged); err != nil {
+		return err
+	}
+	if !unchanged {
+		return ErrVoteLocked
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/tally"
+)
+
+func TestStore_RecordVote_RevoteDisabled(t *testing.T) {
+	s, db := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	now := time.Now()
+
+	if room, _ := s.GetRoom(roomID); !room.AllowRevote {
+		t.Fatalf("revote must be allowed by default")
+	}
+	if err := s.SetAllowRevote(roomID, false); err != nil {
+		t.Fatalf("SetAllowRevote: %v", err)
+	}
+
+	if err := s.RecordVote("u1", nomID, a, now); err != nil {
+		t.Fatalf("first vote: %v", err)
+	}
+	if err := s.RecordVote("u1", nomID, b, now); err != ErrVoteLocked {
+		t.Fatalf("expected ErrVoteLocked, got %v", err)
+	}
+	// тот же номинант ещё раз — не ошибка и не второй голос
+	if err := s.RecordVote("u1", nomID, a, now); err != nil {
+		t.Fatalf("same nominee again: %v", err)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM votes WHERE user_hash = 'u1' AND nominee_id = ?`, a); n != 1 {
+		t.Fatalf("expected the first vote to stay, got %d rows", n)
+	}
+	if err := s.WithdrawVote("u1", nomID, a); err != ErrVoteLocked {
+		t.Fatalf("withdraw: expected ErrVoteLocked, got %v", err)
+	}
+
+	// другие участники голосуют как обычно
+	if err := s.RecordVote("u2", nomID, b, now); err != nil {
+		t.Fatalf("other voter: %v", err)
+	}
+
+	if err := s.SetAllowRevote(roomID, true); err != nil {
+		t.Fatalf("SetAllowRevote(on): %v", err)
+	}
+	if err := s.RecordVote("u1", nomID, b, now); err != nil {
+		t.Fatalf("revote after enabling: %v", err)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM votes WHERE user_hash = 'u1'`); n != 1 {
+		t.Fatalf("revote must replace the vote, got %d rows", n)
+	}
+
+	if err := s.SetAllowRevote(9999, false); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_RevoteDisabled_AllKinds(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	if err := s.SetAllowRevote(roomID, false); err != nil {
+		t.Fatalf("SetAllowRevote: %v", err)
+	}
+	now := time.Now()
+	newNom := func(name string) (int64, int64, int64) {
+		t.Helper()
+		nomID, _ := s.CreateNomination(roomID, name, "")
+		a, _ := s.CreateNominee(nomID, "A")
+		b, _ := s.CreateNominee(nomID, "B")
+		return nomID, a, b
+	}
+
+	t.Run("approval", func(t *testing.T) {
+		nomID, a, b := newNom("Approval")
+		if err := s.SetNominationMaxChoices(nomID, 2); err != nil {
+			t.Fatalf("SetNominationMaxChoices: %v", err)
+		}
+		if _, _, err := s.ToggleVote("u1", nomID, a, now); err != nil {
+			t.Fatalf("first mark: %v", err)
+		}
+		// новые отметки добавлять можно, снимать поставленные — нет
+		if _, _, err := s.ToggleVote("u1", nomID, b, now); err != nil {
+			t.Fatalf("second mark: %v", err)
+		}
+		if _, _, err := s.ToggleVote("u1", nomID, a, now); err != ErrVoteLocked {
+			t.Fatalf("unmark: expected ErrVoteLocked, got %v", err)
+		}
+		if voted, _ := s.UserNomineeVotes("u1", nomID); !voted[a] || !voted[b] {
+			t.Fatalf("marks must stay: %v", voted)
+		}
+	})
+
+	t.Run("ranked", func(t *testing.T) {
+		nomID, a, b := newNom("Ranked")
+		if err := s.SetNominationKind(nomID, domain.KindRanked); err != nil {
+			t.Fatalf("SetNominationKind: %v", err)
+		}
+		submit := func(order ...int64) error {
+			t.Helper()
+			for _, id := range order {
+				if err := s.AppendRankingDraft("u1", nomID, id); err != nil {
+					t.Fatalf("AppendRankingDraft: %v", err)
+				}
+			}
+			_, err := s.SubmitRanking("u1", nomID, now)
+			return err
+		}
+		if err := submit(a, b); err != nil {
+			t.Fatalf("first ballot: %v", err)
+		}
+		if err := submit(a, b); err != nil {
+			t.Fatalf("same ballot again: %v", err)
+		}
+		if err := submit(b, a); err != ErrVoteLocked {
+			t.Fatalf("other ballot: expected ErrVoteLocked, got %v", err)
+		}
+		if order, _ := s.UserRanking("u1", nomID); len(order) != 2 || order[0] != a {
+			t.Fatalf("first ballot must stay: %v", order)
+		}
+	})
+
+	t.Run("score", func(t *testing.T) {
+		nomID, a, b := newNom("Score")
+		if err := s.SetNominationKind(nomID, domain.KindScore); err != nil {
+			t.Fatalf("SetNominationKind: %v", err)
+		}
+		if err := s.RecordScore("u1", nomID, a, 3, now); err != nil {
+			t.Fatalf("first score: %v", err)
+		}
+		if err := s.RecordScore("u1", nomID, a, 3, now); err != nil {
+			t.Fatalf("same score again: %v", err)
+		}
+		if err := s.RecordScore("u1", nomID, a, 5, now); err != ErrVoteLocked {
+			t.Fatalf("other score: expected ErrVoteLocked, got %v", err)
+		}
+		if err := s.RecordScore("u1", nomID, b, 5, now); err != nil {
+			t.Fatalf("another nominee: %v", err)
+		}
+		if scores, _ := s.UserScores("u1", nomID); scores[a] != 3 || scores[b] != 5 {
+			t.Fatalf("unexpected scores: %v", scores)
+		}
+	})
+
+	t.Run("referendum", func(t *testing.T) {
+		nomID, _, _ := newNom("Referendum")
+		if err := s.SetReferendumRule(nomID, domain.PassMajority, 0); err != nil {
+			t.Fatalf("SetReferendumRule: %v", err)
+		}
+		if err := s.RecordReferendumVote("u1", nomID, domain.ChoiceYes, now); err != nil {
+			t.Fatalf("first answer: %v", err)
+		}
+		if err := s.RecordReferendumVote("u1", nomID, domain.ChoiceYes, now); err != nil {
+			t.Fatalf("same answer again: %v", err)
+		}
+		if err := s.RecordReferendumVote("u1", nomID, domain.ChoiceNo, now); err != ErrVoteLocked {
+			t.Fatalf("other answer: expected ErrVoteLocked, got %v", err)
+		}
+		if choice, _ := s.UserReferendumVote("u1", nomID); choice != domain.ChoiceYes {
+			t.Fatalf("first answer must stay: %q", choice)
+		}
+	})
+
+	t.Run("pairwise", func(t *testing.T) {
+		nomID, a, b := newNom("Pairwise")
+		if err := s.SetNominationKind(nomID, domain.KindPairwise); err != nil {
+			t.Fatalf("SetNominationKind: %v", err)
+		}
+		if err := s.RecordComparison("u1", nomID, a, b, now); err != nil {
+			t.Fatalf("first comparison: %v", err)
+		}
+		if err := s.RecordComparison("u1", nomID, a, b, now); err != nil {
+			t.Fatalf("same comparison again: %v", err)
+		}
+		if err := s.RecordComparison("u1", nomID, b, a, now); err != ErrVoteLocked {
+			t.Fatalf("other winner: expected ErrVoteLocked, got %v", err)
+		}
+		if all, _ := s.NominationComparisons(nomID); len(all) != 1 || all[0].Winner != a {
+			t.Fatalf("first comparison must stay: %v", all)
+		}
+	})
+
+	t.Run("bracket", func(t *testing.T) {
+		nomID, a, b := newNom("Bracket")
+		if err := s.SetNominationKind(nomID, domain.KindBracket); err != nil {
+			t.Fatalf("SetNominationKind: %v", err)
+		}
+		m, err := s.CreateBracket(nomID, tally.BracketPairs([]int64{a, b}), 0, now)
+		if err != nil || m == nil {
+			t.Fatalf("CreateBracket: %+v err=%v", m, err)
+		}
+		if err := s.RecordMatchVote("u1", m.ID, a, now); err != nil {
+			t.Fatalf("first match vote: %v", err)
+		}
+		if err := s.RecordMatchVote("u1", m.ID, a, now); err != nil {
+			t.Fatalf("same match vote again: %v", err)
+		}
+		if err := s.RecordMatchVote("u1", m.ID, b, now); err != ErrVoteLocked {
+			t.Fatalf("other match vote: expected ErrVoteLocked, got %v", err)
+		}
+		if got, _ := s.UserMatchVote("u1", m.ID); got != a {
+			t.Fatalf("first match vote must stay: %d", got)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"database/sql"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// ---------- Rounds ----------
+
+// CreateNextRound создаёт номинацию следующего тура со ссылкой на parentID и копирует в неё
+// выбранных номинантов (имя и медиа) в порядке nomineeIDs. Номинанты из других номинаций
+// пропускаются. Голоса в parentID после этого не принимаются (см. checkVotingOpen).
+// Лимит отметок (max_choices) переносится, но не больше «номинантов минус один»: иначе в
+// переголосовании из двух лидеров можно отметить обоих, и ничья повторится.
+func (s *Store) CreateNextRound(parentID int64, name, description string, nomineeIDs []int64) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var nominationID int64
+	err = tx.QueryRow(`
+INSERT INTO nominations(room_id, name, description, parent_nomination_id)
+SELECT room_id, ?, ?, id FROM nominations WHERE id = ?
+RETURNING id
+`, name, description, parentID).Scan(&nominationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	for _, id := range nomineeIDs {
+		_, err := tx.Exec(`
+INSERT INTO nominees(nomination_id, name, media_file_id, media_type)
+SELECT ?, name, media_file_id, media_type FROM nominees WHERE id = ? AND nomination_id = ?
+`, nominationID, id, parentID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(`
+UPDATE nominations
+SET max_choices = MAX(1, MIN(
+    (SELECT max_choices FROM nominations WHERE id = ?),
+    (SELECT COUNT(*) FROM nominees WHERE nomination_id = ?) - 1
+))
+WHERE id = ?
+`, parentID, nominationID, nominationID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return nominationID, nil
+}
+
+// HasNextRound сообщает, есть ли у номинации следующий тур.
+func (s *Store) HasNextRound(nominationID int64) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM nominations WHERE parent_nomination_id = ?)`, nominationID).Scan(&exists)
+	return exists, err
+}
+
+// SetAutoRunoff включает или выключает автоматическое переголосование при ничьей.
+func (s *Store) SetAutoRunoff(roomID int64, on bool) error {
+	res, err := s.db.Exec(`UPDATE rooms SET auto_runoff = ? WHERE id = ?`, on, roomID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// NominationVoterHashes — хэши всех, кто голосовал в номинации с выбором (таблица votes).
+func (s *Store) NominationVoterHashes(nominationID int64) (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT user_hash FROM votes WHERE nomination_id = ?`, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	hashes := make(map[string]bool)
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		hashes[h] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// RoundHistory — все туры, в которые входит номинация: от первого до последнего.
+// Следующий тур у номинации один (после него она заморожена), так что история — цепочка.
+func (s *Store) RoundHistory(nominationID int64) ([]domain.Nomination, error) {
+	rows, err := s.db.Query(`
+WITH RECURSIVE
+up(id, parent, depth) AS (
+    SELECT id, parent_nomination_id, 0 FROM nominations WHERE id = ?
+    UNION ALL
+    SELECT n.id, n.parent_nomination_id, up.depth - 1 FROM nominations n JOIN up ON n.id = up.parent
+),
+down(id, depth) AS (
+    SELECT id, 0 FROM nominations WHERE id = ?
+    UNION ALL
+    SELECT n.id, down.depth + 1 FROM nominations n JOIN down ON n.parent_nomination_id = down.id
+),
+chain(id, depth) AS (
+    SELECT id, depth FROM up
+    UNION
+    SELECT id, depth FROM down
+)
+SELECT n.id, n.room_id, n.name, IFNULL(n.description, ''), n.max_choices, n.kind, n.tally_method, IFNULL(n.parent_nomination_id, 0), n.match_minutes,
+       n.pass_rule, n.quorum_percent
+FROM chain c
+JOIN nominations n ON n.id = c.id
+ORDER BY c.depth, n.id
+`, nominationID, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var noms []domain.Nomination
+	for rows.Next() {
+		var n domain.Nomination
+		if err := rows.Scan(&n.ID, &n.RoomID, &n.Name, &n.Description, &n.MaxChoices, &n.Kind, &n.TallyMethod, &n.ParentID, &n.MatchMins,
+			&n.PassRule, &n.QuorumPct); err != nil {
+			return nil, err
+		}
+		noms = append(noms, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(noms) == 0 {
+		return nil, ErrNotFound
+	}
+	return noms, nil
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore_CreateNextRound_CopiesNomineesAndFreezesParent(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	c, _ := s.CreateNominee(nomID, "C")
+	if err := s.UpdateNomineeMedia(b, "file-b", "photo"); err != nil {
+		t.Fatalf("UpdateNomineeMedia: %v", err)
+	}
+
+	otherNom, _ := s.CreateNomination(roomID, "Other", "")
+	foreign, _ := s.CreateNominee(otherNom, "X")
+
+	now := time.Now()
+	if err := s.RecordVote("u1", nomID, a, now); err != nil {
+		t.Fatalf("RecordVote: %v", err)
+	}
+	if err := s.RecordVote("u2", nomID, b, now); err != nil {
+		t.Fatalf("RecordVote: %v", err)
+	}
+
+	// порядок nomineeIDs сохраняется, чужой номинант пропускается
+	runoffID, err := s.CreateNextRound(nomID, "Nom — переголосование", "tie", []int64{b, a, foreign})
+	if err != nil {
+		t.Fatalf("CreateNextRound: %v", err)
+	}
+
+	runoff, err := s.GetNomination(runoffID)
+	if err != nil || runoff.ParentID != nomID || runoff.RoomID != roomID || runoff.Description != "tie" {
+		t.Fatalf("unexpected runoff nomination: %+v err=%v", runoff, err)
+	}
+	nominees, err := s.ListNominees(runoffID)
+	if err != nil {
+		t.Fatalf("ListNominees: %v", err)
+	}
+	if len(nominees) != 2 || nominees[0].Name != "B" || nominees[1].Name != "A" {
+		t.Fatalf("unexpected runoff nominees: %+v", nominees)
+	}
+	if nominees[0].MediaFileID != "file-b" || nominees[0].MediaType != "photo" || nominees[1].MediaFileID != "" {
+		t.Fatalf("media not copied: %+v", nominees)
+	}
+
+	if next, err := s.HasNextRound(nomID); err != nil || !next {
+		t.Fatalf("HasNextRound(parent): %v err=%v", next, err)
+	}
+	if next, err := s.HasNextRound(runoffID); err != nil || next {
+		t.Fatalf("HasNextRound(runoff): %v err=%v", next, err)
+	}
+
+	// исходная номинация заморожена, в переголосовании голосовать можно
+	if err := s.RecordVote("u3", nomID, c, now); err != ErrNextRound {
+		t.Fatalf("expected ErrNextRound, got %v", err)
+	}
+	if err := s.RecordVote("u1", runoffID, nominees[0].ID, now); err != nil {
+		t.Fatalf("RecordVote(runoff): %v", err)
+	}
+
+	hashes, err := s.NominationVoterHashes(nomID)
+	if err != nil || len(hashes) != 2 || !hashes["u1"] || !hashes["u2"] {
+		t.Fatalf("NominationVoterHashes: %v err=%v", hashes, err)
+	}
+
+	// approval-номинация: лимит переносится, но в туре из двух можно отметить только одного
+	approval, _ := s.CreateNomination(roomID, "Approval", "")
+	var approvalIDs []int64
+	for _, name := range []string{"P", "Q", "R", "S"} {
+		id, _ := s.CreateNominee(approval, name)
+		approvalIDs = append(approvalIDs, id)
+	}
+	if err := s.SetNominationMaxChoices(approval, 3); err != nil {
+		t.Fatalf("SetNominationMaxChoices: %v", err)
+	}
+	for _, tc := range []struct {
+		ids  []int64
+		want int
+	}{{approvalIDs[:4], 3}, {approvalIDs[:3], 2}, {approvalIDs[:2], 1}} {
+		nextID, err := s.CreateNextRound(approval, "next", "", tc.ids)
+		if err != nil {
+			t.Fatalf("CreateNextRound(approval): %v", err)
+		}
+		next, _ := s.GetNomination(nextID)
+		if next.MaxChoices != tc.want {
+			t.Fatalf("%d nominees: max_choices = %d, want %d", len(tc.ids), next.MaxChoices, tc.want)
+		}
+	}
+
+	if _, err := s.CreateNextRound(nomID+100, "x", "", nil); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_RoundHistory(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, _ := s.CreateRoom(1, "room", "pw")
+	long, _ := s.CreateNomination(roomID, "Long", "")
+	a, _ := s.CreateNominee(long, "A")
+	semi, err := s.CreateNextRound(long, "Semi", "", []int64{a})
+	if err != nil {
+		t.Fatalf("CreateNextRound(semi): %v", err)
+	}
+	final, err := s.CreateNextRound(semi, "Final", "", nil)
+	if err != nil {
+		t.Fatalf("CreateNextRound(final): %v", err)
+	}
+	single, _ := s.CreateNomination(roomID, "Single", "")
+
+	// из любого тура видна вся цепочка
+	for _, id := range []int64{long, semi, final} {
+		rounds, err := s.RoundHistory(id)
+		if err != nil {
+			t.Fatalf("RoundHistory(%d): %v", id, err)
+		}
+		if len(rounds) != 3 || rounds[0].ID != long || rounds[1].ID != semi || rounds[2].ID != final {
+			t.Fatalf("RoundHistory(%d): %+v", id, rounds)
+		}
+	}
+
+	if rounds, err := s.RoundHistory(single); err != nil || len(rounds) != 1 {
+		t.Fatalf("RoundHistory(single): %+v err=%v", rounds, err)
+	}
+	if _, err := s.RoundHistory(single + 100); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// ---------- Schedule ----------
+
+// ReplaceRoomSchedule отменяет ожидающие задачи комнаты и ставит новые (можно пустой список).
+func (s *Store) ReplaceRoomSchedule(roomID int64, jobs []domain.ScheduledTransition) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM room_schedule WHERE room_id = ? AND done_at IS NULL`, roomID); err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		_, err := tx.Exec(`
+INSERT INTO room_schedule(room_id, target_status, run_at, timezone, created_by)
+VALUES (?, ?, ?, ?, ?)
+`, roomID, j.To, j.RunAt.UTC(), j.Timezone, j.CreatedBy)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) listTransitions(query string, args ...any) ([]domain.ScheduledTransition, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var jobs []domain.ScheduledTransition
+	for rows.Next() {
+		var j domain.ScheduledTransition
+		if err := rows.Scan(&j.ID, &j.RoomID, &j.To, &j.RunAt, &j.Timezone, &j.CreatedBy); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ListRoomSchedule — ожидающие задачи комнаты по времени.
+func (s *Store) ListRoomSchedule(roomID int64) ([]domain.ScheduledTransition, error) {
+	return s.listTransitions(`
+SELECT id, room_id, target_status, run_at, timezone, created_by
+FROM room_schedule
+WHERE room_id = ? AND done_at IS NULL
+ORDER BY run_at
+`, roomID)
+}
+
+// DueTransitions — задачи, время которых наступило к now (включая пропущенные, пока бот лежал).
+func (s *Store) DueTransitions(now time.Time) ([]domain.ScheduledTransition, error) {
+	return s.listTransitions(`
+SELECT id, room_id, target_status, run_at, timezone, created_by
+FROM room_schedule
+WHERE done_at IS NULL AND run_at <= ?
+ORDER BY run_at, id
+`, now.UTC())
+}
+
+func (s *Store) MarkTransitionDone(id int64, now time.Time, result string) error {
+	_, err := s.db.Exec(`UPDATE room_schedule SET done_at = ?, result = ? WHERE id = ?`, now.UTC(), result, id)
+	return err
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_RoomSchedule(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, err := s.CreateRoom(1, "room", "pw")
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	msk := time.FixedZone("MSK", 3*60*60)
+	jobs := []domain.ScheduledTransition{
+		{RoomID: roomID, To: domain.RoomClosed, RunAt: now.Add(2 * time.Hour), Timezone: "UTC", CreatedBy: 1},
+		// то же «через час», но в другой таймзоне — в БД должно лечь в UTC
+		{RoomID: roomID, To: domain.RoomOpen, RunAt: now.Add(time.Hour).In(msk), Timezone: "Europe/Moscow", CreatedBy: 1},
+	}
+	if err := s.ReplaceRoomSchedule(roomID, jobs); err != nil {
+		t.Fatalf("ReplaceRoomSchedule: %v", err)
+	}
+
+	list, err := s.ListRoomSchedule(roomID)
+	if err != nil {
+		t.Fatalf("ListRoomSchedule: %v", err)
+	}
+	if len(list) != 2 || list[0].To != domain.RoomOpen || list[1].To != domain.RoomClosed {
+		t.Fatalf("unexpected schedule: %+v", list)
+	}
+	if !list[0].RunAt.Equal(now.Add(time.Hour)) || list[0].Timezone != "Europe/Moscow" {
+		t.Fatalf("unexpected open job: %+v", list[0])
+	}
+
+	// ничего ещё не наступило
+	due, err := s.DueTransitions(now)
+	if err != nil {
+		t.Fatalf("DueTransitions: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected no due jobs, got %+v", due)
+	}
+
+	// бот «проспал» оба перехода — отдаём оба по порядку
+	due, err = s.DueTransitions(now.Add(3 * time.Hour))
+	if err != nil {
+		t.Fatalf("DueTransitions: %v", err)
+	}
+	if len(due) != 2 || due[0].To != domain.RoomOpen {
+		t.Fatalf("unexpected due jobs: %+v", due)
+	}
+
+	if err := s.MarkTransitionDone(due[0].ID, now.Add(3*time.Hour), "ok"); err != nil {
+		t.Fatalf("MarkTransitionDone: %v", err)
+	}
+	due, err = s.DueTransitions(now.Add(3 * time.Hour))
+	if err != nil {
+		t.Fatalf("DueTransitions: %v", err)
+	}
+	if len(due) != 1 || due[0].To != domain.RoomClosed {
+		t.Fatalf("expected only close job left, got %+v", due)
+	}
+
+	// замена расписания убирает только ожидающие задачи
+	if err := s.ReplaceRoomSchedule(roomID, nil); err != nil {
+		t.Fatalf("ReplaceRoomSchedule(nil): %v", err)
+	}
+	list, err = s.ListRoomSchedule(roomID)
+	if err != nil {
+		t.Fatalf("ListRoomSchedule: %v", err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("expected empty schedule, got %+v", list)
+	}
+}
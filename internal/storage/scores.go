@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrNomineeMismatch — номинант не из этой номинации (устаревшая или подделанная кнопка).
+var ErrNomineeMismatch = errors.New("nominee does not belong to nomination")
+
+// ---------- Scores ----------
+
+// RecordScore сохраняет (или меняет) оценку номинанта. Вне статуса open — ErrVotingClosed.
+// Без переголосования (allow_revote) другая оценка поверх поставленной — ErrVoteLocked.
+func (s *Store) RecordScore(userHash string, nominationID, nomineeID int64, score int, createdAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkVotingOpen(tx, nominationID); err != nil {
+		return err
+	}
+
+	var owner int64
+	err = tx.QueryRow(`SELECT nomination_id FROM nominees WHERE id = ?`, nomineeID).Scan(&owner)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if owner != nominationID {
+		return ErrNomineeMismatch
+	}
+	allow, err := allowRevote(tx, nominationID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+INSERT INTO scores(user_hash, nomination_id, nominee_id, score, created_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(user_hash, nominee_id) DO UPDATE SET
+    score = excluded.score,
+    created_at = excluded.created_at
+WHERE ?
+`, userHash, nominationID, nomineeID, score, createdAt, allow)
+	if err != nil {
+		return err
+	}
+	if err := checkFinalVote(tx, res, `SELECT score = ? FROM scores WHERE user_hash = ? AND nominee_id = ?`,
+		score, userHash, nomineeID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UserScores — оценки пользователя в номинации: nomineeID -> 1..5.
+func (s *Store) UserScores(userHash string, nominationID int64) (map[int64]int, error) {
+	rows, err := s.db.Query(`SELECT nominee_id, score FROM scores WHERE user_hash = ? AND nomination_id = ?`, userHash, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	scores := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var score int
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, err
+		}
+		scores[id] = score
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// NominationScores — все оценки номинации по номинантам, для tally.RankByScore.
+func (s *Store) NominationScores(nominationID int64) (map[int64][]int, error) {
+	rows, err := s.db.Query(`SELECT nominee_id, score FROM scores WHERE nomination_id = ?`, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	scores := make(map[int64][]int)
+	for rows.Next() {
+		var id int64
+		var score int
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, err
+		}
+		scores[id] = append(scores[id], score)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_Scores(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	otherNom, _ := s.CreateNomination(roomID, "Other", "")
+	foreign, _ := s.CreateNominee(otherNom, "X")
+
+	if err := s.SetNominationKind(nomID, domain.KindScore); err != nil {
+		t.Fatalf("SetNominationKind: %v", err)
+	}
+
+	now := time.Now()
+	must := func(user string, nominee int64, score int) {
+		t.Helper()
+		if err := s.RecordScore(user, nomID, nominee, score, now); err != nil {
+			t.Fatalf("RecordScore(%s, %d, %d): %v", user, nominee, score, err)
+		}
+	}
+	must("u1", a, 5)
+	must("u1", a, 4) // повторная оценка заменяет прежнюю
+	must("u1", b, 2)
+	must("u2", a, 3)
+
+	if err := s.RecordScore("u1", nomID, foreign, 5, now); err != ErrNomineeMismatch {
+		t.Fatalf("expected ErrNomineeMismatch, got %v", err)
+	}
+
+	mine, err := s.UserScores("u1", nomID)
+	if err != nil || !reflect.DeepEqual(mine, map[int64]int{a: 4, b: 2}) {
+		t.Fatalf("UserScores: got=%v err=%v", mine, err)
+	}
+
+	all, err := s.NominationScores(nomID)
+	if err != nil {
+		t.Fatalf("NominationScores: %v", err)
+	}
+	sort.Ints(all[a])
+	if !reflect.DeepEqual(all[a], []int{3, 4}) || !reflect.DeepEqual(all[b], []int{2}) {
+		t.Fatalf("unexpected scores: %v", all)
+	}
+
+	if err := s.SetNominationKind(nomID, domain.KindPlurality); err != ErrHasVotes {
+		t.Fatalf("expected ErrHasVotes, got %v", err)
+	}
+
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomClosed); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := s.RecordScore("u3", nomID, a, 1, now); err != ErrVotingClosed {
+		t.Fatalf("expected ErrVotingClosed, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/session"
+)
+
+// Store реализует session.Store — сессии переживают перезапуск бота.
+var _ session.Store = (*Store)(nil)
+
+// ---------- Sessions ----------
+
+func (s *Store) LoadSession(userID int64) (*session.Session, error) {
+	row := s.db.QueryRow(`
+SELECT active_room_id, waiting_media_for_nominee_id, creating_nominee_for_nomination_id
+FROM sessions
+WHERE user_id = ?
+`, userID)
+	var sess session.Session
+	if err := row.Scan(&sess.ActiveRoomID, &sess.WaitingMediaForNomineeID, &sess.CreatingNomineeForNominationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &sess, nil
+}
+
+func (s *Store) SaveSession(userID int64, sess *session.Session) error {
+	_, err := s.db.Exec(`
+INSERT INTO sessions(user_id, active_room_id, waiting_media_for_nominee_id, creating_nominee_for_nomination_id, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(user_id) DO UPDATE SET
+    active_room_id = excluded.active_room_id,
+    waiting_media_for_nominee_id = excluded.waiting_media_for_nominee_id,
+    creating_nominee_for_nomination_id = excluded.creating_nominee_for_nomination_id,
+    updated_at = excluded.updated_at
+`, userID, sess.ActiveRoomID, sess.WaitingMediaForNomineeID, sess.CreatingNomineeForNominationID, time.Now())
+	return err
+}
@@ -2,18 +2,20 @@ package storage
 
 import (
 	"database/sql"
-	"embed"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/maaaruch/tg-vote-bot/internal/domain"
 )
 
-//go:embed schema.sql
-var embeddedSchema embed.FS
-
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrVotingClosed      = errors.New("voting is not open")
+	ErrInvalidTransition = errors.New("invalid room status transition")
+	ErrTooManyChoices    = errors.New("max choices reached")
+	ErrHasVotes          = errors.New("nomination already has votes")
+	ErrNextRound         = errors.New("nomination moved to the next round")
+)
 
 type Store struct {
 	db *sql.DB
@@ -23,25 +25,21 @@ func New(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) InitSchema() error {
-	if _, err := s.db.Exec(`PRAGMA foreign_keys = ON;`); err != nil {
-		return err
-	}
+// ---------- Rooms ----------
 
-	b, err := embeddedSchema.ReadFile("schema.sql")
+func (s *Store) CreateRoom(ownerID int64, title, password string) (int64, error) {
+	hash, err := hashPassword(password)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	schema := strings.TrimSpace(string(b))
-	_, err = s.db.Exec(schema)
-	return err
-}
-
-// ---------- Rooms ----------
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
 
-func (s *Store) CreateRoom(ownerID int64, title, password string) (int64, error) {
-	res, err := s.db.Exec(`INSERT INTO rooms(owner_user_id, title, password) VALUES (?, ?, ?)`, ownerID, title, password)
+	res, err := tx.Exec(`INSERT INTO rooms(owner_user_id, title, password_hash, status) VALUES (?, ?, ?, ?)`, ownerID, title, hash, domain.RoomDraft)
 	if err != nil {
 		return 0, err
 	}
@@ -49,35 +47,37 @@ func (s *Store) CreateRoom(ownerID int64, title, password string) (int64, error)
 	if err != nil {
 		return 0, err
 	}
+	if _, err := tx.Exec(`INSERT INTO room_admins(room_id, user_id, role) VALUES (?, ?, ?)`, id, ownerID, domain.RoleOwner); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
-func (s *Store) ListRoomsByOwner(ownerID int64) ([]domain.Room, error) {
-	rows, err := s.db.Query(`SELECT id, title FROM rooms WHERE owner_user_id = ? ORDER BY created_at DESC`, ownerID)
+// GetRoomByIDAndPassword сверяет пароль с bcrypt-хэшем в Go.
+// Неверный пароль неотличим от несуществующей комнаты — в обоих случаях ErrNotFound.
+func (s *Store) GetRoomByIDAndPassword(id int64, password string) (*domain.Room, error) {
+	r, err := s.GetRoom(id)
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = rows.Close() }()
-
-	var rooms []domain.Room
-	for rows.Next() {
-		var r domain.Room
-		r.OwnerUserID = ownerID
-		if err := rows.Scan(&r.ID, &r.Title); err != nil {
-			return nil, err
-		}
-		rooms = append(rooms, r)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	if !checkPassword(r.PasswordHash, password) {
+		return nil, ErrNotFound
 	}
-	return rooms, nil
+	return r, nil
 }
 
-func (s *Store) GetRoomByIDAndPassword(id int64, password string) (*domain.Room, error) {
-	row := s.db.QueryRow(`SELECT id, owner_user_id, title, password, created_at FROM rooms WHERE id = ? AND password = ?`, id, password)
+func (s *Store) GetRoom(id int64) (*domain.Room, error) {
+	row := s.db.QueryRow(`
+SELECT id, owner_user_id, title, password_hash, status, jury_weight, auto_runoff, withdraw_after_close, allow_revote, created_at
+FROM rooms
+WHERE id = ?
+`, id)
 	var r domain.Room
-	if err := row.Scan(&r.ID, &r.OwnerUserID, &r.Title, &r.Password, &r.CreatedAt); err != nil {
+	if err := row.Scan(&r.ID, &r.OwnerUserID, &r.Title, &r.PasswordHash, &r.Status, &r.JuryWeight, &r.AutoRunoff, &r.WithdrawAfterClose,
+		&r.AllowRevote, &r.CreatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
 		}
@@ -86,13 +86,57 @@ func (s *Store) GetRoomByIDAndPassword(id int64, password string) (*domain.Room,
 	return &r, nil
 }
 
-func (s *Store) IsRoomOwner(roomID, userID int64) (bool, error) {
-	var cnt int
-	err := s.db.QueryRow(`SELECT COUNT(1) FROM rooms WHERE id = ? AND owner_user_id = ?`, roomID, userID).Scan(&cnt)
+func (s *Store) GetRoomOwnerID(roomID int64) (int64, error) {
+	var ownerID int64
+	err := s.db.QueryRow(`SELECT owner_user_id FROM rooms WHERE id = ?`, roomID).Scan(&ownerID)
 	if err != nil {
-		return false, err
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
 	}
-	return cnt > 0, nil
+	return ownerID, nil
+}
+
+func (s *Store) GetRoomStatus(roomID int64) (domain.RoomStatus, error) {
+	var status domain.RoomStatus
+	err := s.db.QueryRow(`SELECT status FROM rooms WHERE id = ?`, roomID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return status, nil
+}
+
+// TransitionRoomStatus переводит комнату в статус to и возвращает прежний статус.
+// Недопустимый переход (см. RoomStatus.CanTransition) — ErrInvalidTransition.
+func (s *Store) TransitionRoomStatus(roomID int64, to domain.RoomStatus) (domain.RoomStatus, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var from domain.RoomStatus
+	if err := tx.QueryRow(`SELECT status FROM rooms WHERE id = ?`, roomID).Scan(&from); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if !from.CanTransition(to) {
+		return from, ErrInvalidTransition
+	}
+
+	if _, err := tx.Exec(`UPDATE rooms SET status = ? WHERE id = ?`, to, roomID); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return from, nil
 }
 
 func (s *Store) GetRoomTitle(roomID int64) (string, error) {
@@ -110,7 +154,13 @@ func (s *Store) GetRoomTitle(roomID int64) (string, error) {
 // ---------- Nominations ----------
 
 func (s *Store) ListNominations(roomID int64) ([]domain.Nomination, error) {
-	rows, err := s.db.Query(`SELECT id, name, description FROM nominations WHERE room_id = ? ORDER BY id`, roomID)
+	rows, err := s.db.Query(`
+SELECT id, name, IFNULL(description, ''), max_choices, kind, tally_method, IFNULL(parent_nomination_id, 0), match_minutes,
+       pass_rule, quorum_percent
+FROM nominations
+WHERE room_id = ?
+ORDER BY id
+`, roomID)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +170,8 @@ func (s *Store) ListNominations(roomID int64) ([]domain.Nomination, error) {
 	for rows.Next() {
 		var n domain.Nomination
 		n.RoomID = roomID
-		if err := rows.Scan(&n.ID, &n.Name, &n.Description); err != nil {
+		if err := rows.Scan(&n.ID, &n.Name, &n.Description, &n.MaxChoices, &n.Kind, &n.TallyMethod, &n.ParentID, &n.MatchMins,
+			&n.PassRule, &n.QuorumPct); err != nil {
 			return nil, err
 		}
 		noms = append(noms, n)
@@ -155,18 +206,45 @@ func (s *Store) DeleteNomination(nominationID int64) (bool, error) {
 	return affected > 0, nil
 }
 
-func (s *Store) IsNominationOwner(nominationID, userID int64) (bool, error) {
-	var cnt int
+func (s *Store) GetNomination(nominationID int64) (*domain.Nomination, error) {
+	var n domain.Nomination
 	err := s.db.QueryRow(`
-SELECT COUNT(1)
-FROM nominations nom
-JOIN rooms r ON nom.room_id = r.id
-WHERE nom.id = ? AND r.owner_user_id = ?
-`, nominationID, userID).Scan(&cnt)
+SELECT id, room_id, name, IFNULL(description, ''), max_choices, kind, tally_method, IFNULL(parent_nomination_id, 0), match_minutes,
+       pass_rule, quorum_percent
+FROM nominations
+WHERE id = ?
+`, nominationID).Scan(&n.ID, &n.RoomID, &n.Name, &n.Description, &n.MaxChoices, &n.Kind, &n.TallyMethod, &n.ParentID, &n.MatchMins,
+		&n.PassRule, &n.QuorumPct)
 	if err != nil {
-		return false, err
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
 	}
-	return cnt > 0, nil
+	return &n, nil
+}
+
+// SetNominationMaxChoices меняет лимит отметок, пока в номинации нет ни одного голоса (иначе ErrHasVotes):
+// уже отданные голоса не должны внезапно оказаться сверх лимита.
+func (s *Store) SetNominationMaxChoices(nominationID int64, maxChoices int) error {
+	res, err := s.db.Exec(`
+UPDATE nominations SET max_choices = ?
+WHERE id = ? AND NOT EXISTS (SELECT 1 FROM votes WHERE nomination_id = ?)
+`, maxChoices, nominationID, nominationID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+	if _, err := s.GetNominationRoomID(nominationID); err != nil {
+		return err
+	}
+	return ErrHasVotes
 }
 
 func (s *Store) GetNominationRoomID(nominationID int64) (int64, error) {
@@ -259,21 +337,6 @@ func (s *Store) DeleteNominee(nomineeID int64) (bool, error) {
 	return affected > 0, nil
 }
 
-func (s *Store) IsNomineeOwner(nomineeID, userID int64) (bool, error) {
-	var cnt int
-	err := s.db.QueryRow(`
-SELECT COUNT(1)
-FROM nominees n
-JOIN nominations nom ON n.nomination_id = nom.id
-JOIN rooms r ON nom.room_id = r.id
-WHERE n.id = ? AND r.owner_user_id = ?
-`, nomineeID, userID).Scan(&cnt)
-	if err != nil {
-		return false, err
-	}
-	return cnt > 0, nil
-}
-
 func (s *Store) UpdateNomineeMedia(nomineeID int64, fileID, mediaType string) error {
 	_, err := s.db.Exec(`UPDATE nominees SET media_file_id = ?, media_type = ? WHERE id = ?`, fileID, mediaType, nomineeID)
 	return err
@@ -309,17 +372,212 @@ WHERE n.id = ?
 
 // ---------- Votes / Results ----------
 
+// RecordVote сохраняет голос в номинации с выбором одного: предыдущий голос пользователя заменяется.
+// Если в комнате выключено переголосование (allow_revote), другой голос поверх уже отданного —
+// ErrVoteLocked, а повторный голос за того же номинанта ничего не меняет.
+// Если комната не в статусе open — ErrVotingClosed; проверки и запись идут в одной транзакции,
+// так что закрытие не «проскочит» между ними.
 func (s *Store) RecordVote(userHash string, nominationID, nomineeID int64, createdAt time.Time) error {
-	_, err := s.db.Exec(`
-INSERT INTO votes(user_hash, nomination_id, nominee_id, created_at)
-VALUES (?, ?, ?, ?)
-ON CONFLICT(user_hash, nomination_id) DO UPDATE SET
-    nominee_id = excluded.nominee_id,
-    created_at = excluded.created_at
-`, userHash, nominationID, nomineeID, createdAt)
-	return err
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkVotingOpen(tx, nominationID); err != nil {
+		return err
+	}
+
+	allow, err := allowRevote(tx, nominationID)
+	if err != nil {
+		return err
+	}
+	if allow {
+		if _, err := tx.Exec(`DELETE FROM votes WHERE user_hash = ? AND nomination_id = ?`, userHash, nominationID); err != nil {
+			return err
+		}
+	}
+
+	// без переголосования голос вставляется, только если его ещё нет, — проверка и вставка одной командой
+	res, err := tx.Exec(`
+INSERT INTO votes(user_hash, nomination_id, nominee_id, created_at, voter_group)
+SELECT ?, ?, ?, ?, `+voterGroupExpr+`
+WHERE NOT EXISTS (SELECT 1 FROM votes WHERE user_hash = ? AND nomination_id = ?)
+`, userHash, nominationID, nomineeID, createdAt, nominationID, userHash, userHash, nominationID)
+	if err != nil {
+		return err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		var current int64
+		err := tx.QueryRow(`SELECT nominee_id FROM votes WHERE user_hash = ? AND nomination_id = ? LIMIT 1`,
+			userHash, nominationID).Scan(&current)
+		if err != nil {
+			return err
+		}
+		if current != nomineeID {
+			return ErrVoteLocked
+		}
+	}
+	return tx.Commit()
+}
+
+// ToggleVote ставит или снимает отметку с номинанта в номинации с max_choices > 1.
+// Возвращает, поставлена ли отметка, и сколько номинантов у пользователя отмечено теперь.
+// Сверх лимита — ErrTooManyChoices, вне статуса open — ErrVotingClosed. Без переголосования
+// (allow_revote) отметки можно только добавлять: снять поставленную — ErrVoteLocked.
+func (s *Store) ToggleVote(userHash string, nominationID, nomineeID int64, createdAt time.Time) (added bool, selected int, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkVotingOpen(tx, nominationID); err != nil {
+		return false, 0, err
+	}
+	allow, err := allowRevote(tx, nominationID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// без переголосования отметка снимается «никогда»: удаление пропускаем, а найденная
+	// отметка означает попытку её снять
+	res, err := tx.Exec(`DELETE FROM votes WHERE user_hash = ? AND nomination_id = ? AND nominee_id = ? AND ?`,
+		userHash, nominationID, nomineeID, allow)
+	if err != nil {
+		return false, 0, err
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return false, 0, err
+	}
+	if !allow {
+		var marked bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM votes WHERE user_hash = ? AND nomination_id = ? AND nominee_id = ?)`,
+			userHash, nominationID, nomineeID).Scan(&marked)
+		if err != nil {
+			return false, 0, err
+		}
+		if marked {
+			return false, 0, ErrVoteLocked
+		}
+	}
+
+	var maxChoices int
+	err = tx.QueryRow(`
+SELECT nom.max_choices, (SELECT COUNT(*) FROM votes v WHERE v.nomination_id = nom.id AND v.user_hash = ?)
+FROM nominations nom
+WHERE nom.id = ?
+`, userHash, nominationID).Scan(&maxChoices, &selected)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if removed == 0 {
+		if selected >= maxChoices {
+			return false, selected, ErrTooManyChoices
+		}
+		_, err = tx.Exec(`
+INSERT INTO votes(user_hash, nomination_id, nominee_id, created_at, voter_group)
+VALUES (?, ?, ?, ?, `+voterGroupExpr+`)
+`, userHash, nominationID, nomineeID, createdAt, nominationID, userHash)
+		if err != nil {
+			return false, 0, err
+		}
+		added = true
+		selected++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, 0, err
+	}
+	return added, selected, nil
+}
+
+// UserNomineeVotes — номинанты, за которых пользователь голосовал в номинации.
+func (s *Store) UserNomineeVotes(userHash string, nominationID int64) (map[int64]bool, error) {
+	rows, err := s.db.Query(`SELECT nominee_id FROM votes WHERE user_hash = ? AND nomination_id = ?`, userHash, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	voted := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		voted[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return voted, nil
+}
+
+// VotedNominations — номинации комнаты, в которых пользователь уже проголосовал любым способом:
+// выбор, отправленный бюллетень, оценка, сравнение, ответ референдума или голос в матче сетки.
+func (s *Store) VotedNominations(userHash string, roomID int64) (map[int64]bool, error) {
+	ids, err := s.listIDs(`
+SELECT nom.id
+FROM nominations nom
+WHERE nom.room_id = ? AND (
+    EXISTS (SELECT 1 FROM votes WHERE nomination_id = nom.id AND user_hash = ?)
+    OR EXISTS (SELECT 1 FROM rankings WHERE nomination_id = nom.id AND user_hash = ?)
+    OR EXISTS (SELECT 1 FROM scores WHERE nomination_id = nom.id AND user_hash = ?)
+    OR EXISTS (SELECT 1 FROM comparisons WHERE nomination_id = nom.id AND user_hash = ?)
+    OR EXISTS (SELECT 1 FROM referendum_votes WHERE nomination_id = nom.id AND user_hash = ?)
+    OR EXISTS (
+        SELECT 1
+        FROM match_votes mv
+        JOIN bracket_matches m ON m.id = mv.match_id
+        JOIN bracket_rounds r ON r.id = m.round_id
+        WHERE r.nomination_id = nom.id AND mv.user_hash = ?
+    )
+)
+`, roomID, userHash, userHash, userHash, userHash, userHash, userHash)
+	if err != nil {
+		return nil, err
+	}
+	voted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		voted[id] = true
+	}
+	return voted, nil
+}
+
+// checkVotingOpen: голосовать можно в открытой комнате и только в последнем туре номинации —
+// у номинации, ушедшей в переголосование или финал, голоса заморожены (ErrNextRound).
+func checkVotingOpen(tx *sql.Tx, nominationID int64) error {
+	var open, nextRound bool
+	err := tx.QueryRow(`
+SELECT r.status = 'open',
+       EXISTS (SELECT 1 FROM nominations c WHERE c.parent_nomination_id = nom.id)
+FROM nominations nom
+JOIN rooms r ON r.id = nom.room_id
+WHERE nom.id = ?
+`, nominationID).Scan(&open, &nextRound)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !open {
+		return ErrVotingClosed
+	}
+	if nextRound {
+		return ErrNextRound
+	}
+	return nil
 }
 
+// ResultsByNomination — число голосов (в approval-номинациях — отметок) у каждого номинанта.
 func (s *Store) ResultsByNomination(nominationID int64) ([]domain.NomineeResult, error) {
 	rows, err := s.db.Query(`
 SELECT n.id, n.name, COUNT(v.id) as votes
@@ -2,13 +2,25 @@ package storage
 
 import (
 	"database/sql"
+	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+	"github.com/maaaruch/tg-vote-bot/internal/session"
 )
 
+func TestMain(m *testing.M) {
+	// bcrypt с DefaultCost заметно тормозит тесты, а стойкость тут не нужна
+	passwordCost = bcrypt.MinCost
+	os.Exit(m.Run())
+}
+
 func newTestStore(t *testing.T) (*Store, *sql.DB) {
 	t.Helper()
 
@@ -23,12 +35,26 @@ func newTestStore(t *testing.T) (*Store, *sql.DB) {
 	db.SetMaxOpenConns(1)
 
 	s := New(db)
-	if err := s.InitSchema(); err != nil {
-		t.Fatalf("init schema: %v", err)
+	if _, err := s.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
 	}
 	return s, db
 }
 
+// newOpenRoom создаёт комнату и сразу открывает голосование (новые комнаты стартуют в draft).
+func newOpenRoom(t *testing.T, s *Store, ownerID int64) int64 {
+	t.Helper()
+
+	roomID, err := s.CreateRoom(ownerID, "room", "pw")
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomOpen); err != nil {
+		t.Fatalf("open room: %v", err)
+	}
+	return roomID
+}
+
 func mustCount(t *testing.T, db *sql.DB, q string, args ...any) int64 {
 	t.Helper()
 	var n int64
@@ -39,13 +65,18 @@ func mustCount(t *testing.T, db *sql.DB, q string, args ...any) int64 {
 }
 
 func TestStore_CreateRoom_AndJoinByPassword(t *testing.T) {
-	s, _ := newTestStore(t)
+	s, db := newTestStore(t)
 
 	roomID, err := s.CreateRoom(777, "test room", "secret")
 	if err != nil {
 		t.Fatalf("CreateRoom: %v", err)
 	}
 
+	// пароль в открытом виде в БД не лежит
+	if got := mustCount(t, db, `SELECT COUNT(*) FROM rooms WHERE password_hash LIKE '%secret%'`); got != 0 {
+		t.Fatalf("password stored in plaintext")
+	}
+
 	// ok password
 	room, err := s.GetRoomByIDAndPassword(roomID, "secret")
 	if err != nil {
@@ -68,7 +99,7 @@ func TestStore_CreateRoom_AndJoinByPassword(t *testing.T) {
 func TestStore_RecordVote_UpsertPerNomination(t *testing.T) {
 	s, db := newTestStore(t)
 
-	roomID, _ := s.CreateRoom(1, "room", "pw")
+	roomID := newOpenRoom(t, s, 1)
 	nomID, _ := s.CreateNomination(roomID, "Best dev", "desc")
 	aliceID, _ := s.CreateNominee(nomID, "Alice")
 	bobID, _ := s.CreateNominee(nomID, "Bob")
@@ -113,7 +144,7 @@ func TestStore_RecordVote_UpsertPerNomination(t *testing.T) {
 func TestStore_DeleteNominee_CascadesVotes(t *testing.T) {
 	s, db := newTestStore(t)
 
-	roomID, _ := s.CreateRoom(1, "room", "pw")
+	roomID := newOpenRoom(t, s, 1)
 	nomID, _ := s.CreateNomination(roomID, "Nom", "")
 	nomineeID, _ := s.CreateNominee(nomID, "Victim")
 
@@ -141,7 +172,7 @@ func TestStore_DeleteNominee_CascadesVotes(t *testing.T) {
 func TestStore_DeleteNomination_CascadesNomineesAndVotes(t *testing.T) {
 	s, db := newTestStore(t)
 
-	roomID, _ := s.CreateRoom(1, "room", "pw")
+	roomID := newOpenRoom(t, s, 1)
 	nomID, _ := s.CreateNomination(roomID, "Nom", "")
 	n1, _ := s.CreateNominee(nomID, "A")
 	n2, _ := s.CreateNominee(nomID, "B")
@@ -171,3 +202,156 @@ func TestStore_DeleteNomination_CascadesNomineesAndVotes(t *testing.T) {
 		t.Fatalf("expected 0 votes after delete, got %d", got)
 	}
 }
+
+func TestStore_Sessions_SaveAndLoad(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	got, err := s.LoadSession(42)
+	if err != nil {
+		t.Fatalf("LoadSession(empty): %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected nil session for unknown user, got %+v", got)
+	}
+
+	want := &session.Session{ActiveRoomID: 5, WaitingMediaForNomineeID: 7, CreatingNomineeForNominationID: 0}
+	if err := s.SaveSession(42, want); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+
+	// повторное сохранение должно перезаписать, а не дублировать
+	want.ActiveRoomID = 6
+	if err := s.SaveSession(42, want); err != nil {
+		t.Fatalf("SaveSession(update): %v", err)
+	}
+
+	got, err = s.LoadSession(42)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if got == nil || *got != *want {
+		t.Fatalf("session mismatch: got=%+v want=%+v", got, want)
+	}
+}
+
+func TestStore_JoinFailures_CountLockAndReset(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	now := time.Unix(1_000_000, 0)
+
+	for i := 1; i <= 3; i++ {
+		n, err := s.RecordJoinFailure(JoinScopeUser, 7, now.Add(time.Duration(i)*time.Minute), time.Hour)
+		if err != nil {
+			t.Fatalf("RecordJoinFailure: %v", err)
+		}
+		if n != i {
+			t.Fatalf("expected %d failures, got %d", i, n)
+		}
+	}
+
+	// другая область считается отдельно
+	if n, _ := s.RecordJoinFailure(JoinScopeRoom, 7, now, time.Hour); n != 1 {
+		t.Fatalf("expected room scope to start from 1, got %d", n)
+	}
+
+	until := now.Add(time.Hour)
+	if err := s.SetJoinLock(JoinScopeUser, 7, until); err != nil {
+		t.Fatalf("SetJoinLock: %v", err)
+	}
+	f, err := s.GetJoinFailure(JoinScopeUser, 7)
+	if err != nil {
+		t.Fatalf("GetJoinFailure: %v", err)
+	}
+	if f.Failures != 3 || !f.LockedUntil.Equal(until) {
+		t.Fatalf("unexpected failure state: %+v", f)
+	}
+
+	// после окна тишины счёт начинается заново
+	if n, _ := s.RecordJoinFailure(JoinScopeUser, 7, now.Add(3*time.Hour), time.Hour); n != 1 {
+		t.Fatalf("expected counter reset after window, got %d", n)
+	}
+
+}
+
+func TestStore_RoomStatus_TransitionsAndVoteGate(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, _ := s.CreateRoom(1, "room", "pw")
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	nomineeID, _ := s.CreateNominee(nomID, "A")
+
+	if st, _ := s.GetRoomStatus(roomID); st != domain.RoomDraft {
+		t.Fatalf("new room must start as draft, got %q", st)
+	}
+
+	// в черновике голосовать нельзя
+	if err := s.RecordVote("u1", nomID, nomineeID, time.Now()); err != ErrVotingClosed {
+		t.Fatalf("expected ErrVotingClosed in draft, got %v", err)
+	}
+
+	// черновик нельзя сразу закрыть
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomClosed); err != ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition draft->closed, got %v", err)
+	}
+
+	if from, err := s.TransitionRoomStatus(roomID, domain.RoomOpen); err != nil || from != domain.RoomDraft {
+		t.Fatalf("draft->open: from=%q err=%v", from, err)
+	}
+	if err := s.RecordVote("u1", nomID, nomineeID, time.Now()); err != nil {
+		t.Fatalf("RecordVote(open): %v", err)
+	}
+
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomClosed); err != nil {
+		t.Fatalf("open->closed: %v", err)
+	}
+	if err := s.RecordVote("u2", nomID, nomineeID, time.Now()); err != ErrVotingClosed {
+		t.Fatalf("expected ErrVotingClosed after close, got %v", err)
+	}
+
+	room, err := s.GetRoom(roomID)
+	if err != nil {
+		t.Fatalf("GetRoom: %v", err)
+	}
+	if room.Status != domain.RoomClosed {
+		t.Fatalf("unexpected status: %q", room.Status)
+	}
+}
+
+func TestStore_VotedNominations_AllKinds(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	now := time.Now()
+
+	plural, _ := s.CreateNomination(roomID, "Plurality", "")
+	p1, _ := s.CreateNominee(plural, "A")
+	scored, _ := s.CreateNomination(roomID, "Score", "")
+	s1, _ := s.CreateNominee(scored, "A")
+	_ = s.SetNominationKind(scored, domain.KindScore)
+	ref, _ := s.CreateNomination(roomID, "Referendum", "")
+	_ = s.SetReferendumRule(ref, domain.PassMajority, 0)
+	untouched, _ := s.CreateNomination(roomID, "Untouched", "")
+	u1, _ := s.CreateNominee(untouched, "A")
+
+	otherRoom := newOpenRoom(t, s, 2)
+	foreign, _ := s.CreateNomination(otherRoom, "Foreign", "")
+	f1, _ := s.CreateNominee(foreign, "A")
+
+	if err := s.RecordVote("u1", plural, p1, now); err != nil {
+		t.Fatalf("RecordVote: %v", err)
+	}
+	if err := s.RecordScore("u1", scored, s1, 4, now); err != nil {
+		t.Fatalf("RecordScore: %v", err)
+	}
+	if err := s.RecordReferendumVote("u1", ref, domain.ChoiceAbstain, now); err != nil {
+		t.Fatalf("RecordReferendumVote: %v", err)
+	}
+	_ = s.RecordVote("u1", foreign, f1, now)
+	_ = s.RecordVote("u2", untouched, u1, now) // чужой голос не считается
+
+	got, err := s.VotedNominations("u1", roomID)
+	want := map[int64]bool{plural: true, scored: true, ref: true}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("VotedNominations: got=%v want=%v err=%v", got, want, err)
+	}
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+// ---------- Room voters / jury ----------
+
+// voterGroupExpr — группа голосующего для INSERT в votes; параметры: nomination_id, user_hash.
+const voterGroupExpr = `COALESCE((
+    SELECT rv.voter_group
+    FROM room_voters rv
+    JOIN nominations nom ON nom.room_id = rv.room_id
+    WHERE nom.id = ? AND rv.user_hash = ?
+), 'audience')`
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// AddRoomVoter отмечает пользователя участником комнаты. Жюри не понижается до зрителя
+// повторным входом по паролю или обычной ссылке.
+func (s *Store) AddRoomVoter(roomID int64, userHash string, group domain.VoterGroup, now time.Time) error {
+	return addRoomVoter(s.db, roomID, userHash, group, now)
+}
+
+func addRoomVoter(db execer, roomID int64, userHash string, group domain.VoterGroup, now time.Time) error {
+	_, err := db.Exec(`
+INSERT INTO room_voters(room_id, user_hash, voter_group, joined_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(room_id, user_hash) DO UPDATE SET
+    voter_group = CASE WHEN excluded.voter_group = 'jury' THEN 'jury' ELSE room_voters.voter_group END
+`, roomID, userHash, group, now.UTC())
+	if err != nil {
+		return err
+	}
+	if group != domain.GroupJury {
+		return nil
+	}
+
+	// голоса, отданные до вступления в жюри, тоже переходят в группу жюри
+	_, err = db.Exec(`
+UPDATE votes SET voter_group = 'jury'
+WHERE user_hash = ?
+  AND nomination_id IN (SELECT id FROM nominations WHERE room_id = ?)
+`, userHash, roomID)
+	return err
+}
+
+// AddRoomMember запоминает Telegram ID вошедшего в комнату — чтобы потом ему можно было написать.
+func (s *Store) AddRoomMember(roomID, userID int64, now time.Time) error {
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO room_members(room_id, user_id, joined_at) VALUES (?, ?, ?)`,
+		roomID, userID, now.UTC())
+	return err
+}
+
+// RoomMemberIDs — Telegram ID всех, кто входил в комнату.
+func (s *Store) RoomMemberIDs(roomID int64) ([]int64, error) {
+	return s.listIDs(`SELECT user_id FROM room_members WHERE room_id = ? ORDER BY user_id`, roomID)
+}
+
+// SetJuryWeight задаёт вес жюри в процентах (0..100), остальное — зрители.
+func (s *Store) SetJuryWeight(roomID int64, percent int) error {
+	res, err := s.db.Exec(`UPDATE rooms SET jury_weight = ? WHERE id = ?`, percent, roomID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RoomVoterCount — сколько участников в комнате (вошли по паролю или ссылке); база для кворума.
+func (s *Store) RoomVoterCount(roomID int64) (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM room_voters WHERE room_id = ?`, roomID).Scan(&n)
+	return n, err
+}
+
+// ResultsByGroup — число голосов у номинантов отдельно по группам голосующих.
+func (s *Store) ResultsByGroup(nominationID int64) (map[domain.VoterGroup]map[int64]int, error) {
+	rows, err := s.db.Query(`
+SELECT voter_group, nominee_id, COUNT(*)
+FROM votes
+WHERE nomination_id = ?
+GROUP BY voter_group, nominee_id
+`, nominationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[domain.VoterGroup]map[int64]int)
+	for rows.Next() {
+		var group string
+		var nomineeID int64
+		var n int
+		if err := rows.Scan(&group, &nomineeID, &n); err != nil {
+			return nil, err
+		}
+		g := domain.VoterGroup(group)
+		if counts[g] == nil {
+			counts[g] = make(map[int64]int)
+		}
+		counts[g][nomineeID] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
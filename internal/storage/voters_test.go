@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_JuryInvite_GroupsVotes(t *testing.T) {
+	s, db := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	now := time.Now()
+
+	if err := s.CreateInvite(domain.Invite{Token: "jury", RoomID: roomID, CreatedBy: 1, CreatedAt: now, Group: domain.GroupJury}); err != nil {
+		t.Fatalf("CreateInvite(jury): %v", err)
+	}
+	inv, err := s.GetInvite("jury")
+	if err != nil || inv.Group != domain.GroupJury {
+		t.Fatalf("GetInvite: %+v err=%v", inv, err)
+	}
+
+	// j проголосовал зрителем, потом вошёл в жюри — прежний голос тоже переходит в жюри
+	if err := s.AddRoomVoter(roomID, "j", domain.GroupAudience, now); err != nil {
+		t.Fatalf("AddRoomVoter: %v", err)
+	}
+	if err := s.RecordVote("j", nomID, a, now); err != nil {
+		t.Fatalf("RecordVote(j): %v", err)
+	}
+	if _, _, group, err := s.RedeemInvite("jury", 2, "j", "", now); err != nil || group != domain.GroupJury {
+		t.Fatalf("RedeemInvite(jury): group=%q err=%v", group, err)
+	}
+	if got := mustCount(t, db, `SELECT COUNT(*) FROM votes WHERE user_hash = 'j' AND voter_group = 'jury'`); got != 1 {
+		t.Fatalf("expected j's earlier vote moved to jury, got %d", got)
+	}
+
+	// повторный вход по паролю не понижает жюри до зрителя
+	if err := s.AddRoomVoter(roomID, "j", domain.GroupAudience, now); err != nil {
+		t.Fatalf("AddRoomVoter(again): %v", err)
+	}
+	if err := s.RecordVote("j", nomID, b, now); err != nil {
+		t.Fatalf("RecordVote(j, b): %v", err)
+	}
+
+	// незнакомый голосующий (без записи в room_voters) — зритель
+	for _, u := range []string{"u1", "u2"} {
+		if err := s.RecordVote(u, nomID, a, now); err != nil {
+			t.Fatalf("RecordVote(%s): %v", u, err)
+		}
+	}
+
+	byGroup, err := s.ResultsByGroup(nomID)
+	if err != nil {
+		t.Fatalf("ResultsByGroup: %v", err)
+	}
+	if len(byGroup[domain.GroupJury]) != 1 || byGroup[domain.GroupJury][b] != 1 {
+		t.Fatalf("unexpected jury tally: %v", byGroup[domain.GroupJury])
+	}
+	if byGroup[domain.GroupAudience][a] != 2 || byGroup[domain.GroupAudience][b] != 0 {
+		t.Fatalf("unexpected audience tally: %v", byGroup[domain.GroupAudience])
+	}
+}
+
+func TestStore_SetJuryWeight(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, _ := s.CreateRoom(1, "room", "pw")
+	room, err := s.GetRoom(roomID)
+	if err != nil || room.JuryWeight != 50 {
+		t.Fatalf("default jury weight: %+v err=%v", room, err)
+	}
+
+	if err := s.SetJuryWeight(roomID, 70); err != nil {
+		t.Fatalf("SetJuryWeight: %v", err)
+	}
+	if room, _ := s.GetRoom(roomID); room.JuryWeight != 70 {
+		t.Fatalf("expected 70, got %d", room.JuryWeight)
+	}
+	if err := s.SetJuryWeight(roomID, 101); err == nil {
+		t.Fatalf("expected CHECK violation for 101")
+	}
+	if err := s.SetJuryWeight(roomID+100, 10); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_RoomMembers(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	roomID, _ := s.CreateRoom(1, "room", "pw")
+	other, _ := s.CreateRoom(1, "other", "pw")
+	now := time.Now()
+	for _, m := range []struct{ room, user int64 }{{roomID, 7}, {roomID, 3}, {roomID, 7}, {other, 5}} {
+		if err := s.AddRoomMember(m.room, m.user, now); err != nil {
+			t.Fatalf("AddRoomMember: %v", err)
+		}
+	}
+
+	ids, err := s.RoomMemberIDs(roomID)
+	if err != nil || !reflect.DeepEqual(ids, []int64{3, 7}) {
+		t.Fatalf("RoomMemberIDs: %v err=%v", ids, err)
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+var (
+	// ErrNoVote — голоса за этого номинанта у пользователя нет (устаревшая кнопка).
+	ErrNoVote = errors.New("no vote to withdraw")
+	// ErrWithdrawLocked — голосование закрыто, а отзыв после закрытия в комнате не включён
+	// (или итоги уже опубликованы).
+	ErrWithdrawLocked = errors.New("vote withdrawal is disabled after voting closed")
+)
+
+// ---------- Withdraw ----------
+
+// WithdrawVote удаляет голос пользователя за номинанта. Пока голосование открыто, отозвать можно
+// всегда; после закрытия — только если в комнате включён withdraw_after_close, иначе ErrWithdrawLocked.
+// После публикации итоги не меняются — ErrWithdrawLocked при любой настройке.
+// В комнате без переголосования (allow_revote) голос окончательный — ErrVoteLocked.
+// В черновике — ErrVotingClosed, у номинации уже есть следующий тур — ErrNextRound,
+// голоса за этого номинанта нет — ErrNoVote.
+func (s *Store) WithdrawVote(userHash string, nominationID, nomineeID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status domain.RoomStatus
+	var afterClose, allowRevote, nextRound bool
+	err = tx.QueryRow(`
+SELECT r.status, r.withdraw_after_close, r.allow_revote,
+       EXISTS (SELECT 1 FROM nominations c WHERE c.parent_nomination_id = nom.id)
+FROM nominations nom
+JOIN rooms r ON r.id = nom.room_id
+WHERE nom.id = ?
+`, nominationID).Scan(&status, &afterClose, &allowRevote, &nextRound)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return err
+	}
+	switch status {
+	case domain.RoomOpen:
+	case domain.RoomClosed:
+		if !afterClose {
+			return ErrWithdrawLocked
+		}
+	case domain.RoomPublished:
+		return ErrWithdrawLocked
+	default:
+		return ErrVotingClosed
+	}
+	if nextRound {
+		return ErrNextRound
+	}
+	if !allowRevote {
+		return ErrVoteLocked
+	}
+
+	res, err := tx.Exec(`DELETE FROM votes WHERE user_hash = ? AND nomination_id = ? AND nominee_id = ?`,
+		userHash, nominationID, nomineeID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoVote
+	}
+	return tx.Commit()
+}
+
+// SetWithdrawAfterClose разрешает или запрещает отзывать голоса после закрытия голосования.
+func (s *Store) SetWithdrawAfterClose(roomID int64, on bool) error {
+	res, err := s.db.Exec(`UPDATE rooms SET withdraw_after_close = ? WHERE id = ?`, on, roomID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maaaruch/tg-vote-bot/internal/domain"
+)
+
+func TestStore_WithdrawVote(t *testing.T) {
+	s, db := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	b, _ := s.CreateNominee(nomID, "B")
+	now := time.Now()
+
+	if err := s.RecordVote("u1", nomID, a, now); err != nil {
+		t.Fatalf("RecordVote: %v", err)
+	}
+	if err := s.WithdrawVote("u1", nomID, b); err != ErrNoVote {
+		t.Fatalf("stale button: expected ErrNoVote, got %v", err)
+	}
+	if err := s.WithdrawVote("u1", nomID, a); err != nil {
+		t.Fatalf("WithdrawVote: %v", err)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM votes`); n != 0 {
+		t.Fatalf("vote must be deleted, %d left", n)
+	}
+	if err := s.WithdrawVote("u1", nomID, a); err != ErrNoVote {
+		t.Fatalf("second withdrawal: expected ErrNoVote, got %v", err)
+	}
+
+	// по умолчанию после закрытия голоса окончательные
+	_ = s.RecordVote("u1", nomID, a, now)
+	_ = s.RecordVote("u2", nomID, b, now)
+	if room, _ := s.GetRoom(roomID); room.WithdrawAfterClose {
+		t.Fatalf("withdrawal after close must be off by default")
+	}
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomClosed); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := s.WithdrawVote("u1", nomID, a); err != ErrWithdrawLocked {
+		t.Fatalf("expected ErrWithdrawLocked, got %v", err)
+	}
+
+	if err := s.SetWithdrawAfterClose(roomID, true); err != nil {
+		t.Fatalf("SetWithdrawAfterClose: %v", err)
+	}
+	if room, _ := s.GetRoom(roomID); !room.WithdrawAfterClose {
+		t.Fatalf("setting must be saved")
+	}
+	if err := s.WithdrawVote("u1", nomID, a); err != nil {
+		t.Fatalf("WithdrawVote after close: %v", err)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM votes`); n != 1 {
+		t.Fatalf("only the withdrawn vote must go, got %d votes", n)
+	}
+
+	// пока голосование открыто, настройка не мешает
+	if _, err := s.TransitionRoomStatus(roomID, domain.RoomOpen); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if err := s.WithdrawVote("u2", nomID, b); err != nil {
+		t.Fatalf("WithdrawVote in open room: %v", err)
+	}
+
+	if err := s.SetWithdrawAfterClose(9999, true); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_WithdrawVote_PublishedIsFinal(t *testing.T) {
+	s, db := newTestStore(t)
+
+	roomID := newOpenRoom(t, s, 1)
+	nomID, _ := s.CreateNomination(roomID, "Nom", "")
+	a, _ := s.CreateNominee(nomID, "A")
+	if err := s.RecordVote("u1", nomID, a, time.Now()); err != nil {
+		t.Fatalf("RecordVote: %v", err)
+	}
+	if err := s.SetWithdrawAfterClose(roomID, true); err != nil {
+		t.Fatalf("SetWithdrawAfterClose: %v", err)
+	}
+	for _, st := range []domain.RoomStatus{domain.RoomClosed, domain.RoomPublished} {
+		if _, err := s.TransitionRoomStatus(roomID, st); err != nil {
+			t.Fatalf("transition to %s: %v", st, err)
+		}
+	}
+
+	if err := s.WithdrawVote("u1", nomID, a); err != ErrWithdrawLocked {
+		t.Fatalf("expected ErrWithdrawLocked, got %v", err)
+	}
+	if n := mustCount(t, db, `SELECT COUNT(*) FROM votes`); n != 1 {
+		t.Fatalf("published vote must stay, got %d votes", n)
+	}
+}
@@ -0,0 +1,41 @@
+package tally
+
+import "sort"
+
+// BordaRow — очки номинанта и сколько раз он оказался на каждом месте.
+type BordaRow struct {
+	ID        int64
+	Points    int
+	Positions []int // Positions[i] — сколько бюллетеней поставили номинанта на место i+1
+}
+
+// Borda считает очки: при n кандидатах первое место даёт n-1 очков, последнее — 0.
+// Неизвестные номинанты в бюллетене пропускаются, не попавшие в бюллетень получают 0.
+// Строки отсортированы по очкам, при равенстве — в порядке candidates.
+func Borda(candidates []int64, ballots []Ballot) []BordaRow {
+	n := len(candidates)
+	index := make(map[int64]int, n)
+	rows := make([]BordaRow, n)
+	for i, c := range candidates {
+		index[c] = i
+		rows[i] = BordaRow{ID: c, Positions: make([]int, n)}
+	}
+
+	for _, b := range ballots {
+		pos := 0
+		seen := make(map[int64]bool, len(b))
+		for _, c := range b {
+			i, ok := index[c]
+			if !ok || seen[c] {
+				continue
+			}
+			seen[c] = true
+			rows[i].Points += n - 1 - pos
+			rows[i].Positions[pos]++
+			pos++
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Points > rows[j].Points })
+	return rows
+}
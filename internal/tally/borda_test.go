@@ -0,0 +1,95 @@
+package tally
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBorda(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		candidates []int64
+		ballots    []Ballot
+		wantOrder  []int64
+		wantPoints []int
+		wantPos    map[int64][]int
+	}{
+		{
+			name:       "no_ballots",
+			candidates: []int64{1, 2},
+			wantOrder:  []int64{1, 2},
+			wantPoints: []int{0, 0},
+			wantPos:    map[int64][]int{1: {0, 0}, 2: {0, 0}},
+		},
+		{
+			name:       "single_ballot",
+			candidates: []int64{1, 2, 3},
+			ballots:    []Ballot{{3, 1, 2}},
+			wantOrder:  []int64{3, 1, 2},
+			wantPoints: []int{2, 1, 0},
+			wantPos:    map[int64][]int{3: {1, 0, 0}, 1: {0, 1, 0}, 2: {0, 0, 1}},
+		},
+		{
+			// у 1 больше первых мест, но 2 стабильно второй и выигрывает по очкам
+			name:       "consensus_beats_plurality",
+			candidates: []int64{1, 2, 3},
+			ballots: []Ballot{
+				{1, 2, 3}, {1, 2, 3},
+				{3, 2, 1}, {3, 2, 1},
+				{2, 1, 3},
+			},
+			wantOrder:  []int64{2, 1, 3},
+			wantPoints: []int{6, 5, 4},
+			wantPos:    map[int64][]int{1: {2, 1, 2}, 2: {1, 4, 0}, 3: {2, 0, 3}},
+		},
+		{
+			name:       "tie_keeps_candidate_order",
+			candidates: []int64{1, 2},
+			ballots:    []Ballot{{2, 1}, {1, 2}},
+			wantOrder:  []int64{1, 2},
+			wantPoints: []int{1, 1},
+			wantPos:    map[int64][]int{1: {1, 1}, 2: {1, 1}},
+		},
+		{
+			// удалённый номинант и повтор в бюллетене не сдвигают места
+			name:       "unknown_and_duplicate_skipped",
+			candidates: []int64{1, 2, 3},
+			ballots:    []Ballot{{99, 2, 2, 1, 3}},
+			wantOrder:  []int64{2, 1, 3},
+			wantPoints: []int{2, 1, 0},
+			wantPos:    map[int64][]int{2: {1, 0, 0}, 1: {0, 1, 0}, 3: {0, 0, 1}},
+		},
+		{
+			// номинант, добавленный после голосования, в старых бюллетенях не стоит — 0 очков
+			name:       "partial_ballot",
+			candidates: []int64{1, 2, 3},
+			ballots:    []Ballot{{1, 2}},
+			wantOrder:  []int64{1, 2, 3},
+			wantPoints: []int{2, 1, 0},
+			wantPos:    map[int64][]int{1: {1, 0, 0}, 2: {0, 1, 0}, 3: {0, 0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows := Borda(tt.candidates, tt.ballots)
+			var order []int64
+			var points []int
+			for _, r := range rows {
+				order = append(order, r.ID)
+				points = append(points, r.Points)
+				if !reflect.DeepEqual(r.Positions, tt.wantPos[r.ID]) {
+					t.Fatalf("positions of %d: got=%v want=%v", r.ID, r.Positions, tt.wantPos[r.ID])
+				}
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) || !reflect.DeepEqual(points, tt.wantPoints) {
+				t.Fatalf("got order=%v points=%v, want order=%v points=%v", order, points, tt.wantOrder, tt.wantPoints)
+			}
+		})
+	}
+}
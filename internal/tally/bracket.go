@@ -0,0 +1,46 @@
+package tally
+
+// BracketSize — число мест в сетке на выбывание: ближайшая степень двойки, не меньше n (минимум 2).
+func BracketSize(n int) int {
+	size := 2
+	for size < n {
+		size *= 2
+	}
+	return size
+}
+
+// SeedOrder — классический посев для сетки из size мест: сеянные 1 и 2 попадают в разные половины
+// и могут встретиться только в финале. Для 8: 1 8 4 5 2 7 3 6 — пары (1,8), (4,5), (2,7), (3,6).
+func SeedOrder(size int) []int {
+	seeds := []int{1}
+	for len(seeds) < size {
+		m := 2*len(seeds) + 1
+		next := make([]int, 0, 2*len(seeds))
+		for _, s := range seeds {
+			next = append(next, s, m-s)
+		}
+		seeds = next
+	}
+	return seeds
+}
+
+// BracketPairs раскладывает номинантов (в порядке посева, первый — сильнейший) по парам первого круга.
+// Недостающие места — 0: соперник с пустым местом проходит дальше без матча.
+func BracketPairs(seeded []int64) [][2]int64 {
+	if len(seeded) < 2 {
+		return nil
+	}
+	order := SeedOrder(BracketSize(len(seeded)))
+	pairs := make([][2]int64, 0, len(order)/2)
+	for i := 0; i < len(order); i += 2 {
+		pairs = append(pairs, [2]int64{seedAt(seeded, order[i]), seedAt(seeded, order[i+1])})
+	}
+	return pairs
+}
+
+func seedAt(seeded []int64, seed int) int64 {
+	if seed > len(seeded) {
+		return 0
+	}
+	return seeded[seed-1]
+}
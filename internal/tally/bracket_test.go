@@ -0,0 +1,51 @@
+package tally
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeedOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{2, []int{1, 2}},
+		{4, []int{1, 4, 2, 3}},
+		{8, []int{1, 8, 4, 5, 2, 7, 3, 6}},
+	}
+
+	for _, tt := range tests {
+		if got := SeedOrder(tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("SeedOrder(%d) = %v want %v", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestBracketPairs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		seeded []int64
+		want   [][2]int64
+	}{
+		{"too_few", []int64{10}, nil},
+		{"two", []int64{10, 20}, [][2]int64{{10, 20}}},
+		{"three_with_bye", []int64{10, 20, 30}, [][2]int64{{10, 0}, {20, 30}}},
+		{"five", []int64{1, 2, 3, 4, 5}, [][2]int64{{1, 0}, {4, 5}, {2, 0}, {3, 0}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := BracketPairs(tt.seeded); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Package tally — подсчёт итогов для разных способов голосования.
+// Чистые функции без доступа к БД: storage отдаёт бюллетени, app форматирует результат.
+package tally
+
+// Ballot — номинанты в порядке предпочтения, первый — самый желанный.
+type Ballot []int64
+
+// IRVRound — один раунд instant-runoff.
+type IRVRound struct {
+	Counts     map[int64]int // голоса у кандидатов, оставшихся в раунде
+	Exhausted  int           // бюллетени, в которых не осталось ни одного живого кандидата
+	Eliminated []int64       // выбывшие по итогам раунда; пусто в последнем раунде
+}
+
+// IRVResult — все раунды и победитель. Winners пуст, если голосов не было,
+// и содержит нескольких кандидатов при неразрешимой ничьей.
+type IRVResult struct {
+	Rounds  []IRVRound
+	Winners []int64
+}
+
+// IRV считает instant-runoff: в каждом раунде голос бюллетеня уходит его старшему живому
+// кандидату; побеждает набравший больше половины неисчерпанных бюллетеней, иначе выбывают все
+// кандидаты с наименьшим числом голосов. Если поровну у всех оставшихся — это ничья между ними.
+// Порядок candidates задаёт порядок в Eliminated и Winners.
+func IRV(candidates []int64, ballots []Ballot) IRVResult {
+	active := make(map[int64]bool, len(candidates))
+	for _, c := range candidates {
+		active[c] = true
+	}
+
+	var res IRVResult
+	for len(active) > 0 {
+		round := IRVRound{Counts: make(map[int64]int, len(active))}
+		for c := range active {
+			round.Counts[c] = 0
+		}
+
+		total := 0
+		for _, b := range ballots {
+			top, ok := firstActive(b, active)
+			if !ok {
+				round.Exhausted++
+				continue
+			}
+			round.Counts[top]++
+			total++
+		}
+
+		if total == 0 {
+			res.Rounds = append(res.Rounds, round)
+			return res
+		}
+
+		minVotes := total
+		for _, c := range candidates {
+			if !active[c] {
+				continue
+			}
+			if round.Counts[c]*2 > total {
+				res.Rounds = append(res.Rounds, round)
+				res.Winners = []int64{c}
+				return res
+			}
+			if round.Counts[c] < minVotes {
+				minVotes = round.Counts[c]
+			}
+		}
+
+		var losers []int64
+		for _, c := range candidates {
+			if active[c] && round.Counts[c] == minVotes {
+				losers = append(losers, c)
+			}
+		}
+		if len(losers) == len(active) {
+			res.Rounds = append(res.Rounds, round)
+			res.Winners = losers
+			return res
+		}
+
+		for _, c := range losers {
+			delete(active, c)
+		}
+		round.Eliminated = losers
+		res.Rounds = append(res.Rounds, round)
+	}
+	return res
+}
+
+func firstActive(b Ballot, active map[int64]bool) (int64, bool) {
+	for _, c := range b {
+		if active[c] {
+			return c, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,141 @@
+package tally
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIRV(t *testing.T) {
+	t.Parallel()
+
+	const (
+		a int64 = 1
+		b int64 = 2
+		c int64 = 3
+		d int64 = 4
+	)
+
+	tests := []struct {
+		name           string
+		candidates     []int64
+		ballots        []Ballot
+		wantWinners    []int64
+		wantRounds     int
+		wantEliminated [][]int64
+		wantExhausted  []int
+	}{
+		{
+			name:           "no_ballots",
+			candidates:     []int64{a, b},
+			wantWinners:    nil,
+			wantRounds:     1,
+			wantEliminated: [][]int64{nil},
+			wantExhausted:  []int{0},
+		},
+		{
+			name:           "first_round_majority",
+			candidates:     []int64{a, b, c},
+			ballots:        []Ballot{{a}, {a, b}, {b}},
+			wantWinners:    []int64{a},
+			wantRounds:     1,
+			wantEliminated: [][]int64{nil},
+			wantExhausted:  []int{0},
+		},
+		{
+			// C выбывает, его голос уходит B, и B обгоняет A
+			name:       "transfer_changes_winner",
+			candidates: []int64{a, b, c},
+			ballots: []Ballot{
+				{a}, {a}, {a},
+				{b, a}, {b}, {b},
+				{c, b}, {c, b},
+			},
+			wantWinners:    []int64{b},
+			wantRounds:     2,
+			wantEliminated: [][]int64{{c}, nil},
+			wantExhausted:  []int{0, 0},
+		},
+		{
+			// после выбывания C его бюллетень без других предпочтений исчерпан
+			name:           "exhausted_ballot",
+			candidates:     []int64{a, b, c},
+			ballots:        []Ballot{{a}, {a}, {b}, {b}, {c}},
+			wantWinners:    []int64{a, b},
+			wantRounds:     2,
+			wantEliminated: [][]int64{{c}, nil},
+			wantExhausted:  []int{0, 1},
+		},
+		{
+			// C и D делят последнее место и выбывают вместе
+			name:       "tie_for_last_eliminated_together",
+			candidates: []int64{a, b, c, d},
+			ballots: []Ballot{
+				{a}, {a}, {a},
+				{b}, {b},
+				{c, b}, {d, b},
+			},
+			wantWinners:    []int64{b},
+			wantRounds:     2,
+			wantEliminated: [][]int64{{c, d}, nil},
+			wantExhausted:  []int{0, 0},
+		},
+		{
+			name:           "candidate_without_votes",
+			candidates:     []int64{a, b, c},
+			ballots:        []Ballot{{a}, {b}, {a}},
+			wantWinners:    []int64{a},
+			wantRounds:     1,
+			wantEliminated: [][]int64{nil},
+			wantExhausted:  []int{0},
+		},
+		{
+			// бюллетень с удалённым номинантом просто пропускает его
+			name:           "unknown_candidate_skipped",
+			candidates:     []int64{a, b},
+			ballots:        []Ballot{{99, b}, {a}, {b}},
+			wantWinners:    []int64{b},
+			wantRounds:     1,
+			wantEliminated: [][]int64{nil},
+			wantExhausted:  []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res := IRV(tt.candidates, tt.ballots)
+			if !reflect.DeepEqual(res.Winners, tt.wantWinners) {
+				t.Fatalf("winners: got=%v want=%v", res.Winners, tt.wantWinners)
+			}
+			if len(res.Rounds) != tt.wantRounds {
+				t.Fatalf("rounds: got=%d want=%d (%+v)", len(res.Rounds), tt.wantRounds, res.Rounds)
+			}
+			for i, r := range res.Rounds {
+				if !reflect.DeepEqual(r.Eliminated, tt.wantEliminated[i]) {
+					t.Fatalf("round %d eliminated: got=%v want=%v", i+1, r.Eliminated, tt.wantEliminated[i])
+				}
+				if r.Exhausted != tt.wantExhausted[i] {
+					t.Fatalf("round %d exhausted: got=%d want=%d", i+1, r.Exhausted, tt.wantExhausted[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIRV_RoundCounts(t *testing.T) {
+	t.Parallel()
+
+	res := IRV([]int64{1, 2, 3}, []Ballot{{1}, {1}, {1}, {2, 1}, {2}, {2}, {3, 2}, {3, 2}})
+
+	want := []map[int64]int{
+		{1: 3, 2: 3, 3: 2},
+		{1: 3, 2: 5},
+	}
+	for i, r := range res.Rounds {
+		if !reflect.DeepEqual(r.Counts, want[i]) {
+			t.Fatalf("round %d counts: got=%v want=%v", i+1, r.Counts, want[i])
+		}
+	}
+}
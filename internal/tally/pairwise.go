@@ -0,0 +1,98 @@
+package tally
+
+import (
+	"math"
+	"sort"
+)
+
+// Comparison — одно попарное сравнение: Winner предпочли Loser.
+type Comparison struct {
+	Winner int64
+	Loser  int64
+}
+
+// PairwiseRow — номинант в таблице попарных сравнений.
+type PairwiseRow struct {
+	ID     int64
+	Rating float64 // рейтинг Брэдли–Терри в шкале Эло: 1500 — средний, +400 — в 10 раз сильнее
+	Wins   int
+	Losses int
+}
+
+const (
+	baseRating   = 1500
+	ratingScale  = 400
+	btIterations = 1000
+	btTolerance  = 1e-9
+)
+
+// BradleyTerry оценивает силу кандидатов по всем сравнениям сразу (порядок сравнений не важен,
+// в отличие от пошагового Эло) и переводит её в шкалу Эло. Чтобы у кандидата без побед или
+// без поражений сила не уходила в 0 или бесконечность, каждому добавлены одна победа и одно
+// поражение против воображаемого соперника средней силы; кандидат без сравнений получает 1500.
+// Сравнения с кандидатами не из candidates пропускаются. Результат отсортирован по рейтингу,
+// при равенстве — в порядке candidates.
+func BradleyTerry(candidates []int64, comparisons []Comparison) []PairwiseRow {
+	index := make(map[int64]int, len(candidates))
+	for i, c := range candidates {
+		index[c] = i
+	}
+
+	n := len(candidates)
+	wins := make([]int, n)
+	losses := make([]int, n)
+	games := make([]map[int]int, n) // games[i][j] — сколько раз i и j сравнивали
+	for i := range games {
+		games[i] = make(map[int]int)
+	}
+	for _, c := range comparisons {
+		w, okW := index[c.Winner]
+		l, okL := index[c.Loser]
+		if !okW || !okL || w == l {
+			continue
+		}
+		wins[w]++
+		losses[l]++
+		games[w][l]++
+		games[l][w]++
+	}
+
+	// MM-алгоритм (Hunter, 2004): p_i = W_i / Σ_j n_ij / (p_i + p_j), с воображаемым соперником силы 1
+	strength := make([]float64, n)
+	for i := range strength {
+		strength[i] = 1
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < btIterations; iter++ {
+		for i := range strength {
+			denom := 2 / (strength[i] + 1)
+			for j, g := range games[i] {
+				denom += float64(g) / (strength[i] + strength[j])
+			}
+			next[i] = float64(wins[i]+1) / denom
+		}
+
+		diff := 0.0
+		for i := range strength {
+			diff = math.Max(diff, math.Abs(math.Log(next[i])-math.Log(strength[i])))
+			strength[i] = next[i]
+		}
+		if diff < btTolerance {
+			break
+		}
+	}
+
+	rows := make([]PairwiseRow, 0, n)
+	for i, c := range candidates {
+		rows = append(rows, PairwiseRow{
+			ID:     c,
+			Rating: baseRating + ratingScale*math.Log10(strength[i]),
+			Wins:   wins[i],
+			Losses: losses[i],
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Rating > rows[j].Rating
+	})
+	return rows
+}
@@ -0,0 +1,63 @@
+package tally
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestBradleyTerry_Order(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		candidates  []int64
+		comparisons []Comparison
+		want        []int64
+	}{
+		{"no_comparisons_keeps_order", []int64{1, 2, 3}, nil, []int64{1, 2, 3}},
+		{"single_win", []int64{1, 2}, []Comparison{{2, 1}}, []int64{2, 1}},
+		{"chain", []int64{1, 2, 3}, []Comparison{{3, 2}, {2, 1}, {3, 1}}, []int64{3, 2, 1}},
+		{"split_is_a_tie", []int64{1, 2}, []Comparison{{1, 2}, {2, 1}}, []int64{1, 2}},
+		// у 1 и 2 по одной победе, но 2 обыграл сильного 3, а 1 — слабого 4
+		{"opponent_strength_matters", []int64{1, 2, 3, 4}, []Comparison{{1, 4}, {2, 3}, {3, 4}, {3, 4}}, []int64{2, 1, 3, 4}},
+		{"unknown_ignored", []int64{1, 2}, []Comparison{{9, 1}, {1, 1}, {2, 1}}, []int64{2, 1}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows := BradleyTerry(tt.candidates, tt.comparisons)
+			got := make([]int64, 0, len(rows))
+			for _, r := range rows {
+				got = append(got, r.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v want %v (%+v)", got, tt.want, rows)
+			}
+		})
+	}
+}
+
+func TestBradleyTerry_Ratings(t *testing.T) {
+	t.Parallel()
+
+	rows := BradleyTerry([]int64{1, 2, 3}, []Comparison{{1, 2}, {1, 2}, {1, 2}, {2, 1}})
+	byID := make(map[int64]PairwiseRow, len(rows))
+	for _, r := range rows {
+		byID[r.ID] = r
+	}
+
+	if byID[1].Wins != 3 || byID[1].Losses != 1 || byID[2].Wins != 1 || byID[2].Losses != 3 {
+		t.Fatalf("unexpected tallies: %+v", rows)
+	}
+	if byID[3].Rating != 1500 {
+		t.Fatalf("no comparisons must give 1500, got %v", byID[3].Rating)
+	}
+	// симметричная картина: отклонения от среднего равны по модулю
+	if d := (byID[1].Rating - 1500) + (byID[2].Rating - 1500); math.Abs(d) > 1e-3 || byID[1].Rating <= 1500 {
+		t.Fatalf("ratings must be symmetric around 1500: %+v", rows)
+	}
+}
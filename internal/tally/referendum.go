@@ -0,0 +1,38 @@
+package tally
+
+// ReferendumResult — итог референдума.
+type ReferendumResult struct {
+	Yes, No, Abstain int
+	Turnout          int // проголосовали, включая воздержавшихся
+	Required         int // сколько «за» нужно при таком числе «за» и «против»
+	Margin           int // Yes − Required: ≥ 0 — порог пройден, < 0 — столько «за» не хватило
+	QuorumNeeded     int // сколько участников должно проголосовать; 0 — кворум не нужен
+	QuorumMet        bool
+	Passed           bool
+}
+
+// Referendum подводит итог: решение принято, если набран кворум и «за» не меньше Required.
+// Воздержавшиеся учитываются в кворуме, но не в пороге. Простое большинство — «за» строго
+// больше «против», две трети — «за» не меньше 2/3 от «за» и «против»; без голосов «за» и
+// «против» решение не принято. Кворум — quorumPct процентов от members, с округлением вверх;
+// если проголосовало больше, чем известно участников, база кворума — число проголосовавших.
+func Referendum(yes, no, abstain int, twoThirds bool, members, quorumPct int) ReferendumResult {
+	r := ReferendumResult{Yes: yes, No: no, Abstain: abstain, Turnout: yes + no + abstain}
+
+	decisive := yes + no
+	if twoThirds {
+		r.Required = (2*decisive + 2) / 3
+	} else {
+		r.Required = decisive/2 + 1
+	}
+	r.Required = max(r.Required, 1)
+	r.Margin = yes - r.Required
+
+	if quorumPct > 0 {
+		base := max(members, r.Turnout)
+		r.QuorumNeeded = (base*quorumPct + 99) / 100
+	}
+	r.QuorumMet = r.Turnout >= r.QuorumNeeded
+	r.Passed = r.QuorumMet && r.Margin >= 0
+	return r
+}
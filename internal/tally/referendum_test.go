@@ -0,0 +1,44 @@
+package tally
+
+import "testing"
+
+func TestReferendum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                 string
+		yes, no, abstain     int
+		twoThirds            bool
+		members, quorumPct   int
+		wantRequired, margin int
+		wantQuorum           int
+		wantPassed           bool
+	}{
+		{"majority_passes", 5, 3, 1, false, 0, 0, 5, 0, 0, true},
+		{"majority_tie_fails", 2, 2, 0, false, 0, 0, 3, -1, 0, false},
+		{"abstain_not_in_threshold", 1, 0, 10, false, 0, 0, 1, 0, 0, true},
+		{"no_votes_fails", 0, 0, 3, false, 0, 0, 1, -1, 0, false},
+		{"two_thirds_exact", 2, 1, 0, true, 0, 0, 2, 0, 0, true},
+		{"two_thirds_short", 3, 2, 0, true, 0, 0, 4, -1, 0, false},
+		{"two_thirds_rounds_up", 3, 1, 0, true, 0, 0, 3, 0, 0, true},
+		{"quorum_met_with_abstain", 3, 0, 2, false, 10, 50, 2, 1, 5, true},
+		{"quorum_missed", 4, 0, 0, false, 10, 50, 3, 1, 5, false},
+		{"quorum_rounds_up", 2, 0, 0, false, 3, 50, 2, 0, 2, true},
+		{"turnout_above_members", 6, 0, 0, false, 2, 100, 4, 2, 6, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := Referendum(tt.yes, tt.no, tt.abstain, tt.twoThirds, tt.members, tt.quorumPct)
+			if r.Required != tt.wantRequired || r.Margin != tt.margin || r.QuorumNeeded != tt.wantQuorum || r.Passed != tt.wantPassed {
+				t.Fatalf("got %+v", r)
+			}
+			if r.Turnout != tt.yes+tt.no+tt.abstain {
+				t.Fatalf("turnout: got %d", r.Turnout)
+			}
+		})
+	}
+}
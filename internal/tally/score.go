@@ -0,0 +1,62 @@
+package tally
+
+import (
+	"math"
+	"sort"
+)
+
+// ScoreStats — сводка оценок одного номинанта.
+type ScoreStats struct {
+	Count  int
+	Mean   float64
+	StdDev float64 // стандартное отклонение по всем оценкам (генеральное, делим на n)
+}
+
+// Scores считает количество, среднее и стандартное отклонение; для пустого списка — нули.
+func Scores(values []int) ScoreStats {
+	if len(values) == 0 {
+		return ScoreStats{}
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += float64(v)
+	}
+	mean := sum / float64(len(values))
+
+	sq := 0.0
+	for _, v := range values {
+		d := float64(v) - mean
+		sq += d * d
+	}
+	return ScoreStats{
+		Count:  len(values),
+		Mean:   mean,
+		StdDev: math.Sqrt(sq / float64(len(values))),
+	}
+}
+
+// ScoreRow — номинант и его сводка в общей таблице.
+type ScoreRow struct {
+	ID int64
+	ScoreStats
+}
+
+// RankByScore сортирует кандидатов по среднему, при равенстве — по числу оценок,
+// дальше — в порядке candidates. Кандидаты без оценок идут в конце.
+func RankByScore(candidates []int64, scores map[int64][]int) []ScoreRow {
+	rows := make([]ScoreRow, 0, len(candidates))
+	for _, c := range candidates {
+		rows = append(rows, ScoreRow{ID: c, ScoreStats: Scores(scores[c])})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if (rows[i].Count == 0) != (rows[j].Count == 0) {
+			return rows[j].Count == 0
+		}
+		if rows[i].Mean != rows[j].Mean {
+			return rows[i].Mean > rows[j].Mean
+		}
+		return rows[i].Count > rows[j].Count
+	})
+	return rows
+}
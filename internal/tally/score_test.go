@@ -0,0 +1,69 @@
+package tally
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestScores(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		values []int
+		want   ScoreStats
+	}{
+		{"empty", nil, ScoreStats{}},
+		{"single", []int{4}, ScoreStats{Count: 1, Mean: 4, StdDev: 0}},
+		{"same", []int{3, 3, 3}, ScoreStats{Count: 3, Mean: 3, StdDev: 0}},
+		{"spread", []int{1, 5}, ScoreStats{Count: 2, Mean: 3, StdDev: 2}},
+		{"mixed", []int{2, 4, 4, 4, 5, 5, 7, 9}, ScoreStats{Count: 8, Mean: 5, StdDev: 2}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Scores(tt.values)
+			if got.Count != tt.want.Count ||
+				math.Abs(got.Mean-tt.want.Mean) > 1e-9 ||
+				math.Abs(got.StdDev-tt.want.StdDev) > 1e-9 {
+				t.Fatalf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankByScore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		candidates []int64
+		scores     map[int64][]int
+		want       []int64
+	}{
+		{"by_mean", []int64{1, 2, 3}, map[int64][]int{1: {3}, 2: {5}, 3: {4}}, []int64{2, 3, 1}},
+		{"tie_by_count", []int64{1, 2}, map[int64][]int{1: {4}, 2: {4, 4}}, []int64{2, 1}},
+		{"full_tie_keeps_order", []int64{1, 2}, map[int64][]int{1: {4}, 2: {4}}, []int64{1, 2}},
+		{"unscored_last", []int64{1, 2}, map[int64][]int{2: {1}}, []int64{2, 1}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows := RankByScore(tt.candidates, tt.scores)
+			got := make([]int64, 0, len(rows))
+			for _, r := range rows {
+				got = append(got, r.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
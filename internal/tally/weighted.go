@@ -0,0 +1,49 @@
+package tally
+
+import "sort"
+
+// WeightedRow — номинант в общем зачёте нескольких групп голосующих.
+type WeightedRow struct {
+	ID     int64
+	Score  float64            // взвешенная доля голосов, 0..1
+	Shares map[string]float64 // доля голосов номинанта внутри каждой группы
+}
+
+// Weighted сводит голоса групп (например, жюри и зрителей) в один рейтинг:
+// внутри группы считается доля голосов номинанта, доли складываются с весами групп.
+// Группы без голосов выпадают, веса оставшихся нормируются к единице —
+// иначе номинация без голосов жюри потеряла бы его долю целиком.
+// Результат отсортирован по убыванию Score, при равенстве — в порядке candidates.
+func Weighted(candidates []int64, counts map[string]map[int64]int, weights map[string]float64) []WeightedRow {
+	totals := make(map[string]int, len(counts))
+	weightSum := 0.0
+	for group, byNominee := range counts {
+		for _, c := range candidates {
+			totals[group] += byNominee[c]
+		}
+		if totals[group] > 0 {
+			weightSum += weights[group]
+		}
+	}
+
+	rows := make([]WeightedRow, 0, len(candidates))
+	for _, c := range candidates {
+		row := WeightedRow{ID: c, Shares: make(map[string]float64, len(counts))}
+		for group, byNominee := range counts {
+			if totals[group] == 0 {
+				continue
+			}
+			share := float64(byNominee[c]) / float64(totals[group])
+			row.Shares[group] = share
+			if weightSum > 0 {
+				row.Score += share * weights[group] / weightSum
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Score > rows[j].Score
+	})
+	return rows
+}
@@ -0,0 +1,93 @@
+package tally
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWeighted(t *testing.T) {
+	t.Parallel()
+
+	half := map[string]float64{"jury": 0.5, "audience": 0.5}
+
+	tests := []struct {
+		name       string
+		candidates []int64
+		counts     map[string]map[int64]int
+		weights    map[string]float64
+		wantOrder  []int64
+		wantScores []float64
+	}{
+		{
+			name:       "jury_outweighs_crowd",
+			candidates: []int64{1, 2},
+			counts: map[string]map[int64]int{
+				"jury":     {1: 3},
+				"audience": {1: 40, 2: 60},
+			},
+			weights:    half,
+			wantOrder:  []int64{1, 2},
+			wantScores: []float64{0.7, 0.3},
+		},
+		{
+			name:       "uneven_weights",
+			candidates: []int64{1, 2},
+			counts: map[string]map[int64]int{
+				"jury":     {1: 1},
+				"audience": {2: 1},
+			},
+			weights:    map[string]float64{"jury": 0.3, "audience": 0.7},
+			wantOrder:  []int64{2, 1},
+			wantScores: []float64{0.7, 0.3},
+		},
+		{
+			name:       "empty_group_renormalized",
+			candidates: []int64{1, 2},
+			counts: map[string]map[int64]int{
+				"audience": {1: 1, 2: 3},
+			},
+			weights:    half,
+			wantOrder:  []int64{2, 1},
+			wantScores: []float64{0.75, 0.25},
+		},
+		{
+			name:       "no_votes_keeps_order",
+			candidates: []int64{3, 1, 2},
+			counts:     map[string]map[int64]int{},
+			weights:    half,
+			wantOrder:  []int64{3, 1, 2},
+			wantScores: []float64{0, 0, 0},
+		},
+		{
+			name:       "tie_keeps_order",
+			candidates: []int64{2, 1},
+			counts: map[string]map[int64]int{
+				"jury":     {1: 1},
+				"audience": {2: 1},
+			},
+			weights:    half,
+			wantOrder:  []int64{2, 1},
+			wantScores: []float64{0.5, 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows := Weighted(tt.candidates, tt.counts, tt.weights)
+			order := make([]int64, 0, len(rows))
+			for i, r := range rows {
+				order = append(order, r.ID)
+				if math.Abs(r.Score-tt.wantScores[i]) > 1e-9 {
+					t.Fatalf("row %d (ID %d): score %v want %v", i, r.ID, r.Score, tt.wantScores[i])
+				}
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Fatalf("order %v want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}